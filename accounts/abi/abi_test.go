@@ -220,6 +220,97 @@ func TestMultiPack(t *testing.T) {
 	}
 }
 
+// Tests that tuples, including dynamic, nested and arrays of them, are signed,
+// packed and unpacked according to the ABI specification.
+func TestTuplePackUnpack(t *testing.T) {
+	const definition = `[{"type":"function","name":"f","inputs":[
+		{"name":"s","type":"tuple","internalType":"struct T.S","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]},
+		{"name":"ss","type":"tuple[]","internalType":"struct T.S[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]}
+	],"outputs":[
+		{"name":"s","type":"tuple","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]},
+		{"name":"ss","type":"tuple[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]}
+	]},{"type":"function","name":"g","inputs":[
+		{"name":"p","type":"tuple[2]","components":[{"name":"x","type":"uint8"},{"name":"y","type":"tuple","components":[{"name":"z","type":"bool"}]}]},
+		{"name":"q","type":"uint8"}
+	]}]`
+
+	abi, err := JSON(strings.NewReader(definition))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	if sig := abi.Methods["f"].Sig(); sig != "f((uint256,string),(uint256,string)[])" {
+		t.Errorf("signature mismatch: have %s, want %s", sig, "f((uint256,string),(uint256,string)[])")
+	}
+	if name := abi.Methods["f"].Inputs[1].Type.Elem.TupleRawName; name != "S" {
+		t.Errorf("struct name mismatch: have %s, want %s", name, "S")
+	}
+	// Pack a pair of dynamic tuples and check the offsets
+	type S struct {
+		A *big.Int
+		B string
+	}
+	packed, err := abi.Pack("f", S{big.NewInt(1), "hi"}, []S{{big.NewInt(2), "x"}})
+	if err != nil {
+		t.Fatalf("failed to pack dynamic tuples: %v", err)
+	}
+	want := common.Hex2Bytes(
+		"0000000000000000000000000000000000000000000000000000000000000040" + // offset of s
+			"00000000000000000000000000000000000000000000000000000000000000c0" + // offset of ss
+			"0000000000000000000000000000000000000000000000000000000000000001" + // s.a
+			"0000000000000000000000000000000000000000000000000000000000000040" + // offset of s.b
+			"0000000000000000000000000000000000000000000000000000000000000002" + // length of s.b
+			"6869000000000000000000000000000000000000000000000000000000000000" + // s.b
+			"0000000000000000000000000000000000000000000000000000000000000001" + // length of ss
+			"0000000000000000000000000000000000000000000000000000000000000020" + // offset of ss[0]
+			"0000000000000000000000000000000000000000000000000000000000000002" + // ss[0].a
+			"0000000000000000000000000000000000000000000000000000000000000040" + // offset of ss[0].b
+			"0000000000000000000000000000000000000000000000000000000000000001" + // length of ss[0].b
+			"7800000000000000000000000000000000000000000000000000000000000000") // ss[0].b
+	if !bytes.Equal(packed[4:], want) {
+		t.Errorf("dynamic tuple encoding mismatch:\nhave %x\nwant %x", packed[4:], want)
+	}
+	// Unpack the same encoding into user defined types
+	var out struct {
+		S  S
+		Ss []S
+	}
+	if err := abi.Unpack(&out, "f", want); err != nil {
+		t.Fatalf("failed to unpack dynamic tuples: %v", err)
+	}
+	if out.S.A.Cmp(big.NewInt(1)) != 0 || out.S.B != "hi" || len(out.Ss) != 1 || out.Ss[0].A.Cmp(big.NewInt(2)) != 0 || out.Ss[0].B != "x" {
+		t.Errorf("dynamic tuple decoding mismatch: have %+v", out)
+	}
+	// Pack static nested tuples and check they are encoded in place
+	type Y struct{ Z bool }
+	type P struct {
+		X uint8
+		Y Y
+	}
+	packed, err = abi.Pack("g", [2]P{{1, Y{true}}, {2, Y{false}}}, uint8(3))
+	if err != nil {
+		t.Fatalf("failed to pack static tuples: %v", err)
+	}
+	want = common.Hex2Bytes(
+		"0000000000000000000000000000000000000000000000000000000000000001" + // p[0].x
+			"0000000000000000000000000000000000000000000000000000000000000001" + // p[0].y.z
+			"0000000000000000000000000000000000000000000000000000000000000002" + // p[1].x
+			"0000000000000000000000000000000000000000000000000000000000000000" + // p[1].y.z
+			"0000000000000000000000000000000000000000000000000000000000000003") // q
+	if !bytes.Equal(packed[4:], want) {
+		t.Errorf("static tuple encoding mismatch:\nhave %x\nwant %x", packed[4:], want)
+	}
+	var (
+		p [2]P
+		q uint8
+	)
+	if err := abi.Methods["g"].Inputs.Unpack(&[]interface{}{&p, &q}, want); err != nil {
+		t.Fatalf("failed to unpack static tuples: %v", err)
+	}
+	if p != [2]P{{1, Y{true}}, {2, Y{false}}} || q != 3 {
+		t.Errorf("static tuple decoding mismatch: have %v, %v", p, q)
+	}
+}
+
 func ExampleJSON() {
 	const definition = `[{"constant":true,"inputs":[{"name":"","type":"address"}],"name":"isBar","outputs":[{"name":"","type":"bool"}],"type":"function"}]`
 
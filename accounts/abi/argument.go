@@ -33,22 +33,29 @@ type Argument struct {
 
 type Arguments []Argument
 
+// ArgumentMarshaling is the JSON representation of an argument, including the
+// components of tuple types.
+type ArgumentMarshaling struct {
+	Name         string
+	Type         string
+	InternalType string
+	Components   []ArgumentMarshaling
+	Indexed      bool
+}
+
 // UnmarshalJSON implements json.Unmarshaler interface
 func (argument *Argument) UnmarshalJSON(data []byte) error {
-	var extarg struct {
-		Name    string
-		Type    string
-		Indexed bool
-	}
+	var extarg ArgumentMarshaling
 	err := json.Unmarshal(data, &extarg)
 	if err != nil {
 		return fmt.Errorf("argument json err: %v", err)
 	}
 
-	argument.Type, err = NewType(extarg.Type)
+	argument.Type, err = NewType(extarg.Type, extarg.Components...)
 	if err != nil {
 		return err
 	}
+	argument.Type.setTupleRawName(extarg.InternalType)
 	argument.Name = extarg.Name
 	argument.Indexed = extarg.Indexed
 
@@ -127,10 +134,10 @@ func (arguments Arguments) unpackTuple(v interface{}, output []byte) error {
 			return err
 		}
 
-		if arg.Type.T == ArrayTy {
-			// combined index ('i' + 'j') need to be adjusted only by size of array, thus
-			// we need to decrement 'j' because 'i' was incremented
-			j += arg.Type.Size - 1
+		if (arg.Type.T == ArrayTy || arg.Type.T == TupleTy) && !isDynamicType(arg.Type) {
+			// combined index ('i' + 'j') need to be adjusted only by size of the static
+			// array or tuple, thus we need to decrement 'j' because 'i' was incremented
+			j += getTypeSize(arg.Type)/32 - 1
 		}
 
 		reflectValue := reflect.ValueOf(marshalledValue)
@@ -201,11 +208,7 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 	// input offset is the bytes offset for packed output
 	inputOffset := 0
 	for _, abiArg := range abiArgs {
-		if abiArg.Type.T == ArrayTy {
-			inputOffset += 32 * abiArg.Type.Size
-		} else {
-			inputOffset += 32
-		}
+		inputOffset += getTypeSize(abiArg.Type)
 	}
 
 	var ret []byte
@@ -217,8 +220,8 @@ func (arguments Arguments) Pack(args ...interface{}) ([]byte, error) {
 			return nil, err
 		}
 
-		// check for a slice type (string, bytes, slice)
-		if input.Type.requiresLengthPrefix() {
+		// check for a dynamic type (string, bytes, slice, dynamic array or tuple)
+		if isDynamicType(input.Type) {
 			// calculate the offset
 			offset := inputOffset + len(variableInput)
 			// set the offset
@@ -30,11 +30,15 @@ import (
 	"github.com/vaporyco/go-vapory/common/math"
 	"github.com/vaporyco/go-vapory/consensus/vapash"
 	"github.com/vaporyco/go-vapory/core"
+	"github.com/vaporyco/go-vapory/core/bloombits"
 	"github.com/vaporyco/go-vapory/core/state"
 	"github.com/vaporyco/go-vapory/core/types"
 	"github.com/vaporyco/go-vapory/core/vm"
+	"github.com/vaporyco/go-vapory/event"
+	"github.com/vaporyco/go-vapory/vap/filters"
 	"github.com/vaporyco/go-vapory/vapdb"
 	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/rpc"
 )
 
 // This nil assignment ensures compile time that SimulatedBackend implements bind.ContractBackend.
@@ -52,6 +56,10 @@ type SimulatedBackend struct {
 	mu           sync.Mutex
 	pendingBlock *types.Block   // Currently pending block that will be imported on request
 	pendingState *state.StateDB // Currently pending state that will be the active on on request
+	pendingLogs  []*types.Log   // Logs of the pending block, already announced to subscribers
+
+	mux    *event.TypeMux       // Event mux to announce pending and removed logs on
+	events *filters.EventSystem // Event system for filtering log events live
 
 	config *params.ChainConfig
 }
@@ -62,8 +70,14 @@ func NewSimulatedBackend(alloc core.GenesisAlloc) *SimulatedBackend {
 	database, _ := vapdb.NewMemDatabase()
 	genesis := core.Genesis{Config: params.AllVapashProtocolChanges, Alloc: alloc}
 	genesis.MustCommit(database)
-	blockchain, _ := core.NewBlockChain(database, genesis.Config, vapash.NewFaker(), vm.Config{})
-	backend := &SimulatedBackend{database: database, blockchain: blockchain, config: genesis.Config}
+	blockchain, _ := core.NewBlockChain(database, nil, genesis.Config, vapash.NewFaker(), vm.Config{})
+	backend := &SimulatedBackend{
+		database:   database,
+		blockchain: blockchain,
+		mux:        new(event.TypeMux),
+		config:     genesis.Config,
+	}
+	backend.events = filters.NewEventSystem(backend.mux, &filterBackend{database, blockchain, backend.mux}, false)
 	backend.rollback()
 	return backend
 }
@@ -81,10 +95,12 @@ func (b *SimulatedBackend) Commit() {
 }
 
 // Rollback aborts all pending transactions, reverting to the last committed state.
+// The logs of the aborted transactions are reported as removed to subscribers.
 func (b *SimulatedBackend) Rollback() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	b.removePendingLogs()
 	b.rollback()
 }
 
@@ -92,6 +108,32 @@ func (b *SimulatedBackend) rollback() {
 	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), vapash.NewFaker(), b.database, 1, func(int, *core.BlockGen) {})
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), state.NewDatabase(b.database))
+	b.pendingLogs = nil
+}
+
+// announcePendingLogs notifies subscribers of new logs in the pending block.
+func (b *SimulatedBackend) announcePendingLogs(logs []*types.Log) {
+	if len(logs) == 0 {
+		return
+	}
+	b.pendingLogs = append(b.pendingLogs, logs...)
+	b.mux.Post(core.PendingLogsEvent{Logs: logs})
+}
+
+// removePendingLogs notifies subscribers that all the logs announced from the
+// pending block are removed, as the block is being discarded or rewritten.
+func (b *SimulatedBackend) removePendingLogs() {
+	if len(b.pendingLogs) == 0 {
+		return
+	}
+	removed := make([]*types.Log, len(b.pendingLogs))
+	for i, log := range b.pendingLogs {
+		cpy := *log
+		cpy.Removed = true
+		removed[i] = &cpy
+	}
+	b.pendingLogs = nil
+	b.mux.Post(core.PendingLogsEvent{Logs: removed})
 }
 
 // CodeAt returns the code associated with a certain account in the blockchain.
@@ -277,7 +319,8 @@ func (b *SimulatedBackend) callContract(ctx context.Context, call vapory.CallMsg
 }
 
 // SendTransaction updates the pending block to include the given transaction.
-// It panics if the transaction is invalid.
+// It panics if the transaction is invalid. As the pending block is rewritten,
+// its logs are reported as removed and announced anew to subscribers.
 func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -291,7 +334,7 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 		panic(fmt.Errorf("invalid transaction nonce: got %d, want %d", tx.Nonce(), nonce))
 	}
 
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), vapash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, receipts := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), vapash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTx(tx)
 		}
@@ -299,14 +342,88 @@ func (b *SimulatedBackend) SendTransaction(ctx context.Context, tx *types.Transa
 	})
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), state.NewDatabase(b.database))
+
+	b.removePendingLogs()
+	for _, receipt := range receipts[0] {
+		b.announcePendingLogs(receipt.Logs)
+	}
 	return nil
 }
 
-// JumpTimeInSeconds adds skip seconds to the clock
+// FilterLogs executes a log filter operation, blocking during execution and
+// returning all the results in one batch.
+func (b *SimulatedBackend) FilterLogs(ctx context.Context, query vapory.FilterQuery) ([]types.Log, error) {
+	// Initialize unset filter boundaries to run from genesis to chain head
+	from := int64(0)
+	if query.FromBlock != nil {
+		from = query.FromBlock.Int64()
+	}
+	to := int64(-1)
+	if query.ToBlock != nil {
+		to = query.ToBlock.Int64()
+	}
+	// Construct and execute the filter
+	filter := filters.New(&filterBackend{b.database, b.blockchain, b.mux}, from, to, query.Addresses, query.Topics)
+
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]types.Log, len(logs))
+	for i, log := range logs {
+		res[i] = *log
+	}
+	return res, nil
+}
+
+// SubscribeFilterLogs creates a background log filtering operation, returning a
+// subscription immediately, which can be used to stream the found events. Logs
+// are delivered when blocks are committed, or as soon as they enter the pending
+// block if the query ends at the pending one. Logs of a rewritten pending block
+// are reported with the Removed flag set.
+func (b *SimulatedBackend) SubscribeFilterLogs(ctx context.Context, query vapory.FilterQuery, ch chan<- types.Log) (vapory.Subscription, error) {
+	// Subscribe to contract events
+	sink := make(chan []*types.Log)
+
+	sub, err := b.events.SubscribeLogs(filters.FilterCriteria{
+		FromBlock: query.FromBlock,
+		ToBlock:   query.ToBlock,
+		Addresses: query.Addresses,
+		Topics:    query.Topics,
+	}, sink)
+	if err != nil {
+		return nil, err
+	}
+	// Since we're getting logs in batches, we need to flatten them into a plain stream
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case logs := <-sink:
+				for _, log := range logs {
+					select {
+					case ch <- *log:
+					case err := <-sub.Err():
+						return err
+					case <-quit:
+						return nil
+					}
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// AdjustTime adds a time shift to the pending block. As the pending block is
+// rewritten, its logs are reported as removed and announced anew to subscribers.
 func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	blocks, _ := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), vapash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
+	blocks, receipts := core.GenerateChain(b.config, b.blockchain.CurrentBlock(), vapash.NewFaker(), b.database, 1, func(number int, block *core.BlockGen) {
 		for _, tx := range b.pendingBlock.Transactions() {
 			block.AddTx(tx)
 		}
@@ -315,6 +432,10 @@ func (b *SimulatedBackend) AdjustTime(adjustment time.Duration) error {
 	b.pendingBlock = blocks[0]
 	b.pendingState, _ = state.New(b.pendingBlock.Root(), state.NewDatabase(b.database))
 
+	b.removePendingLogs()
+	for _, receipt := range receipts[0] {
+		b.announcePendingLogs(receipt.Logs)
+	}
 	return nil
 }
 
@@ -331,3 +452,47 @@ func (m callmsg) GasPrice() *big.Int   { return m.CallMsg.GasPrice }
 func (m callmsg) Gas() uint64          { return m.CallMsg.Gas }
 func (m callmsg) Value() *big.Int      { return m.CallMsg.Value }
 func (m callmsg) Data() []byte         { return m.CallMsg.Data }
+
+// filterBackend implements filters.Backend to support filtering for logs without
+// taking bloom-bits acceleration structures into account.
+type filterBackend struct {
+	db  vapdb.Database
+	bc  *core.BlockChain
+	mux *event.TypeMux
+}
+
+func (fb *filterBackend) ChainDb() vapdb.Database  { return fb.db }
+func (fb *filterBackend) EventMux() *event.TypeMux { return fb.mux }
+
+func (fb *filterBackend) HeaderByNumber(ctx context.Context, block rpc.BlockNumber) (*types.Header, error) {
+	if block == rpc.LatestBlockNumber {
+		return fb.bc.CurrentHeader(), nil
+	}
+	return fb.bc.GetHeaderByNumber(uint64(block.Int64())), nil
+}
+
+func (fb *filterBackend) GetReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	return core.GetBlockReceipts(fb.db, hash, core.GetBlockNumber(fb.db, hash)), nil
+}
+
+func (fb *filterBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+func (fb *filterBackend) SubscribeChainEvent(ch chan<- core.ChainEvent) event.Subscription {
+	return fb.bc.SubscribeChainEvent(ch)
+}
+func (fb *filterBackend) SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription {
+	return fb.bc.SubscribeRemovedLogsEvent(ch)
+}
+func (fb *filterBackend) SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription {
+	return fb.bc.SubscribeLogsEvent(ch)
+}
+
+func (fb *filterBackend) BloomStatus() (uint64, uint64) { return 4096, 0 }
+
+func (fb *filterBackend) ServiceFilter(ctx context.Context, ms *bloombits.MatcherSession) {
+	panic("not supported")
+}
@@ -21,12 +21,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"reflect"
 
 	"github.com/vaporyco/go-vapory"
 	"github.com/vaporyco/go-vapory/accounts/abi"
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/core/types"
 	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/event"
 )
 
 // SignerFn is a signer function callback when a contract requires a method to
@@ -55,6 +57,23 @@ type TransactOpts struct {
 	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
 }
 
+// FilterOpts is the collection of options to fine tune filtering for events
+// within a bound contract.
+type FilterOpts struct {
+	Start uint64  // Start of the queried range
+	End   *uint64 // End of the range (nil = latest)
+
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
+// WatchOpts is the collection of options to fine tune subscribing for events
+// within a bound contract.
+type WatchOpts struct {
+	Start *uint64 // Start of the queried range (nil = latest)
+
+	Context context.Context // Network context to support cancellation and timeouts (nil = no timeout)
+}
+
 // BoundContract is the base wrapper object that reflects a contract on the
 // Vapory network. It contains a collection of methods that are used by the
 // higher level contract bindings to operate.
@@ -63,16 +82,18 @@ type BoundContract struct {
 	abi        abi.ABI            // Reflect based ABI to access the correct Vapory methods
 	caller     ContractCaller     // Read interface to interact with the blockchain
 	transactor ContractTransactor // Write interface to interact with the blockchain
+	filterer   ContractFilterer   // Event filtering to interact with the blockchain
 }
 
 // NewBoundContract creates a low level contract interface through which calls
 // and transactions may be made through.
-func NewBoundContract(address common.Address, abi abi.ABI, caller ContractCaller, transactor ContractTransactor) *BoundContract {
+func NewBoundContract(address common.Address, abi abi.ABI, caller ContractCaller, transactor ContractTransactor, filterer ContractFilterer) *BoundContract {
 	return &BoundContract{
 		address:    address,
 		abi:        abi,
 		caller:     caller,
 		transactor: transactor,
+		filterer:   filterer,
 	}
 }
 
@@ -80,7 +101,7 @@ func NewBoundContract(address common.Address, abi abi.ABI, caller ContractCaller
 // deployment address with a Go wrapper.
 func DeployContract(opts *TransactOpts, abi abi.ABI, bytecode []byte, backend ContractBackend, params ...interface{}) (common.Address, *types.Transaction, *BoundContract, error) {
 	// Otherwise try to deploy the contract
-	c := NewBoundContract(common.Address{}, abi, backend, backend)
+	c := NewBoundContract(common.Address{}, abi, backend, backend, backend)
 
 	input, err := c.abi.Pack("", params...)
 	if err != nil {
@@ -225,6 +246,135 @@ func (c *BoundContract) transact(opts *TransactOpts, contract *common.Address, i
 	return signedTx, nil
 }
 
+// FilterLogs filters contract logs for past blocks, returning the necessary
+// channels to construct a strongly typed bound iterator on top of them.
+func (c *BoundContract) FilterLogs(opts *FilterOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(FilterOpts)
+	}
+	// Assemble the topic set of the requested event
+	topics, err := c.eventTopics(name, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := vapory.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(opts.Start),
+	}
+	if opts.End != nil {
+		config.ToBlock = new(big.Int).SetUint64(*opts.End)
+	}
+	// Retrieve all the matching logs and feed them to the iterator in the background
+	buff, err := c.filterer.FilterLogs(ensureContext(opts.Context), config)
+	if err != nil {
+		return nil, nil, err
+	}
+	logs := make(chan types.Log, 128)
+
+	sub := event.NewSubscription(func(quit <-chan struct{}) error {
+		for _, log := range buff {
+			select {
+			case logs <- log:
+			case <-quit:
+				return nil
+			}
+		}
+		return nil
+	})
+	return logs, sub, nil
+}
+
+// WatchLogs subscribes to contract logs for future blocks, returning a
+// subscription object that can be used to tear down the watcher.
+func (c *BoundContract) WatchLogs(opts *WatchOpts, name string, query ...[]interface{}) (chan types.Log, event.Subscription, error) {
+	// Don't crash on a lazy user
+	if opts == nil {
+		opts = new(WatchOpts)
+	}
+	// Assemble the topic set of the requested event
+	topics, err := c.eventTopics(name, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := vapory.FilterQuery{
+		Addresses: []common.Address{c.address},
+		Topics:    topics,
+	}
+	if opts.Start != nil {
+		config.FromBlock = new(big.Int).SetUint64(*opts.Start)
+	}
+	// Start the background filtering
+	logs := make(chan types.Log, 128)
+
+	sub, err := c.filterer.SubscribeFilterLogs(ensureContext(opts.Context), config, logs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return logs, sub, nil
+}
+
+// UnpackLog unpacks a retrieved log into the provided output structure. Indexed
+// fields are reconstructed from the log topics, the rest from the log data.
+func (c *BoundContract) UnpackLog(out interface{}, name string, log types.Log) error {
+	event, ok := c.abi.Events[name]
+	if !ok {
+		return fmt.Errorf("event '%s' not found", name)
+	}
+	var indexed, data abi.Arguments
+	for i, arg := range event.Inputs {
+		// Anonymous arguments are named after their position, as the bindings do
+		if arg.Name == "" {
+			arg.Name = fmt.Sprintf("arg%d", i)
+		}
+		if arg.Indexed {
+			indexed = append(indexed, arg)
+		} else {
+			data = append(data, arg)
+		}
+	}
+	// Unpack the non-indexed fields from the log data
+	switch len(data) {
+	case 0:
+	case 1:
+		field := reflect.ValueOf(out).Elem().FieldByName(capitalise(data[0].Name))
+		if !field.IsValid() {
+			return fmt.Errorf("field '%s' not found in %T", capitalise(data[0].Name), out)
+		}
+		if err := data.Unpack(field.Addr().Interface(), log.Data); err != nil {
+			return err
+		}
+	default:
+		if err := data.Unpack(out, log.Data); err != nil {
+			return err
+		}
+	}
+	// Reconstruct the indexed fields from the topics, skipping the event selector
+	topics := log.Topics
+	if !event.Anonymous {
+		if len(topics) == 0 {
+			return errors.New("event selector topic missing")
+		}
+		topics = topics[1:]
+	}
+	return parseTopics(out, indexed, topics)
+}
+
+// eventTopics assembles the topic set filtering for the named event, restricted by
+// the given rules on its indexed arguments.
+func (c *BoundContract) eventTopics(name string, query [][]interface{}) ([][]common.Hash, error) {
+	event, ok := c.abi.Events[name]
+	if !ok {
+		return nil, fmt.Errorf("event '%s' not found", name)
+	}
+	// Non-anonymous events are identified by their selector in the first topic
+	if !event.Anonymous {
+		query = append([][]interface{}{{event.Id()}}, query...)
+	}
+	return makeTopics(query...)
+}
+
 func ensureContext(ctx context.Context) context.Context {
 	if ctx == nil {
 		return context.TODO()
@@ -22,11 +22,12 @@ package bind
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"text/template"
-	"unicode"
 
 	"github.com/vaporyco/go-vapory/accounts/abi"
 	"golang.org/x/tools/imports"
@@ -47,7 +48,10 @@ const (
 // manually maintain hard coded strings that break on runtime.
 func Bind(types []string, abis []string, bytecodes []string, pkg string, lang Lang) (string, error) {
 	// Process each individual contract requested binding
-	contracts := make(map[string]*tmplContract)
+	var (
+		contracts = make(map[string]*tmplContract)
+		structs   = make(map[string]*tmplStruct)
+	)
 
 	for i := 0; i < len(types); i++ {
 		// Parse the actual ABI to generate the binding for
@@ -55,13 +59,13 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 		if err != nil {
 			return "", err
 		}
-		// Strip any whitespace from the JSON ABI
-		strippedABI := strings.Map(func(r rune) rune {
-			if unicode.IsSpace(r) {
-				return -1
-			}
-			return r
-		}, abis[i])
+		// Strip any whitespace from the JSON ABI, retaining it within strings
+		// (e.g. struct internal types)
+		stripped := new(bytes.Buffer)
+		if err := json.Compact(stripped, []byte(abis[i])); err != nil {
+			return "", err
+		}
+		strippedABI := stripped.String()
 
 		// Extract the call and transact methods, and sort them alphabetically
 		var (
@@ -94,6 +98,30 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 				transacts[original.Name] = &tmplMethod{Original: original, Normalized: normalized, Structured: structured(original)}
 			}
 		}
+		// Extract the events to filter and watch, skipping anonymous ones as they
+		// cannot be identified by their topics
+		events := make(map[string]*tmplEvent)
+		for _, original := range vvmABI.Events {
+			if original.Anonymous {
+				continue
+			}
+			// Normalize the event for capital cases and non-anonymous inputs
+			normalized := original
+			normalized.Name = methodNormalizer[lang](original.Name)
+
+			normalized.Inputs = make([]abi.Argument, len(original.Inputs))
+			copy(normalized.Inputs, original.Inputs)
+			for j, input := range normalized.Inputs {
+				if input.Name == "" {
+					normalized.Inputs[j].Name = fmt.Sprintf("arg%d", j)
+				}
+			}
+			events[original.Name] = &tmplEvent{Original: original, Normalized: normalized}
+		}
+		// Gather the structs the tuple arguments of the methods are bound to
+		if err := bindStructs(vvmABI, structs, lang); err != nil {
+			return "", err
+		}
 		contracts[types[i]] = &tmplContract{
 			Type:        capitalise(types[i]),
 			InputABI:    strings.Replace(strippedABI, "\"", "\\\"", -1),
@@ -101,20 +129,23 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 			Constructor: vvmABI.Constructor,
 			Calls:       calls,
 			Transacts:   transacts,
+			Events:      events,
 		}
 	}
 	// Generate the contract template data content and render it
 	data := &tmplData{
 		Package:   pkg,
 		Contracts: contracts,
+		Structs:   structs,
 	}
 	buffer := new(bytes.Buffer)
 
 	funcs := map[string]interface{}{
-		"bindtype":     bindType[lang],
-		"namedtype":    namedType[lang],
-		"capitalise":   capitalise,
-		"decapitalise": decapitalise,
+		"bindtype":      func(kind abi.Type) string { return bindType[lang](kind, structs) },
+		"bindtopictype": func(kind abi.Type) string { return bindTopicType[lang](kind, structs) },
+		"namedtype":     namedType[lang],
+		"capitalise":    capitalise,
+		"decapitalise":  decapitalise,
 	}
 	tmpl := template.Must(template.New("").Funcs(funcs).Parse(tmplSource[lang]))
 	if err := tmpl.Execute(buffer, data); err != nil {
@@ -132,17 +163,109 @@ func Bind(types []string, abis []string, bytecodes []string, pkg string, lang La
 	return buffer.String(), nil
 }
 
+// bindStructs registers the structs all tuple arguments of the contract's methods
+// and events are bound to. These are visited alphabetically to keep anonymous
+// struct names stable across runs.
+func bindStructs(contract abi.ABI, structs map[string]*tmplStruct, lang Lang) error {
+	names := make([]string, 0, len(contract.Methods))
+	for name := range contract.Methods {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	args := contract.Constructor.Inputs
+	for _, name := range names {
+		args = append(args, contract.Methods[name].Inputs...)
+		args = append(args, contract.Methods[name].Outputs...)
+	}
+	names = names[:0]
+	for name := range contract.Events {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		args = append(args, contract.Events[name].Inputs...)
+	}
+	for _, arg := range args {
+		if !hasTuple(arg.Type) {
+			continue
+		}
+		if lang != LangGo {
+			return fmt.Errorf("tuple argument %s: tuples are only supported in Go bindings", arg.Name)
+		}
+		bindTypeGo(arg.Type, structs)
+	}
+	return nil
+}
+
+// hasTuple checks whether a type is a tuple or an array of tuples.
+func hasTuple(kind abi.Type) bool {
+	for kind.T == abi.SliceTy || kind.T == abi.ArrayTy {
+		kind = *kind.Elem
+	}
+	return kind.T == abi.TupleTy
+}
+
 // bindType is a set of type binders that convert Solidity types to some supported
 // programming language.
-var bindType = map[Lang]func(kind abi.Type) string{
+var bindType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
 	LangGo:   bindTypeGo,
 	LangJava: bindTypeJava,
 }
 
 // bindTypeGo converts a Solidity type to a Go one. Since there is no clear mapping
 // from all Solidity types to Go ones (e.g. uint17), those that cannot be exactly
-// mapped will use an upscaled type (e.g. *big.Int).
-func bindTypeGo(kind abi.Type) string {
+// mapped will use an upscaled type (e.g. *big.Int). Tuples are bound to Go structs,
+// which are registered into structs the first time they are encountered.
+func bindTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	switch kind.T {
+	case abi.TupleTy:
+		return bindStructTypeGo(kind, structs)
+	case abi.ArrayTy:
+		return fmt.Sprintf("[%d]", kind.Size) + bindTypeGo(*kind.Elem, structs)
+	case abi.SliceTy:
+		return "[]" + bindTypeGo(*kind.Elem, structs)
+	default:
+		return bindBasicTypeGo(kind)
+	}
+}
+
+// bindStructTypeGo returns the name of the Go struct a tuple is bound to, creating
+// it (and the ones of any nested tuples) if it does not exist yet. Structs are
+// named after their Solidity counterparts if known, or numbered otherwise.
+func bindStructTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	id := kind.TupleRawName + kind.String()
+	if s, exist := structs[id]; exist {
+		return s.Name
+	}
+	var fields []*tmplField
+	for i, elem := range kind.TupleElems {
+		fields = append(fields, &tmplField{
+			Type:    bindTypeGo(*elem, structs),
+			Name:    capitalise(kind.TupleRawNames[i]),
+			SolKind: *elem,
+		})
+	}
+	name := capitalise(kind.TupleRawName)
+	if name == "" {
+		name = fmt.Sprintf("Struct%d", len(structs))
+	}
+	for taken, base, n := true, name, 0; taken; n++ {
+		taken = false
+		for _, s := range structs {
+			if s.Name == name {
+				taken, name = true, fmt.Sprintf("%s%d", base, n)
+				break
+			}
+		}
+	}
+	structs[id] = &tmplStruct{Name: name, Fields: fields}
+	return name
+}
+
+// bindBasicTypeGo converts a basic (i.e. non tuple) Solidity type to a Go one.
+func bindBasicTypeGo(kind abi.Type) string {
 	stringKind := kind.String()
 
 	switch {
@@ -186,7 +309,7 @@ func bindTypeGo(kind abi.Type) string {
 // bindTypeJava converts a Solidity type to a Java one. Since there is no clear mapping
 // from all Solidity types to Java ones (e.g. uint17), those that cannot be exactly
 // mapped will use an upscaled type (e.g. BigDecimal).
-func bindTypeJava(kind abi.Type) string {
+func bindTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
 	stringKind := kind.String()
 
 	switch {
@@ -254,6 +377,31 @@ func bindTypeJava(kind abi.Type) string {
 	}
 }
 
+// bindTopicType is a set of type binders that convert Solidity types of indexed
+// event arguments to some supported programming language.
+var bindTopicType = map[Lang]func(kind abi.Type, structs map[string]*tmplStruct) string{
+	LangGo:   bindTopicTypeGo,
+	LangJava: bindTopicTypeJava,
+}
+
+// bindTopicTypeGo converts a Solidity topic type to a Go one. It is the same as
+// for simple types, but types the VVM replaces with their hash become hashes.
+func bindTopicTypeGo(kind abi.Type, structs map[string]*tmplStruct) string {
+	if !isValueTopic(kind) {
+		return "common.Hash"
+	}
+	return bindTypeGo(kind, structs)
+}
+
+// bindTopicTypeJava converts a Solidity topic type to a Java one. It is the same
+// as for simple types, but types the VVM replaces with their hash become hashes.
+func bindTopicTypeJava(kind abi.Type, structs map[string]*tmplStruct) string {
+	if !isValueTopic(kind) {
+		return "Hash"
+	}
+	return bindTypeJava(kind, structs)
+}
+
 // namedType is a set of functions that transform language specific types to
 // named versions that my be used inside method names.
 var namedType = map[Lang]func(string, abi.Type) string{
@@ -508,6 +508,177 @@ var bindTests = []struct {
 			fmt.Println(a, b, err)
 		`,
 	},
+	// Tests that tuples are bound to Go structs which can be passed to and returned
+	// from contracts. The hand assembled contract echoes back its call data.
+	{
+		`Echoer`,
+		`
+			pragma experimental ABIEncoderV2;
+
+			contract Echoer {
+				struct Y { bool z; }
+				struct P { uint8 x; Y y; }
+				struct S { uint256 a; string b; }
+
+				function echo(S s, S[] ss) constant returns (S, S[]) { return (s, ss); }
+				function echoStatic(P[2] p) constant returns (P[2]) { return p; }
+			}
+		`,
+		`600e80600b6000396000f336600490038060046000376000f3`,
+		`[{"constant":true,"inputs":[{"name":"s","type":"tuple","internalType":"struct Echoer.S","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]},{"name":"ss","type":"tuple[]","internalType":"struct Echoer.S[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]}],"name":"echo","outputs":[{"name":"s","type":"tuple","internalType":"struct Echoer.S","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]},{"name":"ss","type":"tuple[]","internalType":"struct Echoer.S[]","components":[{"name":"a","type":"uint256"},{"name":"b","type":"string"}]}],"type":"function"},{"constant":true,"inputs":[{"name":"p","type":"tuple[2]","internalType":"struct Echoer.P[2]","components":[{"name":"x","type":"uint8"},{"name":"y","type":"tuple","components":[{"name":"z","type":"bool"}]}]}],"name":"echoStatic","outputs":[{"name":"","type":"tuple[2]","internalType":"struct Echoer.P[2]","components":[{"name":"x","type":"uint8"},{"name":"y","type":"tuple","components":[{"name":"z","type":"bool"}]}]}],"type":"function"}]`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth := bind.NewKeyedTransactor(key)
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000)}})
+
+			// Deploy an echoer contract and send some structs through it
+			_, _, echoer, err := DeployEchoer(auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy echoer contract: %v", err)
+			}
+			sim.Commit()
+
+			s := S{A: big.NewInt(1), B: "hi"}
+			ss := []S{{A: big.NewInt(2), B: "x"}, {A: big.NewInt(3), B: "yz"}}
+			if res, err := echoer.Echo(nil, s, ss); err != nil {
+				t.Fatalf("Failed to echo dynamic structs: %v", err)
+			} else if res.S.A.Cmp(s.A) != 0 || res.S.B != s.B || len(res.Ss) != len(ss) {
+				t.Fatalf("Dynamic struct mismatch: have %+v, want %+v/%+v", res, s, ss)
+			} else {
+				for i := range ss {
+					if res.Ss[i].A.Cmp(ss[i].A) != 0 || res.Ss[i].B != ss[i].B {
+						t.Fatalf("Dynamic struct %d mismatch: have %+v, want %+v", i, res.Ss[i], ss[i])
+					}
+				}
+			}
+			// Anonymous structs get numbered names
+			p := [2]P{{X: 1, Y: Struct1{Z: true}}, {X: 2, Y: Struct1{Z: false}}}
+			if res, err := echoer.EchoStatic(nil, p); err != nil {
+				t.Fatalf("Failed to echo static structs: %v", err)
+			} else if res != p {
+				t.Fatalf("Static struct mismatch: have %+v, want %+v", res, p)
+			}
+		`,
+	},
+	// Tests that events can be filtered and watched through the typed bindings, and
+	// that the simulator reports pending and removed logs. The hand assembled contract
+	// emits Poked(msg.sender, value) on every call.
+	{
+		`Eventer`,
+		`
+			contract Eventer {
+				event Poked(address indexed sender, uint256 value);
+
+				function poke(uint256 value) { Poked(msg.sender, value); }
+			}
+		`,
+		`602e80600b6000396000f3600435600052337f4889d7d38c1d1f265318505a98367d07d41591d6b7f9cc000f37ea92b8311daa60206000a200`,
+		`[{"constant":false,"inputs":[{"name":"value","type":"uint256"}],"name":"poke","outputs":[],"type":"function"},{"anonymous":false,"inputs":[{"indexed":true,"name":"sender","type":"address"},{"indexed":false,"name":"value","type":"uint256"}],"name":"Poked","type":"event"}]`,
+		`
+			// Generate a new random account and a funded simulator
+			key, _ := crypto.GenerateKey()
+			auth := bind.NewKeyedTransactor(key)
+			sim := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: big.NewInt(10000000000)}})
+
+			// Deploy an eventer contract
+			addr, _, eventer, err := DeployEventer(auth, sim)
+			if err != nil {
+				t.Fatalf("Failed to deploy eventer contract: %v", err)
+			}
+			sim.Commit()
+
+			// Watch for mined events and subscribe to the raw pending logs too
+			mined := make(chan *EventerPoked, 8)
+			sub, err := eventer.WatchPoked(nil, mined, []common.Address{auth.From})
+			if err != nil {
+				t.Fatalf("Failed to watch for pokes: %v", err)
+			}
+			defer sub.Unsubscribe()
+
+			pending := make(chan types.Log, 8)
+			pendingNumber := big.NewInt(int64(rpc.PendingBlockNumber))
+
+			psub, err := sim.SubscribeFilterLogs(context.Background(), vapory.FilterQuery{FromBlock: pendingNumber, ToBlock: pendingNumber, Addresses: []common.Address{addr}}, pending)
+			if err != nil {
+				t.Fatalf("Failed to subscribe to pending logs: %v", err)
+			}
+			defer psub.Unsubscribe()
+
+			// Poke the eventer and roll it back, expecting the log announced and removed
+			if _, err := eventer.Poke(auth, big.NewInt(1)); err != nil {
+				t.Fatalf("Failed to poke eventer: %v", err)
+			}
+			for i, removed := range []bool{false, true} {
+				if i == 1 {
+					sim.Rollback()
+				}
+				select {
+				case log := <-pending:
+					if log.Removed != removed {
+						t.Fatalf("pending log %d removal mismatch: have %v, want %v", i, log.Removed, removed)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("pending log %d not delivered", i)
+				}
+			}
+			// Poke the eventer twice, expecting the first log to be re-announced as
+			// the pending block is rewritten, and roll both back
+			for _, value := range []int64{3, 4} {
+				if _, err := eventer.Poke(auth, big.NewInt(value)); err != nil {
+					t.Fatalf("Failed to poke eventer: %v", err)
+				}
+			}
+			sim.Rollback()
+			for i, removed := range []bool{false, true, false, false, true, true} {
+				select {
+				case log := <-pending:
+					if log.Removed != removed {
+						t.Fatalf("rewritten log %d removal mismatch: have %v, want %v", i, log.Removed, removed)
+					}
+				case <-time.After(time.Second):
+					t.Fatalf("rewritten log %d not delivered", i)
+				}
+			}
+			// Poke the eventer again and mine it, expecting the typed event
+			if _, err := eventer.Poke(auth, big.NewInt(2)); err != nil {
+				t.Fatalf("Failed to poke eventer: %v", err)
+			}
+			sim.Commit()
+
+			select {
+			case poke := <-mined:
+				if poke.Sender != auth.From || poke.Value.Cmp(big.NewInt(2)) != 0 {
+					t.Fatalf("mined event mismatch: have %x/%v, want %x/2", poke.Sender, poke.Value, auth.From)
+				}
+				if poke.Raw.Removed {
+					t.Fatalf("mined event marked as removed")
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("mined event not delivered")
+			}
+			// Filter through the chain and ensure only the mined poke is found
+			it, err := eventer.FilterPoked(nil, []common.Address{auth.From})
+			if err != nil {
+				t.Fatalf("Failed to filter pokes: %v", err)
+			}
+			defer it.Close()
+
+			var values []int64
+			for it.Next() {
+				values = append(values, it.Event.Value.Int64())
+			}
+			if err := it.Error(); err != nil {
+				t.Fatalf("Failed to iterate pokes: %v", err)
+			}
+			if len(values) != 1 || values[0] != 2 {
+				t.Fatalf("filtered pokes mismatch: have %v, want [2]", values)
+			}
+			if it, err := eventer.FilterPoked(nil, []common.Address{addr}); err != nil || it.Next() {
+				t.Fatalf("Filtering by a different sender found pokes (error %v)", err)
+			}
+		`,
+	},
 }
 
 // Tests that packages generated by the binder can be successfully compiled and
@@ -0,0 +1,142 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+
+	"github.com/vaporyco/go-vapory/accounts/abi"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/math"
+	"github.com/vaporyco/go-vapory/crypto"
+)
+
+// makeTopics converts a filter query argument list into a filter topic set.
+// Value types are encoded in place, whereas strings and byte slices are hashed,
+// as the VVM does for indexed event arguments.
+func makeTopics(query ...[]interface{}) ([][]common.Hash, error) {
+	topics := make([][]common.Hash, len(query))
+	for i, filter := range query {
+		for _, rule := range filter {
+			var topic common.Hash
+
+			// Try to generate the topic based on simple types
+			switch rule := rule.(type) {
+			case common.Hash:
+				topic = rule
+			case common.Address:
+				copy(topic[common.HashLength-common.AddressLength:], rule[:])
+			case *big.Int:
+				topic = makeBigTopic(new(big.Int).Set(rule))
+			case bool:
+				if rule {
+					topic[common.HashLength-1] = 1
+				}
+			case int8:
+				topic = makeIntTopic(int64(rule))
+			case int16:
+				topic = makeIntTopic(int64(rule))
+			case int32:
+				topic = makeIntTopic(int64(rule))
+			case int64:
+				topic = makeIntTopic(rule)
+			case uint8:
+				topic = makeUintTopic(uint64(rule))
+			case uint16:
+				topic = makeUintTopic(uint64(rule))
+			case uint32:
+				topic = makeUintTopic(uint64(rule))
+			case uint64:
+				topic = makeUintTopic(rule)
+			case string:
+				topic = crypto.Keccak256Hash([]byte(rule))
+			case []byte:
+				topic = crypto.Keccak256Hash(rule)
+
+			default:
+				// Attempt to generate the topic from fixed size byte arrays (bytesN)
+				val := reflect.ValueOf(rule)
+				if val.Kind() != reflect.Array || val.Type().Elem().Kind() != reflect.Uint8 || val.Len() > common.HashLength {
+					return nil, fmt.Errorf("unsupported indexed type: %T", rule)
+				}
+				reflect.Copy(reflect.ValueOf(topic[:]), val)
+			}
+			topics[i] = append(topics[i], topic)
+		}
+	}
+	return topics, nil
+}
+
+// makeIntTopic encodes a signed integer into a topic in two's complement form.
+func makeIntTopic(n int64) common.Hash {
+	return makeBigTopic(big.NewInt(n))
+}
+
+// makeUintTopic encodes an unsigned integer into a topic.
+func makeUintTopic(n uint64) common.Hash {
+	return makeBigTopic(new(big.Int).SetUint64(n))
+}
+
+// makeBigTopic encodes an arbitrary integer into a topic in two's complement
+// form. The integer is modified in place.
+func makeBigTopic(n *big.Int) common.Hash {
+	return common.BytesToHash(math.PaddedBigBytes(math.U256(n), common.HashLength))
+}
+
+// parseTopics converts the indexed topic fields into actual log field values.
+// Value types are decoded as regular return values, whereas all other types are
+// hashed by the VVM, so only the hash can be retrieved.
+func parseTopics(out interface{}, fields abi.Arguments, topics []common.Hash) error {
+	// Sanity check that the fields and topics match up
+	if len(fields) != len(topics) {
+		return errors.New("topic/field count mismatch")
+	}
+	// Iterate over all the fields and reconstruct them from topics
+	for i, arg := range fields {
+		if !arg.Indexed {
+			return errors.New("non-indexed field in topic reconstruction")
+		}
+		field := reflect.ValueOf(out).Elem().FieldByName(capitalise(arg.Name))
+		if !field.IsValid() {
+			return fmt.Errorf("field '%s' not found in %T", capitalise(arg.Name), out)
+		}
+		if !isValueTopic(arg.Type) {
+			if field.Type() != reflect.TypeOf(common.Hash{}) {
+				return fmt.Errorf("cannot unmarshal hashed topic into %v", field.Type())
+			}
+			field.Set(reflect.ValueOf(topics[i]))
+			continue
+		}
+		if err := (abi.Arguments{{Name: arg.Name, Type: arg.Type}}).Unpack(field.Addr().Interface(), topics[i][:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isValueTopic reports whether an indexed argument of the given type is stored
+// in place as its topic, or whether it is replaced by its hash.
+func isValueTopic(kind abi.Type) bool {
+	switch kind.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package bind
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/accounts/abi"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/crypto"
+)
+
+// Tests that filter rules are encoded into topics the same way the VVM encodes
+// indexed event arguments.
+func TestMakeTopics(t *testing.T) {
+	tests := []struct {
+		rule  interface{}
+		topic common.Hash
+	}{
+		{common.HexToHash("0x01"), common.HexToHash("0x01")},
+		{common.HexToAddress("0x0102"), common.HexToHash("0x0102")},
+		{big.NewInt(1000), common.HexToHash("0x03e8")},
+		{big.NewInt(-1), common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff")},
+		{true, common.HexToHash("0x01")},
+		{false, common.Hash{}},
+		{int8(-2), common.HexToHash("0xfffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe")},
+		{uint64(1<<64 - 1), common.HexToHash("0xffffffffffffffff")},
+		{"hello", crypto.Keccak256Hash([]byte("hello"))},
+		{[]byte{0x01, 0x02}, crypto.Keccak256Hash([]byte{0x01, 0x02})},
+		{[4]byte{0x01, 0x02, 0x03, 0x04}, common.HexToHash("0x0102030400000000000000000000000000000000000000000000000000000000")},
+	}
+	for i, tt := range tests {
+		topics, err := makeTopics([]interface{}{tt.rule})
+		if err != nil {
+			t.Errorf("test %d: failed to make topic: %v", i, err)
+			continue
+		}
+		if len(topics) != 1 || len(topics[0]) != 1 || topics[0][0] != tt.topic {
+			t.Errorf("test %d: topic mismatch: have %x, want %x", i, topics, tt.topic)
+		}
+	}
+	if _, err := makeTopics([]interface{}{struct{}{}}); err == nil {
+		t.Errorf("unsupported rule type accepted")
+	}
+}
+
+// Tests that logs are unpacked into bound event structs, reconstructing indexed
+// fields from topics and the rest from the log data.
+func TestUnpackLog(t *testing.T) {
+	const definition = `[{"type":"event","name":"Transfer","inputs":[
+		{"name":"from","type":"address","indexed":true},
+		{"name":"delta","type":"int8","indexed":true},
+		{"name":"memo","type":"string","indexed":true},
+		{"name":"value","type":"uint256","indexed":false},
+		{"name":"","type":"string","indexed":false}
+	]}]`
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		t.Fatalf("failed to parse ABI: %v", err)
+	}
+	contract := NewBoundContract(common.Address{}, parsed, nil, nil, nil)
+
+	type transfer struct {
+		From  common.Address
+		Delta int8
+		Memo  common.Hash
+		Value *big.Int
+		Arg4  string
+	}
+	want := transfer{
+		From:  common.HexToAddress("0x0102"),
+		Delta: -3,
+		Memo:  crypto.Keccak256Hash([]byte("memo")),
+		Value: big.NewInt(1000),
+		Arg4:  "note",
+	}
+	topics, err := contract.eventTopics("Transfer", [][]interface{}{{want.From}, {want.Delta}, {"memo"}})
+	if err != nil {
+		t.Fatalf("failed to make topics: %v", err)
+	}
+	data, err := abi.Arguments{parsed.Events["Transfer"].Inputs[3], parsed.Events["Transfer"].Inputs[4]}.Pack(want.Value, want.Arg4)
+	if err != nil {
+		t.Fatalf("failed to pack log data: %v", err)
+	}
+	log := types.Log{Data: data}
+	for _, topic := range topics {
+		log.Topics = append(log.Topics, topic[0])
+	}
+	if log.Topics[0] != parsed.Events["Transfer"].Id() {
+		t.Fatalf("event selector mismatch: have %x, want %x", log.Topics[0], parsed.Events["Transfer"].Id())
+	}
+	var have transfer
+	if err := contract.UnpackLog(&have, "Transfer", log); err != nil {
+		t.Fatalf("failed to unpack log: %v", err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("unpacked event mismatch: have %+v, want %+v", have, want)
+	}
+}
@@ -80,12 +80,44 @@ func set(dst, src reflect.Value, output Argument) error {
 		dst.Set(src)
 	case dstType.Kind() == reflect.Ptr:
 		return set(dst.Elem(), src, output)
+	case dstType.Kind() == reflect.Struct && srcType.Kind() == reflect.Struct:
+		return setStruct(dst, src, output)
+	case dstType.Kind() == reflect.Slice && srcType.Kind() == reflect.Slice:
+		slice := reflect.MakeSlice(dstType, src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			if err := set(slice.Index(i), src.Index(i), output); err != nil {
+				return err
+			}
+		}
+		dst.Set(slice)
+	case dstType.Kind() == reflect.Array && srcType.Kind() == reflect.Array && dst.Len() == src.Len():
+		for i := 0; i < src.Len(); i++ {
+			if err := set(dst.Index(i), src.Index(i), output); err != nil {
+				return err
+			}
+		}
 	default:
 		return fmt.Errorf("abi: cannot unmarshal %v in to %v", src.Type(), dst.Type())
 	}
 	return nil
 }
 
+// setStruct assigns the fields of an unpacked tuple to the equally named fields
+// of dst, allowing tuples to be unpacked into user defined (e.g. generated) types.
+func setStruct(dst, src reflect.Value, output Argument) error {
+	for i := 0; i < src.NumField(); i++ {
+		name := src.Type().Field(i).Name
+		field := dst.FieldByName(name)
+		if !field.IsValid() {
+			return fmt.Errorf("abi: field %s can't be found in the given value", name)
+		}
+		if err := set(field, src.Field(i), output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // requireAssignable assures that `dest` is a pointer and it's not an interface.
 func requireAssignable(dst, src reflect.Value) error {
 	if dst.Kind() != reflect.Ptr && dst.Kind() != reflect.Interface {
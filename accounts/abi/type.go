@@ -38,6 +38,7 @@ const (
 	HashTy
 	FixedPointTy
 	FunctionTy
+	TupleTy
 )
 
 // Type is the reflection of the supported argument type
@@ -49,6 +50,10 @@ type Type struct {
 	Size int
 	T    byte // Our own type checking
 
+	TupleElems    []*Type  // Type information of all tuple fields
+	TupleRawNames []string // Raw field names of all tuple fields
+	TupleRawName  string   // Raw struct name defined in the source code, may be empty
+
 	stringKind string // holds the unparsed string for deriving signatures
 }
 
@@ -57,8 +62,9 @@ var (
 	typeRegex = regexp.MustCompile("([a-zA-Z]+)(([0-9]+)(x([0-9]+))?)?")
 )
 
-// NewType creates a new reflection type of abi type given in t.
-func NewType(t string) (typ Type, err error) {
+// NewType creates a new reflection type of abi type given in t. Tuple types are
+// assembled from the given components.
+func NewType(t string, components ...ArgumentMarshaling) (typ Type, err error) {
 	// check that array brackets are equal if they exist
 	if strings.Count(t, "[") != strings.Count(t, "]") {
 		return Type{}, fmt.Errorf("invalid arg type in abi")
@@ -71,12 +77,15 @@ func NewType(t string) (typ Type, err error) {
 	if strings.Count(t, "[") != 0 {
 		i := strings.LastIndex(t, "[")
 		// recursively embed the type
-		embeddedType, err := NewType(t[:i])
+		embeddedType, err := NewType(t[:i], components...)
 		if err != nil {
 			return Type{}, err
 		}
 		// grab the last cell and create a type from there
 		sliced := t[i:]
+		// tuples are signed by their components, not by their name
+		typ.stringKind = embeddedType.stringKind + sliced
+
 		// grab the slice size with regexp
 		re := regexp.MustCompile("[0-9]+")
 		intz := re.FindAllString(sliced, -1)
@@ -158,6 +167,44 @@ func NewType(t string) (typ Type, err error) {
 		typ.T = FunctionTy
 		typ.Size = 24
 		typ.Type = reflect.ArrayOf(24, reflect.TypeOf(byte(0)))
+	case "tuple":
+		if len(components) == 0 {
+			return Type{}, fmt.Errorf("abi: tuple without components")
+		}
+		var (
+			fields []reflect.StructField
+			elems  []*Type
+			names  []string
+			kinds  []string
+		)
+		used := make(map[string]bool)
+		for _, c := range components {
+			cType, err := NewType(c.Type, c.Components...)
+			if err != nil {
+				return Type{}, err
+			}
+			cType.setTupleRawName(c.InternalType)
+
+			name := capitalise(c.Name)
+			if name == "" {
+				return Type{}, fmt.Errorf("abi: purely anonymous or underscored tuple field is not supported")
+			}
+			if used[name] {
+				return Type{}, fmt.Errorf("abi: multiple tuple fields mapping to the same struct field '%s'", name)
+			}
+			used[name] = true
+
+			fields = append(fields, reflect.StructField{Name: name, Type: cType.Type})
+			elems = append(elems, &cType)
+			names = append(names, c.Name)
+			kinds = append(kinds, cType.stringKind)
+		}
+		typ.Kind = reflect.Struct
+		typ.Type = reflect.StructOf(fields)
+		typ.TupleElems = elems
+		typ.TupleRawNames = names
+		typ.T = TupleTy
+		typ.stringKind = "(" + strings.Join(kinds, ",") + ")"
 	default:
 		return Type{}, fmt.Errorf("unsupported arg type: %s", t)
 	}
@@ -178,23 +225,65 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 		return nil, err
 	}
 
-	if t.T == SliceTy || t.T == ArrayTy {
-		var packed []byte
+	switch t.T {
+	case SliceTy, ArrayTy:
+		var ret []byte
 
+		if t.requiresLengthPrefix() {
+			// append length
+			ret = append(ret, packNum(reflect.ValueOf(v.Len()))...)
+		}
+		// dynamic elements are referenced by offsets relative to the first one
+		offset := 0
+		offsetReq := isDynamicType(*t.Elem)
+		if offsetReq {
+			offset = getTypeSize(*t.Elem) * v.Len()
+		}
+		var tail []byte
 		for i := 0; i < v.Len(); i++ {
 			val, err := t.Elem.pack(v.Index(i))
 			if err != nil {
 				return nil, err
 			}
-			packed = append(packed, val...)
+			if !offsetReq {
+				ret = append(ret, val...)
+				continue
+			}
+			ret = append(ret, packNum(reflect.ValueOf(offset))...)
+			offset += len(val)
+			tail = append(tail, val...)
+		}
+		return append(ret, tail...), nil
+
+	case TupleTy:
+		// dynamic fields are referenced by offsets relative to the tuple start
+		offset := 0
+		for _, elem := range t.TupleElems {
+			offset += getTypeSize(*elem)
 		}
-		if t.T == SliceTy {
-			return packBytesSlice(packed, v.Len()), nil
-		} else if t.T == ArrayTy {
-			return packed, nil
+		var ret, tail []byte
+		for i, elem := range t.TupleElems {
+			field := v.FieldByName(capitalise(t.TupleRawNames[i]))
+			if !field.IsValid() {
+				return nil, fmt.Errorf("abi: field %s can't be found in the given value", t.TupleRawNames[i])
+			}
+			val, err := elem.pack(field)
+			if err != nil {
+				return nil, err
+			}
+			if isDynamicType(*elem) {
+				ret = append(ret, packNum(reflect.ValueOf(offset))...)
+				tail = append(tail, val...)
+				offset += len(val)
+			} else {
+				ret = append(ret, val...)
+			}
 		}
+		return append(ret, tail...), nil
+
+	default:
+		return packElement(t, v), nil
 	}
-	return packElement(t, v), nil
 }
 
 // requireLengthPrefix returns whether the type requires any sort of length
@@ -202,3 +291,62 @@ func (t Type) pack(v reflect.Value) ([]byte, error) {
 func (t Type) requiresLengthPrefix() bool {
 	return t.T == StringTy || t.T == BytesTy || t.T == SliceTy
 }
+
+// setTupleRawName extracts the struct name from a Solidity internal type (e.g.
+// "struct Contract.Name[]") and assigns it to the tuple the type is made of.
+func (t *Type) setTupleRawName(internalType string) {
+	if !strings.HasPrefix(internalType, "struct ") {
+		return
+	}
+	for t.T == SliceTy || t.T == ArrayTy {
+		t = t.Elem
+	}
+	if t.T != TupleTy {
+		return
+	}
+	name := strings.TrimPrefix(internalType, "struct ")
+	if i := strings.Index(name, "["); i >= 0 {
+		name = name[:i]
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	t.TupleRawName = name
+}
+
+// isDynamicType returns whether the type is encoded in the tail of its
+// enclosing tuple, referenced by an offset from the head.
+func isDynamicType(t Type) bool {
+	switch t.T {
+	case StringTy, BytesTy, SliceTy:
+		return true
+	case ArrayTy:
+		return isDynamicType(*t.Elem)
+	case TupleTy:
+		for _, elem := range t.TupleElems {
+			if isDynamicType(*elem) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getTypeSize returns the number of bytes the type occupies in the head of its
+// enclosing tuple. Dynamic types only occupy a single word holding their offset.
+func getTypeSize(t Type) int {
+	if isDynamicType(t) {
+		return 32
+	}
+	switch t.T {
+	case ArrayTy:
+		return t.Size * getTypeSize(*t.Elem)
+	case TupleTy:
+		size := 0
+		for _, elem := range t.TupleElems {
+			size += getTypeSize(*elem)
+		}
+		return size
+	}
+	return 32
+}
@@ -95,13 +95,16 @@ func readFixedBytes(t Type, word []byte) (interface{}, error) {
 
 // iteratively unpack elements
 func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error) {
-	if start+32*size > len(output) {
-		return nil, fmt.Errorf("abi: cannot marshal in to go array: offset %d would go over slice boundary (len=%d)", len(output), start+32*size)
+	if size < 0 {
+		return nil, fmt.Errorf("abi: cannot marshal in to go array: size %d is negative", size)
+	}
+	elemSize := getTypeSize(*t.Elem)
+	if start+elemSize*size > len(output) {
+		return nil, fmt.Errorf("abi: cannot marshal in to go array: offset %d would go over slice boundary (len=%d)", len(output), start+elemSize*size)
 	}
 
 	// this value will become our slice or our array, depending on the type
 	var refSlice reflect.Value
-	slice := output[start : start+size*32]
 
 	if t.T == SliceTy {
 		// declare our slice
@@ -113,11 +116,7 @@ func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error)
 		return nil, fmt.Errorf("abi: invalid type in array/slice unpacking stage")
 	}
 
-	for i, j := start, 0; j*32 < len(slice); i, j = i+32, j+1 {
-		// this corrects the arrangement so that we get all the underlying array values
-		if t.Elem.T == ArrayTy && j != 0 {
-			i = start + t.Elem.Size*32*j
-		}
+	for i, j := start, 0; j < size; i, j = i+elemSize, j+1 {
 		inter, err := toGoType(i, *t.Elem, output)
 		if err != nil {
 			return nil, err
@@ -130,6 +129,23 @@ func forEachUnpack(t Type, output []byte, start, size int) (interface{}, error)
 	return refSlice.Interface(), nil
 }
 
+// forTupleUnpack unpacks the fields of a tuple into a value of the anonymous
+// struct type representing it.
+func forTupleUnpack(t Type, output []byte) (interface{}, error) {
+	retval := reflect.New(t.Type).Elem()
+
+	offset := 0
+	for index, elem := range t.TupleElems {
+		marshalledValue, err := toGoType(offset, *elem, output)
+		if err != nil {
+			return nil, err
+		}
+		offset += getTypeSize(*elem)
+		retval.Field(index).Set(reflect.ValueOf(marshalledValue))
+	}
+	return retval.Interface(), nil
+}
+
 // toGoType parses the output bytes and recursively assigns the value of these bytes
 // into a go type with accordance with the ABI spec.
 func toGoType(index int, t Type, output []byte) (interface{}, error) {
@@ -154,9 +170,25 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 	}
 
 	switch t.T {
+	case TupleTy:
+		if isDynamicType(t) {
+			begin, err := offsetPointsTo(index, output)
+			if err != nil {
+				return nil, err
+			}
+			return forTupleUnpack(t, output[begin:])
+		}
+		return forTupleUnpack(t, output[index:])
 	case SliceTy:
-		return forEachUnpack(t, output, begin, end)
+		return forEachUnpack(t, output[begin:], 0, end)
 	case ArrayTy:
+		if isDynamicType(*t.Elem) {
+			begin, err := offsetPointsTo(index, output)
+			if err != nil {
+				return nil, err
+			}
+			return forEachUnpack(t, output[begin:], 0, t.Size)
+		}
 		return forEachUnpack(t, output, index, t.Size)
 	case StringTy: // variable arrays are written at the end of the return bytes
 		return string(output[begin : begin+end]), nil
@@ -179,6 +211,16 @@ func toGoType(index int, t Type, output []byte) (interface{}, error) {
 	}
 }
 
+// offsetPointsTo interprets a 32 byte slice as the offset of a dynamic array or
+// tuple, which is encoded without a length prefix.
+func offsetPointsTo(index int, output []byte) (int, error) {
+	offset := new(big.Int).SetBytes(output[index : index+32])
+	if offset.BitLen() > 63 || offset.Int64() > int64(len(output)) {
+		return 0, fmt.Errorf("abi: cannot marshal in to go type: offset %v would go over slice boundary (len=%d)", offset, len(output))
+	}
+	return int(offset.Int64()), nil
+}
+
 // interprets a 32 byte slice as an offset and then determines which indice to look to decode the type.
 func lengthPrefixPointsTo(index int, output []byte) (start int, length int, err error) {
 	offset := int(binary.BigEndian.Uint64(output[index+24 : index+32]))
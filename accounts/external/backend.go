@@ -0,0 +1,300 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package external implements an account backend which forwards all signing
+// requests over RPC to a signer running in a separate process.
+package external
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+	"time"
+
+	vapory "github.com/vaporyco/go-vapory"
+	"github.com/vaporyco/go-vapory/accounts"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/event"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/rpc"
+)
+
+// ExternalScheme is the protocol scheme prefixing account and wallet URLs.
+const ExternalScheme = "extapi"
+
+// refreshCycle is the time between two refreshes of the accounts disclosed by the
+// signer. Every refresh is a listing request the signer has to approve, so they
+// are kept rare.
+const refreshCycle = 30 * time.Second
+
+// BackendType is the reflect type of an external signer backend.
+var BackendType = reflect.TypeOf(&ExternalBackend{})
+
+// errTxMismatch is returned if the external signer returns a signed transaction
+// which differs from the one requested to be signed.
+var errTxMismatch = errors.New("signer returned a different transaction")
+
+// errSenderMismatch is returned if the external signer returns a transaction
+// signed by an account other than the requested one.
+var errSenderMismatch = errors.New("signer returned a transaction from a different sender")
+
+// ExternalBackend is an accounts.Backend exposing the single wallet of a remote
+// signer process.
+type ExternalBackend struct {
+	signers []accounts.Wallet
+}
+
+// NewExternalBackend creates a new account backend which forwards all the signing
+// requests to the external signer reachable at the given IPC path or HTTP URL.
+func NewExternalBackend(endpoint string) (*ExternalBackend, error) {
+	signer, err := NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalBackend{signers: []accounts.Wallet{signer}}, nil
+}
+
+// Wallets implements accounts.Backend, returning the external signer.
+func (eb *ExternalBackend) Wallets() []accounts.Wallet {
+	return eb.signers
+}
+
+// Subscribe implements accounts.Backend, creating an async subscription to
+// receive notifications on the addition or removal of wallets. The external
+// signer is permanent, so no events are ever fired.
+func (eb *ExternalBackend) Subscribe(sink chan<- accounts.WalletEvent) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		<-quit
+		return nil
+	})
+}
+
+// ExternalSigner is an accounts.Wallet forwarding all requests over RPC to a
+// signer, which holds the keys and decides whether to serve them.
+type ExternalSigner struct {
+	client   *rpc.Client // RPC client connected to the signer process
+	endpoint string      // IPC path or HTTP URL of the signer
+
+	cache   []accounts.Account // Accounts last reported by the signer
+	cacheMu sync.RWMutex       // Lock protecting the account cache
+
+	quit     chan struct{} // Channel closed to stop the refresh loop
+	quitOnce sync.Once     // Guard ensuring the signer is closed only once
+}
+
+// NewExternalSigner connects to the signer reachable at the given IPC path or
+// HTTP URL, ensuring that it responds to requests.
+func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	signer := &ExternalSigner{
+		client:   client,
+		endpoint: endpoint,
+		quit:     make(chan struct{}),
+	}
+	version, err := signer.version()
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("external signer unreachable: %v", err)
+	}
+	log.Info("Connected to external signer", "endpoint", endpoint, "version", version)
+
+	if err := signer.refresh(); err != nil {
+		log.Warn("Failed to list external accounts", "err", err)
+	}
+	go signer.refreshLoop()
+
+	return signer, nil
+}
+
+// URL implements accounts.Wallet, returning the URL of the signer.
+func (signer *ExternalSigner) URL() accounts.URL {
+	return accounts.URL{Scheme: ExternalScheme, Path: signer.endpoint}
+}
+
+// Status implements accounts.Wallet, returning the version of the signer, or
+// the error encountered while reaching it.
+func (signer *ExternalSigner) Status() (string, error) {
+	version, err := signer.version()
+	if err != nil {
+		return "Offline", err
+	}
+	return fmt.Sprintf("Online, version %s", version), nil
+}
+
+// Open implements accounts.Wallet. The signer manages its own keys, so there is
+// nothing to initialize.
+func (signer *ExternalSigner) Open(passphrase string) error { return nil }
+
+// Close implements accounts.Wallet, stopping the account refreshes and closing
+// the connection to the signer. The wallet is unusable afterwards.
+func (signer *ExternalSigner) Close() error {
+	signer.quitOnce.Do(func() {
+		close(signer.quit)
+		signer.client.Close()
+	})
+	return nil
+}
+
+// Accounts implements accounts.Wallet, returning the list of accounts the signer
+// last disclosed. The list is refreshed periodically in the background.
+func (signer *ExternalSigner) Accounts() []accounts.Account {
+	signer.cacheMu.RLock()
+	defer signer.cacheMu.RUnlock()
+
+	cpy := make([]accounts.Account, len(signer.cache))
+	copy(cpy, signer.cache)
+	return cpy
+}
+
+// Contains implements accounts.Wallet, returning whether a particular account is
+// or is not disclosed by the signer. Only the cached account list is consulted.
+func (signer *ExternalSigner) Contains(account accounts.Account) bool {
+	if account.URL != (accounts.URL{}) && account.URL != signer.URL() {
+		return false
+	}
+	signer.cacheMu.RLock()
+	defer signer.cacheMu.RUnlock()
+
+	for _, acc := range signer.cache {
+		if acc.Address == account.Address {
+			return true
+		}
+	}
+	return false
+}
+
+// Derive implements accounts.Wallet, but is a noop for external signers since
+// there is no notion of hierarchical account derivation for them.
+func (signer *ExternalSigner) Derive(path accounts.DerivationPath, pin bool) (accounts.Account, error) {
+	return accounts.Account{}, accounts.ErrNotSupported
+}
+
+// SelfDerive implements accounts.Wallet, but is a noop for external signers since
+// there is no notion of hierarchical account derivation for them.
+func (signer *ExternalSigner) SelfDerive(base accounts.DerivationPath, chain vapory.ChainStateReader) {
+}
+
+// SignHash implements accounts.Wallet, requesting the signer to sign the given
+// hash with the given account.
+func (signer *ExternalSigner) SignHash(account accounts.Account, hash []byte) ([]byte, error) {
+	var sig hexutil.Bytes
+	if err := signer.client.Call(&sig, "account_signHash", account.Address, hexutil.Bytes(hash)); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SignTx implements accounts.Wallet, requesting the signer to sign the given
+// transaction with the given account. The returned transaction is verified to
+// be the requested one, signed by the requested account.
+func (signer *ExternalSigner) SignTx(account accounts.Account, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	raw, err := rlp.EncodeToBytes(tx)
+	if err != nil {
+		return nil, err
+	}
+	var (
+		signed hexutil.Bytes
+		chain  *hexutil.Big
+	)
+	if chainID != nil {
+		chain = (*hexutil.Big)(chainID)
+	}
+	if err := signer.client.Call(&signed, "account_signTransaction", account.Address, hexutil.Bytes(raw), chain); err != nil {
+		return nil, err
+	}
+	res := new(types.Transaction)
+	if err := rlp.DecodeBytes(signed, res); err != nil {
+		return nil, err
+	}
+	// Ensure the signer did not tamper with the transaction
+	var txSigner types.Signer = types.HomesteadSigner{}
+	if chainID != nil {
+		txSigner = types.NewEIP155Signer(chainID)
+	}
+	if txSigner.Hash(res) != txSigner.Hash(tx) {
+		return nil, errTxMismatch
+	}
+	if sender, err := types.Sender(txSigner, res); err != nil || sender != account.Address {
+		return nil, errSenderMismatch
+	}
+	return res, nil
+}
+
+// SignHashWithPassphrase implements accounts.Wallet. The signer is responsible
+// for unlocking its own accounts, so this method is not supported.
+func (signer *ExternalSigner) SignHashWithPassphrase(account accounts.Account, passphrase string, hash []byte) ([]byte, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// SignTxWithPassphrase implements accounts.Wallet. The signer is responsible for
+// unlocking its own accounts, so this method is not supported.
+func (signer *ExternalSigner) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return nil, accounts.ErrNotSupported
+}
+
+// refresh retrieves the accounts disclosed by the signer, replacing the cached
+// account list.
+func (signer *ExternalSigner) refresh() error {
+	var addrs []common.Address
+	if err := signer.client.Call(&addrs, "account_list"); err != nil {
+		return err
+	}
+	accs := make([]accounts.Account, len(addrs))
+	for i, addr := range addrs {
+		accs[i] = accounts.Account{Address: addr, URL: signer.URL()}
+	}
+	signer.cacheMu.Lock()
+	signer.cache = accs
+	signer.cacheMu.Unlock()
+
+	return nil
+}
+
+// refreshLoop periodically refreshes the cached account list. If the signer
+// cannot be reached, the previously known accounts are retained. The loop runs
+// until the signer is closed.
+func (signer *ExternalSigner) refreshLoop() {
+	ticker := time.NewTicker(refreshCycle)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := signer.refresh(); err != nil {
+				log.Warn("Failed to list external accounts", "err", err)
+			}
+		case <-signer.quit:
+			return
+		}
+	}
+}
+
+// version retrieves the version reported by the signer.
+func (signer *ExternalSigner) version() (string, error) {
+	var version string
+	if err := signer.client.Call(&version, "account_version"); err != nil {
+		return "", err
+	}
+	return version, nil
+}
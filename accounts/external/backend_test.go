@@ -0,0 +1,185 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package external
+
+import (
+	"crypto/ecdsa"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/accounts"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/rpc"
+)
+
+// SignerService is a mock external signer holding a single key, which can be told
+// to tamper with the transactions it signs.
+type SignerService struct {
+	key    *ecdsa.PrivateKey
+	tamper bool
+	lists  int32 // Number of account listings served
+}
+
+func (s *SignerService) Version() string { return "1.0.0-test" }
+
+func (s *SignerService) List() []common.Address {
+	atomic.AddInt32(&s.lists, 1)
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *SignerService) SignHash(addr common.Address, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	return crypto.Sign(hash, s.key)
+}
+
+func (s *SignerService) SignTransaction(addr common.Address, rawTx hexutil.Bytes, chainID *hexutil.Big) (hexutil.Bytes, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(rawTx, tx); err != nil {
+		return nil, err
+	}
+	if s.tamper {
+		tx = types.NewTransaction(tx.Nonce(), common.Address{0xde, 0xad}, tx.Value(), tx.Gas(), tx.GasPrice(), tx.Data())
+	}
+	signed, err := types.SignTx(tx, types.NewEIP155Signer((*big.Int)(chainID)), s.key)
+	if err != nil {
+		return nil, err
+	}
+	return rlp.EncodeToBytes(signed)
+}
+
+// startTestSigner starts a mock external signer on an IPC endpoint, returning
+// the endpoint and a cleanup function to tear it down.
+func startTestSigner(t *testing.T, signer *SignerService) (string, func()) {
+	dir, err := ioutil.TempDir("", "extapi-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", signer); err != nil {
+		t.Fatalf("failed to register signer: %v", err)
+	}
+	endpoint := filepath.Join(dir, "signer.ipc")
+	listener, err := rpc.CreateIPCListener(endpoint)
+	if err != nil {
+		t.Fatalf("failed to open IPC endpoint: %v", err)
+	}
+	go server.ServeListener(listener)
+
+	return endpoint, func() {
+		listener.Close()
+		server.Stop()
+		os.RemoveAll(dir)
+	}
+}
+
+// Tests that the external backend exposes the accounts of the remote signer and
+// forwards signing requests to it.
+func TestExternalSigning(t *testing.T) {
+	key, _ := crypto.GenerateKey()
+	signer := &SignerService{key: key}
+
+	endpoint, stop := startTestSigner(t, signer)
+	defer stop()
+
+	backend, err := NewExternalBackend(endpoint)
+	if err != nil {
+		t.Fatalf("failed to connect to signer: %v", err)
+	}
+	wallets := backend.Wallets()
+	if len(wallets) != 1 {
+		t.Fatalf("wallet count mismatch: have %d, want 1", len(wallets))
+	}
+	wallet := wallets[0]
+
+	if status, err := wallet.Status(); err != nil {
+		t.Fatalf("failed to retrieve signer status: %v (%s)", err, status)
+	}
+	// Ensure the remote account is exposed
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+
+	accs := wallet.Accounts()
+	if len(accs) != 1 || accs[0].Address != addr || accs[0].URL != wallet.URL() {
+		t.Fatalf("accounts mismatch: have %v, want [%x@%v]", accs, addr, wallet.URL())
+	}
+	if !wallet.Contains(accounts.Account{Address: addr}) {
+		t.Errorf("remote account not contained in wallet")
+	}
+	if wallet.Contains(accounts.Account{Address: common.Address{0x01}}) {
+		t.Errorf("unknown account contained in wallet")
+	}
+	if lists := atomic.LoadInt32(&signer.lists); lists != 1 {
+		t.Errorf("account listing count mismatch: have %d, want 1", lists)
+	}
+	// Sign a hash and a transaction, and ensure the signatures check out
+	hash := crypto.Keccak256([]byte("hello"))
+
+	sig, err := wallet.SignHash(accs[0], hash)
+	if err != nil {
+		t.Fatalf("failed to sign hash: %v", err)
+	}
+	if pub, err := crypto.SigToPub(hash, sig); err != nil || crypto.PubkeyToAddress(*pub) != addr {
+		t.Errorf("hash signer mismatch: have %v, want %x", err, addr)
+	}
+	chainID := big.NewInt(1)
+	tx := types.NewTransaction(0, common.Address{0x02}, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	signed, err := wallet.SignTx(accs[0], tx, chainID)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	if sender, err := types.Sender(types.NewEIP155Signer(chainID), signed); err != nil || sender != addr {
+		t.Errorf("transaction sender mismatch: have %x (%v), want %x", sender, err, addr)
+	}
+	// Ensure a tampered transaction is rejected
+	signer.tamper = true
+	if _, err := wallet.SignTx(accs[0], tx, chainID); err != errTxMismatch {
+		t.Errorf("tampered transaction error mismatch: have %v, want %v", err, errTxMismatch)
+	}
+	// Ensure passphrase based signing is refused
+	if _, err := wallet.SignTxWithPassphrase(accs[0], "", tx, chainID); err != accounts.ErrNotSupported {
+		t.Errorf("passphrase signing error mismatch: have %v, want %v", err, accounts.ErrNotSupported)
+	}	// Ensure closing the wallet disconnects from the signer
+	if err := wallet.Close(); err != nil {
+		t.Fatalf("failed to close wallet: %v", err)
+	}
+	if _, err := wallet.Status(); err == nil {
+		t.Errorf("closed wallet still reachable")
+	}
+	if err := wallet.Close(); err != nil {
+		t.Errorf("failed to close wallet twice: %v", err)
+	}
+}
+
+// Tests that connecting to an unreachable signer fails.
+func TestExternalUnreachable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "extapi-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if _, err := NewExternalBackend(filepath.Join(dir, "missing.ipc")); err == nil {
+		t.Fatalf("connected to missing signer")
+	}
+}
@@ -78,6 +78,7 @@ var (
 		executablePath("vappupp"),
 		executablePath("rlpdump"),
 		executablePath("swarm"),
+		executablePath("vapsigner"),
 		executablePath("wnode"),
 	}
 
@@ -111,6 +112,10 @@ var (
 			Name:        "swarm",
 			Description: "Vapory Swarm daemon and tools",
 		},
+		{
+			Name:        "vapsigner",
+			Description: "Standalone signer serving keystore accounts to a remote gvap over RPC.",
+		},
 		{
 			Name:        "wnode",
 			Description: "Vapory Whisper diagnostic tool",
@@ -36,6 +36,8 @@ import (
 	"github.com/vaporyco/go-vapory/event"
 	"github.com/vaporyco/go-vapory/log"
 	"github.com/vaporyco/go-vapory/trie"
+	"github.com/olekukonko/tablewriter"
+	"github.com/syndtr/goleveldb/leveldb"
 	"github.com/syndtr/goleveldb/leveldb/util"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -67,6 +69,8 @@ It expects the genesis file as argument.`,
 			utils.DataDirFlag,
 			utils.CacheFlag,
 			utils.LightModeFlag,
+			utils.GCModeFlag,
+			utils.CacheGCFlag,
 		},
 		Category: "BLOCKCHAIN COMMANDS",
 		Description: `
@@ -138,6 +142,23 @@ Remove blockchain and state databases`,
 The arguments are interpreted as block numbers or hashes.
 Use "vapory dump 0" to dump the genesis block.`,
 	}
+	inspectdbCommand = cli.Command{
+		Action:    utils.MigrateFlags(inspectDB),
+		Name:      "inspect-db",
+		Usage:     "Inspect the storage size of each data category in the database",
+		ArgsUsage: " ",
+		Flags: []cli.Flag{
+			utils.DataDirFlag,
+			utils.CacheFlag,
+			utils.LightModeFlag,
+		},
+		Category: "BLOCKCHAIN COMMANDS",
+		Description: `
+The inspect-db command iterates over the entire chain database and reports the
+number of entries and their total size for each data category (headers, bodies,
+receipts, trie nodes, etc). Chain data not belonging to the canonical chain is
+reported as dangling, and keys not matching any known schema as unknown.`,
+	}
 )
 
 // initGenesis will initialise the given JSON format genesis file and writes it as
@@ -211,13 +232,15 @@ func importChain(ctx *cli.Context) error {
 			}
 		}
 	}
-
+	chain.Stop()
 	fmt.Printf("Import done in %v.\n\n", time.Since(start))
 
 	// Output pre-compaction stats mostly to see the import trashing
-	db := chainDb.(*vapdb.LDBDatabase)
-
-	stats, err := db.LDB().GetProperty("leveldb.stats")
+	db := chainLevelDB(chainDb)
+	if db == nil {
+		utils.Fatalf("Chain database is not backed by leveldb")
+	}
+	stats, err := db.GetProperty("leveldb.stats")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
@@ -241,12 +264,12 @@ func importChain(ctx *cli.Context) error {
 	// Compact the entire database to more accurately measure disk io and print the stats
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = db.LDB().CompactRange(util.Range{}); err != nil {
+	if err = db.CompactRange(util.Range{}); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
 
-	stats, err = db.LDB().GetProperty("leveldb.stats")
+	stats, err = db.GetProperty("leveldb.stats")
 	if err != nil {
 		utils.Fatalf("Failed to read database stats: %v", err)
 	}
@@ -327,7 +350,11 @@ func copyDb(ctx *cli.Context) error {
 	// Compact the entire database to remove any sync overhead
 	start = time.Now()
 	fmt.Println("Compacting entire database...")
-	if err = chainDb.(*vapdb.LDBDatabase).LDB().CompactRange(util.Range{}); err != nil {
+	ldb := chainLevelDB(chainDb)
+	if ldb == nil {
+		utils.Fatalf("Chain database is not backed by leveldb")
+	}
+	if err = ldb.CompactRange(util.Range{}); err != nil {
 		utils.Fatalf("Compaction failed: %v", err)
 	}
 	fmt.Printf("Compaction done in %v.\n\n", time.Since(start))
@@ -335,6 +362,17 @@ func copyDb(ctx *cli.Context) error {
 	return nil
 }
 
+// chainLevelDB retrieves the leveldb instance backing the chain database, also
+// if it is wrapped by the ancient freezer, or nil if there is none.
+func chainLevelDB(db vapdb.Database) *leveldb.DB {
+	if ldb, ok := db.(interface {
+		LDB() *leveldb.DB
+	}); ok {
+		return ldb.LDB()
+	}
+	return nil
+}
+
 func removeDB(ctx *cli.Context) error {
 	stack, _ := makeConfigNode(ctx)
 
@@ -390,6 +428,32 @@ func dump(ctx *cli.Context) error {
 	return nil
 }
 
+func inspectDB(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	chainDb := utils.MakeChainDatabase(ctx, stack)
+	defer chainDb.Close()
+
+	start := time.Now()
+	stats, err := core.InspectDatabase(chainDb)
+	if err != nil {
+		utils.Fatalf("Failed to inspect database: %v", err)
+	}
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetHeader([]string{"Category", "Items", "Size", "Dangling items", "Dangling size"})
+	table.SetAlignment(tablewriter.ALIGN_RIGHT)
+	table.SetAutoFormatHeaders(false)
+
+	for _, stat := range stats.Categories() {
+		table.Append([]string{stat.Name, fmt.Sprint(stat.Count), stat.Size.String(), fmt.Sprint(stat.DanglingCount), stat.DanglingSize.String()})
+	}
+	total := stats.Total()
+	table.SetFooter([]string{total.Name, fmt.Sprint(total.Count), total.Size.String(), fmt.Sprint(total.DanglingCount), total.DanglingSize.String()})
+	table.Render()
+
+	log.Info("Database inspection done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
+
 // hashish returns true for strings that look like hashes.
 func hashish(x string) bool {
 	_, err := strconv.Atoi(x)
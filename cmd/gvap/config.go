@@ -32,6 +32,7 @@ import (
 	"github.com/vaporyco/go-vapory/contracts/release"
 	"github.com/vaporyco/go-vapory/dashboard"
 	"github.com/vaporyco/go-vapory/vap"
+	"github.com/vaporyco/go-vapory/vap/filters"
 	"github.com/vaporyco/go-vapory/node"
 	"github.com/vaporyco/go-vapory/params"
 	whisper "github.com/vaporyco/go-vapory/whisper/whisperv5"
@@ -173,6 +174,12 @@ func makeFullNode(ctx *cli.Context) *node.Node {
 		utils.RegisterShhService(stack, &cfg.Shh)
 	}
 
+	// Add the GraphQL server if requested.
+	if endpoint := cfg.Node.GraphQLEndpoint(); endpoint != "" {
+		limits := filters.Limits{MaxBlocks: cfg.Vap.FilterMaxBlocks, MaxLogs: cfg.Vap.FilterMaxLogs}
+		utils.RegisterGraphQLService(stack, endpoint, cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts, limits)
+	}
+
 	// Add the Vapory Stats daemon if requested.
 	if cfg.Vapstats.URL != "" {
 		utils.RegisterVapStatsService(stack, cfg.Vapstats.URL)
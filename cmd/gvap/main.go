@@ -61,6 +61,7 @@ var (
 		utils.DataDirFlag,
 		utils.KeyStoreDirFlag,
 		utils.NoUSBFlag,
+		utils.ExternalSignerFlag,
 		utils.DashboardEnabledFlag,
 		utils.DashboardAddrFlag,
 		utils.DashboardPortFlag,
@@ -82,6 +83,8 @@ var (
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		utils.TxPoolPriorityFlag,
+		utils.TxPoolPrioritySlotsFlag,
 		utils.FastSyncFlag,
 		utils.LightModeFlag,
 		utils.SyncModeFlag,
@@ -90,6 +93,12 @@ var (
 		utils.LightKDFFlag,
 		utils.CacheFlag,
 		utils.TrieCacheGenFlag,
+		utils.GCModeFlag,
+		utils.CacheGCFlag,
+		utils.CacheGCIntervalFlag,
+		utils.CacheGCRetentionFlag,
+		utils.SnapshotFlag,
+		utils.FreezerThresholdFlag,
 		utils.ListenPortFlag,
 		utils.MaxPeersFlag,
 		utils.MaxPendingPeersFlag,
@@ -126,11 +135,27 @@ var (
 		utils.RPCListenAddrFlag,
 		utils.RPCPortFlag,
 		utils.RPCApiFlag,
+		utils.RPCVirtualHostsFlag,
 		utils.WSEnabledFlag,
 		utils.WSListenAddrFlag,
 		utils.WSPortFlag,
 		utils.WSApiFlag,
 		utils.WSAllowedOriginsFlag,
+		utils.GraphQLEnabledFlag,
+		utils.GraphQLListenAddrFlag,
+		utils.GraphQLPortFlag,
+		utils.GraphQLCORSDomainFlag,
+		utils.GraphQLVirtualHostsFlag,
+		utils.RPCAuthFileFlag,
+		utils.RPCJWTSecretFlag,
+		utils.RPCBatchLimitFlag,
+		utils.RPCResponseLimitFlag,
+		utils.RPCCallTimeoutFlag,
+		utils.RPCRateLimitFlag,
+		utils.RPCRateBurstFlag,
+		utils.RPCMethodCostsFlag,
+		utils.RPCLogsRangeFlag,
+		utils.RPCLogsLimitFlag,
 		utils.IPCDisabledFlag,
 		utils.IPCPathFlag,
 	}
@@ -155,6 +180,8 @@ func init() {
 		copydbCommand,
 		removedbCommand,
 		dumpCommand,
+		inspectdbCommand,
+		snapshotCommand,
 		// See monitorcmd.go:
 		monitorCommand,
 		// See accountcmd.go:
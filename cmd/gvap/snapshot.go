@@ -0,0 +1,142 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"github.com/vaporyco/go-vapory/cmd/utils"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core"
+	"github.com/vaporyco/go-vapory/log"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	pruneBlocksFlag = cli.Uint64Flag{
+		Name:  "blocks",
+		Value: 128,
+		Usage: "Number of recent blocks whose state to retain",
+	}
+	pruneBloomSizeFlag = cli.Uint64Flag{
+		Name:  "bloom-size",
+		Value: 2048,
+		Usage: "Megabytes of memory allocated to the bloom filter tracking the retained state",
+	}
+	pruneDryRunFlag = cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Report the reclaimable storage without deleting anything",
+	}
+	snapshotCommand = cli.Command{
+		Name:     "snapshot",
+		Usage:    "Manage the state data stored in the database",
+		Category: "BLOCKCHAIN COMMANDS",
+		Subcommands: []cli.Command{
+			{
+				Name:      "prune-state",
+				Usage:     "Delete the trie nodes and code of stale states from the database",
+				ArgsUsage: " ",
+				Action:    utils.MigrateFlags(pruneState),
+				Category:  "BLOCKCHAIN COMMANDS",
+				Flags: []cli.Flag{
+					utils.DataDirFlag,
+					utils.CacheFlag,
+					utils.TestnetFlag,
+					utils.RinkebyFlag,
+					pruneBlocksFlag,
+					pruneBloomSizeFlag,
+					pruneDryRunFlag,
+				},
+				Description: `
+gvap snapshot prune-state --blocks <N>
+
+will mark every trie node and contract code reachable from the states of the
+last N blocks (counting back from the current head) and from the genesis state,
+then delete all other trie nodes and contract code from the chain database, and
+finally compact it. The retained entries are tracked in a bloom filter sized by
+--bloom-size; the larger it is, the fewer stale entries escape deletion.
+
+The node must not be running while pruning. Progress is checkpointed into the
+database, so an interrupted run is resumed when the command is restarted. With
+--dry-run nothing is deleted, only the reclaimable storage is reported.`,
+			},
+		},
+	}
+)
+
+// pruneState deletes all the state data not belonging to recent blocks.
+func pruneState(ctx *cli.Context) error {
+	stack, _ := makeConfigNode(ctx)
+	chain, chainDb := utils.MakeChain(ctx, stack)
+	defer chainDb.Close()
+
+	// Gather the state roots of the recent blocks to retain, newest first
+	var (
+		blocks = ctx.Uint64(pruneBlocksFlag.Name)
+		bloom  = ctx.Uint64(pruneBloomSizeFlag.Name)
+		dryRun = ctx.Bool(pruneDryRunFlag.Name)
+		head   = chain.CurrentBlock()
+		roots  []common.Hash
+		seen   = make(map[common.Hash]bool)
+	)
+	if blocks == 0 {
+		utils.Fatalf("At least one block's state must be retained")
+	}
+	if bloom == 0 {
+		utils.Fatalf("The bloom filter must not be empty")
+	}
+	for i := uint64(0); i < blocks && i <= head.NumberU64(); i++ {
+		header := chain.GetHeaderByNumber(head.NumberU64() - i)
+		if header == nil {
+			break
+		}
+		if !seen[header.Root] {
+			seen[header.Root] = true
+			roots = append(roots, header.Root)
+		}
+	}
+	chain.Stop()
+
+	log.Info("Pruning stale state", "head", head.NumberU64(), "blocks", blocks, "roots", len(roots), "bloom", bloom, "dryrun", dryRun)
+	start := time.Now()
+
+	stats, err := core.PruneState(chainDb, roots, bloom*1024*1024, dryRun)
+	if err != nil {
+		utils.Fatalf("State pruning failed: %v", err)
+	}
+	if dryRun {
+		fmt.Printf("Retained %d trie nodes and codes, %d reclaimable (%v)\n", stats.Marked, stats.Pruned, stats.Size)
+		return nil
+	}
+	log.Info("Pruned stale state", "retained", stats.Marked, "deleted", stats.Pruned, "reclaimed", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Compact the entire database to actually release the deleted storage
+	ldb := chainLevelDB(chainDb)
+	if ldb == nil {
+		log.Warn("Chain database not backed by leveldb, skipping compaction")
+		return nil
+	}
+	start = time.Now()
+	log.Info("Compacting chain database")
+	if err := ldb.CompactRange(util.Range{}); err != nil {
+		utils.Fatalf("Compaction failed: %v", err)
+	}
+	log.Info("Database compaction done", "elapsed", common.PrettyDuration(time.Since(start)))
+	return nil
+}
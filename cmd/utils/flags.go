@@ -19,6 +19,8 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math/big"
@@ -42,9 +44,11 @@ import (
 	"github.com/vaporyco/go-vapory/dashboard"
 	"github.com/vaporyco/go-vapory/vap"
 	"github.com/vaporyco/go-vapory/vap/downloader"
+	"github.com/vaporyco/go-vapory/vap/filters"
 	"github.com/vaporyco/go-vapory/vap/gasprice"
 	"github.com/vaporyco/go-vapory/vapdb"
 	"github.com/vaporyco/go-vapory/vapstats"
+	"github.com/vaporyco/go-vapory/graphql"
 	"github.com/vaporyco/go-vapory/les"
 	"github.com/vaporyco/go-vapory/log"
 	"github.com/vaporyco/go-vapory/metrics"
@@ -55,6 +59,7 @@ import (
 	"github.com/vaporyco/go-vapory/p2p/nat"
 	"github.com/vaporyco/go-vapory/p2p/netutil"
 	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/rpc"
 	whisper "github.com/vaporyco/go-vapory/whisper/whisperv5"
 	"gopkg.in/urfave/cli.v1"
 )
@@ -126,6 +131,10 @@ var (
 		Name:  "nousb",
 		Usage: "Disables monitoring for and managing USB hardware wallets",
 	}
+	ExternalSignerFlag = cli.StringFlag{
+		Name:  "signer",
+		Usage: "External signer to forward signing requests to (IPC path or HTTP URL)",
+	}
 	NetworkIdFlag = cli.Uint64Flag{
 		Name:  "networkid",
 		Usage: "Network identifier (integer, 1=Frontier, 2=Morden (disused), 3=Ropsten, 4=Rinkeby)",
@@ -290,6 +299,15 @@ var (
 		Usage: "Maximum amount of time non-executable transaction are queued",
 		Value: vap.DefaultConfig.TxPool.Lifetime,
 	}
+	TxPoolPriorityFlag = cli.StringFlag{
+		Name:  "txpool.priority",
+		Usage: "Comma separated remote senders to evict only after all other remote transactions",
+	}
+	TxPoolPrioritySlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.priorityslots",
+		Usage: "Executable transaction slots reserved for priority senders on top of the global ones",
+		Value: vap.DefaultConfig.TxPool.PrioritySlots,
+	}
 	// Performance tuning settings
 	CacheFlag = cli.IntFlag{
 		Name:  "cache",
@@ -301,6 +319,34 @@ var (
 		Usage: "Number of trie node generations to keep in memory",
 		Value: int(state.MaxTrieCacheGen),
 	}
+	GCModeFlag = cli.StringFlag{
+		Name:  "gcmode",
+		Usage: `Blockchain garbage collection mode ("full", "archive")`,
+		Value: "full",
+	}
+	FreezerThresholdFlag = cli.Uint64Flag{
+		Name:  "freezer.threshold",
+		Usage: fmt.Sprintf("Number of recent blocks to keep in the key-value database before moving them into the ancient store (0 = disabled, minimum %d)", core.MinFreezerThreshold),
+	}
+	SnapshotFlag = cli.BoolFlag{
+		Name:  "snapshot",
+		Usage: "Maintain a flat snapshot of the state for fast state reads",
+	}
+	CacheGCFlag = cli.IntFlag{
+		Name:  "cache.gc",
+		Usage: "Megabytes of memory allowed for in-memory trie nodes before flushing to disk",
+		Value: vap.DefaultConfig.TrieCache,
+	}
+	CacheGCIntervalFlag = cli.Uint64Flag{
+		Name:  "cache.gc.interval",
+		Usage: "Number of blocks after which in-memory trie nodes are flushed to disk (0 = memory/time limits only)",
+		Value: vap.DefaultConfig.TrieBlockInterval,
+	}
+	CacheGCRetentionFlag = cli.Uint64Flag{
+		Name:  "cache.gc.retention",
+		Usage: "Number of recent block states to retain in memory before garbage collecting them",
+		Value: vap.DefaultConfig.TriesInMemory,
+	}
 	// Miner settings
 	MiningEnabledFlag = cli.BoolFlag{
 		Name:  "mine",
@@ -383,6 +429,35 @@ var (
 		Usage: "Comma separated list of domains from which to accept cross origin requests (browser enforced)",
 		Value: "",
 	}
+	RPCVirtualHostsFlag = cli.StringFlag{
+		Name:  "rpcvhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.HTTPVirtualHosts, ","),
+	}
+	GraphQLEnabledFlag = cli.BoolFlag{
+		Name:  "graphql",
+		Usage: "Enable the GraphQL server",
+	}
+	GraphQLListenAddrFlag = cli.StringFlag{
+		Name:  "graphqladdr",
+		Usage: "GraphQL server listening interface",
+		Value: node.DefaultGraphQLHost,
+	}
+	GraphQLPortFlag = cli.IntFlag{
+		Name:  "graphqlport",
+		Usage: "GraphQL server listening port",
+		Value: node.DefaultGraphQLPort,
+	}
+	GraphQLCORSDomainFlag = cli.StringFlag{
+		Name:  "graphqlcorsdomain",
+		Usage: "Comma separated list of domains from which to accept cross origin requests to the GraphQL server (browser enforced)",
+		Value: "",
+	}
+	GraphQLVirtualHostsFlag = cli.StringFlag{
+		Name:  "graphqlvhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept GraphQL requests (server enforced). Accepts '*' wildcard.",
+		Value: strings.Join(node.DefaultConfig.GraphQLVirtualHosts, ","),
+	}
 	RPCApiFlag = cli.StringFlag{
 		Name:  "rpcapi",
 		Usage: "API's offered over the HTTP-RPC interface",
@@ -420,6 +495,54 @@ var (
 		Usage: "Origins from which to accept websockets requests",
 		Value: "",
 	}
+	RPCAuthFileFlag = cli.StringFlag{
+		Name:  "rpcauth",
+		Usage: "JSON file listing the credentials (API keys, JWT secrets) and their permitted APIs required by the HTTP and WS-RPC servers",
+	}
+	RPCBatchLimitFlag = cli.IntFlag{
+		Name:  "rpcbatchlimit",
+		Usage: "Maximum number of requests in a batch accepted by the HTTP and WS-RPC servers (0 = unlimited)",
+		Value: node.DefaultConfig.RPCBatchLimit,
+	}
+	RPCResponseLimitFlag = cli.IntFlag{
+		Name:  "rpcresponselimit",
+		Usage: "Maximum size in bytes of the responses written by the HTTP and WS-RPC servers (0 = unlimited)",
+		Value: node.DefaultConfig.RPCResponseLimit,
+	}
+	RPCCallTimeoutFlag = cli.DurationFlag{
+		Name:  "rpccalltimeout",
+		Usage: "Maximum execution time of a method call on the HTTP and WS-RPC servers (0 = unlimited)",
+		Value: node.DefaultConfig.RPCCallTimeout,
+	}
+	RPCRateLimitFlag = cli.Float64Flag{
+		Name:  "rpcratelimit",
+		Usage: "Requests per second each client may issue to the HTTP and WS-RPC servers (0 = unlimited)",
+		Value: node.DefaultConfig.RPCRateLimits.Rate,
+	}
+	RPCRateBurstFlag = cli.IntFlag{
+		Name:  "rpcrateburst",
+		Usage: "Maximum burst of requests each client may issue to the HTTP and WS-RPC servers",
+		Value: node.DefaultConfig.RPCRateLimits.Burst,
+	}
+	RPCMethodCostsFlag = cli.StringFlag{
+		Name:  "rpcmethodcosts",
+		Usage: "Comma separated request costs of expensive methods for rate limiting (e.g. vap_getLogs=10,debug_*=50)",
+		Value: "",
+	}
+	RPCJWTSecretFlag = cli.StringFlag{
+		Name:  "rpcjwtsecret",
+		Usage: "File containing a hex encoded secret for verifying bearer tokens (JWT, expiring) required by the HTTP and WS-RPC servers",
+	}
+	RPCLogsRangeFlag = cli.Uint64Flag{
+		Name:  "rpclogsrange",
+		Usage: "Maximum number of blocks searched by a single log query (0 = unlimited)",
+		Value: vap.DefaultConfig.FilterMaxBlocks,
+	}
+	RPCLogsLimitFlag = cli.Uint64Flag{
+		Name:  "rpclogslimit",
+		Usage: "Maximum number of logs returned by a single log query (0 = unlimited)",
+		Value: vap.DefaultConfig.FilterMaxLogs,
+	}
 	ExecFlag = cli.StringFlag{
 		Name:  "exec",
 		Usage: "Execute JavaScript statement",
@@ -676,6 +799,29 @@ func setHTTP(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(RPCApiFlag.Name) {
 		cfg.HTTPModules = splitAndTrim(ctx.GlobalString(RPCApiFlag.Name))
 	}
+	if ctx.GlobalIsSet(RPCVirtualHostsFlag.Name) {
+		cfg.HTTPVirtualHosts = splitAndTrim(ctx.GlobalString(RPCVirtualHostsFlag.Name))
+	}
+}
+
+// setGraphQL creates the GraphQL listener interface string from the set command
+// line flags, returning empty if the GraphQL endpoint is disabled.
+func setGraphQL(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalBool(GraphQLEnabledFlag.Name) && cfg.GraphQLHost == "" {
+		cfg.GraphQLHost = "127.0.0.1"
+		if ctx.GlobalIsSet(GraphQLListenAddrFlag.Name) {
+			cfg.GraphQLHost = ctx.GlobalString(GraphQLListenAddrFlag.Name)
+		}
+	}
+	if ctx.GlobalIsSet(GraphQLPortFlag.Name) {
+		cfg.GraphQLPort = ctx.GlobalInt(GraphQLPortFlag.Name)
+	}
+	if ctx.GlobalIsSet(GraphQLCORSDomainFlag.Name) {
+		cfg.GraphQLCors = splitAndTrim(ctx.GlobalString(GraphQLCORSDomainFlag.Name))
+	}
+	if ctx.GlobalIsSet(GraphQLVirtualHostsFlag.Name) {
+		cfg.GraphQLVirtualHosts = splitAndTrim(ctx.GlobalString(GraphQLVirtualHostsFlag.Name))
+	}
 }
 
 // setWS creates the WebSocket RPC listener interface string from the set
@@ -699,6 +845,70 @@ func setWS(ctx *cli.Context, cfg *node.Config) {
 	}
 }
 
+// setRPCLimits applies the request limits of the HTTP and WebSocket RPC servers
+// from the set command line flags.
+func setRPCLimits(ctx *cli.Context, cfg *node.Config) {
+	if ctx.GlobalIsSet(RPCBatchLimitFlag.Name) {
+		cfg.RPCBatchLimit = ctx.GlobalInt(RPCBatchLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCResponseLimitFlag.Name) {
+		cfg.RPCResponseLimit = ctx.GlobalInt(RPCResponseLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCCallTimeoutFlag.Name) {
+		cfg.RPCCallTimeout = ctx.GlobalDuration(RPCCallTimeoutFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRateLimitFlag.Name) {
+		cfg.RPCRateLimits.Rate = ctx.GlobalFloat64(RPCRateLimitFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCRateBurstFlag.Name) {
+		cfg.RPCRateLimits.Burst = ctx.GlobalInt(RPCRateBurstFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCMethodCostsFlag.Name) {
+		costs := make(map[string]int)
+		for name, cost := range cfg.RPCRateLimits.Costs {
+			costs[name] = cost
+		}
+		for _, entry := range splitAndTrim(ctx.GlobalString(RPCMethodCostsFlag.Name)) {
+			parts := strings.Split(entry, "=")
+			if len(parts) != 2 {
+				Fatalf("Option %q: invalid method cost %q, expected method=cost", RPCMethodCostsFlag.Name, entry)
+			}
+			cost, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+			if err != nil || cost < 0 {
+				Fatalf("Option %q: invalid cost of method %q: %s", RPCMethodCostsFlag.Name, parts[0], parts[1])
+			}
+			costs[strings.TrimSpace(parts[0])] = cost
+		}
+		cfg.RPCRateLimits.Costs = costs
+	}
+}
+
+// setRPCAuth loads the credentials required from HTTP and WebSocket RPC callers
+// from the files specified by the command line flags.
+func setRPCAuth(ctx *cli.Context, cfg *node.Config) {
+	if path := ctx.GlobalString(RPCAuthFileFlag.Name); path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			Fatalf("Option %q: %v", RPCAuthFileFlag.Name, err)
+		}
+		var creds []rpc.Credential
+		if err := json.Unmarshal(blob, &creds); err != nil {
+			Fatalf("Option %q: invalid credentials file: %v", RPCAuthFileFlag.Name, err)
+		}
+		cfg.RPCAuth = append(cfg.RPCAuth, creds...)
+	}
+	if path := ctx.GlobalString(RPCJWTSecretFlag.Name); path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			Fatalf("Option %q: %v", RPCJWTSecretFlag.Name, err)
+		}
+		cfg.RPCAuth = append(cfg.RPCAuth, rpc.Credential{
+			Name:      "jwt",
+			JWTSecret: strings.TrimSpace(string(blob)),
+		})
+	}
+}
+
 // setIPC creates an IPC path configuration from the set command line flags,
 // returning an empty string if IPC was explicitly disabled, or the set path.
 func setIPC(ctx *cli.Context, cfg *node.Config) {
@@ -832,6 +1042,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	setIPC(ctx, cfg)
 	setHTTP(ctx, cfg)
 	setWS(ctx, cfg)
+	setGraphQL(ctx, cfg)
+	setRPCLimits(ctx, cfg)
+	setRPCAuth(ctx, cfg)
 	setNodeUserIdent(ctx, cfg)
 
 	switch {
@@ -854,6 +1067,9 @@ func SetNodeConfig(ctx *cli.Context, cfg *node.Config) {
 	if ctx.GlobalIsSet(NoUSBFlag.Name) {
 		cfg.NoUSB = ctx.GlobalBool(NoUSBFlag.Name)
 	}
+	if ctx.GlobalIsSet(ExternalSignerFlag.Name) {
+		cfg.ExternalSigner = ctx.GlobalString(ExternalSignerFlag.Name)
+	}
 }
 
 func setGPO(ctx *cli.Context, cfg *gasprice.Config) {
@@ -896,6 +1112,19 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPriorityFlag.Name) {
+		cfg.Priority = cfg.Priority[:0]
+		for _, account := range strings.Split(ctx.GlobalString(TxPoolPriorityFlag.Name), ",") {
+			account = strings.TrimSpace(account)
+			if !common.IsHexAddress(account) {
+				Fatalf("Option %q: invalid account %q", TxPoolPriorityFlag.Name, account)
+			}
+			cfg.Priority = append(cfg.Priority, common.HexToAddress(account))
+		}
+	}
+	if ctx.GlobalIsSet(TxPoolPrioritySlotsFlag.Name) {
+		cfg.PrioritySlots = ctx.GlobalUint64(TxPoolPrioritySlotsFlag.Name)
+	}
 }
 
 func setVapash(ctx *cli.Context, cfg *vap.Config) {
@@ -1004,6 +1233,34 @@ func SetVapConfig(ctx *cli.Context, stack *node.Node, cfg *vap.Config) {
 	}
 	cfg.DatabaseHandles = makeDatabaseHandles()
 
+	if ctx.GlobalIsSet(FreezerThresholdFlag.Name) {
+		cfg.FreezerThreshold = makeFreezerThreshold(ctx)
+	}
+
+	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
+		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
+	}
+	cfg.NoPruning = ctx.GlobalString(GCModeFlag.Name) == "archive"
+
+	if ctx.GlobalIsSet(CacheGCFlag.Name) {
+		cfg.TrieCache = ctx.GlobalInt(CacheGCFlag.Name)
+	}
+	if ctx.GlobalIsSet(CacheGCIntervalFlag.Name) {
+		cfg.TrieBlockInterval = ctx.GlobalUint64(CacheGCIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(CacheGCRetentionFlag.Name) {
+		cfg.TriesInMemory = ctx.GlobalUint64(CacheGCRetentionFlag.Name)
+	}
+	if ctx.GlobalIsSet(SnapshotFlag.Name) {
+		cfg.Snapshot = ctx.GlobalBool(SnapshotFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLogsRangeFlag.Name) {
+		cfg.FilterMaxBlocks = ctx.GlobalUint64(RPCLogsRangeFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCLogsLimitFlag.Name) {
+		cfg.FilterMaxLogs = ctx.GlobalUint64(RPCLogsLimitFlag.Name)
+	}
+
 	if ctx.GlobalIsSet(MinerThreadsFlag.Name) {
 		cfg.MinerThreads = ctx.GlobalInt(MinerThreadsFlag.Name)
 	}
@@ -1126,6 +1383,28 @@ func RegisterVapStatsService(stack *node.Node, url string) {
 	}
 }
 
+// RegisterGraphQLService configures the GraphQL server on top of the Vapory
+// service (full or light) and adds it to the given node, capping the log queries
+// with the given limits.
+func RegisterGraphQLService(stack *node.Node, endpoint string, cors, vhosts []string, limits filters.Limits) {
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) {
+		// Try to construct the GraphQL service backed by a full node
+		var vapServ *vap.Vapory
+		if err := ctx.Service(&vapServ); err == nil {
+			return graphql.New(vapServ.ApiBackend, limits, endpoint, cors, vhosts)
+		}
+		// Try to construct the GraphQL service backed by a light node
+		var lesServ *les.LightVapory
+		if err := ctx.Service(&lesServ); err == nil {
+			return graphql.New(lesServ.ApiBackend, limits, endpoint, cors, vhosts)
+		}
+		// Well, this should not have happened, bail out
+		return nil, errors.New("no Vapory service")
+	}); err != nil {
+		Fatalf("Failed to register the GraphQL service: %v", err)
+	}
+}
+
 // SetupNetwork configures the system for either the main net or some test network.
 func SetupNetwork(ctx *cli.Context) {
 	// TODO(fjl): move target gas limit into config
@@ -1146,9 +1425,29 @@ func MakeChainDatabase(ctx *cli.Context, stack *node.Node) vapdb.Database {
 	if err != nil {
 		Fatalf("Could not open database: %v", err)
 	}
+	// Back the chain with the ancient store if freezing is enabled, or if any
+	// blocks were already frozen in the past
+	if dir := stack.ResolvePath(name); dir != "" && !ctx.GlobalBool(LightModeFlag.Name) {
+		ancient := filepath.Join(dir, "ancient")
+		if ctx.GlobalUint64(FreezerThresholdFlag.Name) > 0 || common.FileExist(ancient) {
+			if chainDb, err = core.NewDatabaseWithFreezer(chainDb, ancient); err != nil {
+				Fatalf("Could not open ancient database: %v", err)
+			}
+		}
+	}
 	return chainDb
 }
 
+// makeFreezerThreshold retrieves the freezer threshold from the command line,
+// rejecting values that would freeze blocks still within the reorg depth.
+func makeFreezerThreshold(ctx *cli.Context) uint64 {
+	threshold := ctx.GlobalUint64(FreezerThresholdFlag.Name)
+	if threshold > 0 && threshold < core.MinFreezerThreshold {
+		Fatalf("--%s must be 0 (disabled) or at least %d", FreezerThresholdFlag.Name, core.MinFreezerThreshold)
+	}
+	return threshold
+}
+
 func MakeGenesis(ctx *cli.Context) *core.Genesis {
 	var genesis *core.Genesis
 	switch {
@@ -1187,8 +1486,30 @@ func MakeChain(ctx *cli.Context, stack *node.Node) (chain *core.BlockChain, chai
 			})
 		}
 	}
+	if gcmode := ctx.GlobalString(GCModeFlag.Name); gcmode != "full" && gcmode != "archive" {
+		Fatalf("--%s must be either 'full' or 'archive'", GCModeFlag.Name)
+	}
+	cache := &core.CacheConfig{
+		Disabled:       ctx.GlobalString(GCModeFlag.Name) == "archive",
+		TrieNodeLimit:  vap.DefaultConfig.TrieCache,
+		TrieTimeLimit:  vap.DefaultConfig.TrieTimeout,
+		TrieBlockLimit: vap.DefaultConfig.TrieBlockInterval,
+		TriesInMemory:  vap.DefaultConfig.TriesInMemory,
+		Snapshot:       ctx.GlobalBool(SnapshotFlag.Name),
+
+		FreezerThreshold: makeFreezerThreshold(ctx),
+	}
+	if ctx.GlobalIsSet(CacheGCFlag.Name) {
+		cache.TrieNodeLimit = ctx.GlobalInt(CacheGCFlag.Name)
+	}
+	if ctx.GlobalIsSet(CacheGCIntervalFlag.Name) {
+		cache.TrieBlockLimit = ctx.GlobalUint64(CacheGCIntervalFlag.Name)
+	}
+	if ctx.GlobalIsSet(CacheGCRetentionFlag.Name) {
+		cache.TriesInMemory = ctx.GlobalUint64(CacheGCRetentionFlag.Name)
+	}
 	vmcfg := vm.Config{EnablePreimageRecording: ctx.GlobalBool(VMEnableDebugFlag.Name)}
-	chain, err = core.NewBlockChain(chainDb, config, engine, vmcfg)
+	chain, err = core.NewBlockChain(chainDb, cache, config, engine, vmcfg)
 	if err != nil {
 		Fatalf("Can't create BlockChain: %v", err)
 	}
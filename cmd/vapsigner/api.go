@@ -0,0 +1,171 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/vaporyco/go-vapory/accounts"
+	"github.com/vaporyco/go-vapory/accounts/keystore"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/rlp"
+)
+
+// errRequestDenied is returned if a request was rejected by the approver.
+var errRequestDenied = errors.New("request denied")
+
+// SignRequest is a request to sign either a hash or a transaction, pending the
+// approval of the user.
+type SignRequest struct {
+	Account     common.Address     `json:"account"`               // Account to sign with
+	Hash        hexutil.Bytes      `json:"hash,omitempty"`        // Hash to sign, if not a transaction
+	Transaction *types.Transaction `json:"transaction,omitempty"` // Transaction to sign, if not a hash
+	ChainID     *hexutil.Big       `json:"chainId,omitempty"`     // Chain the transaction is signed for (nil = homestead)
+}
+
+// Approver is the hook deciding whether the requests made to the signer are to be
+// served. It is consulted on every single request.
+type Approver interface {
+	// ApproveListing is called when the accounts of the signer are requested,
+	// returning the subset of them which may be disclosed to the client.
+	ApproveListing(addrs []common.Address) ([]common.Address, error)
+
+	// ApproveSigning is called when a hash or transaction is requested to be
+	// signed, returning the passphrase to unlock the account with if approved,
+	// or errRequestDenied otherwise.
+	ApproveSigning(req *SignRequest) (string, error)
+
+	// Signed is called with the outcome of signing a request approved through
+	// ApproveSigning. If it returns an error, the signature is withheld.
+	Signed(req *SignRequest, err error) error
+}
+
+// SignerAPI is the API exposed to clients in the account namespace, serving the
+// accounts of a local keystore subject to the approval of each request.
+type SignerAPI struct {
+	ks       *keystore.KeyStore
+	approver Approver
+}
+
+// NewSignerAPI creates a new signer API serving the accounts of a keystore.
+func NewSignerAPI(ks *keystore.KeyStore, approver Approver) *SignerAPI {
+	return &SignerAPI{ks: ks, approver: approver}
+}
+
+// Version returns the version of the signer.
+func (api *SignerAPI) Version() string {
+	return params.Version
+}
+
+// List returns the addresses of the accounts the approver allows disclosing.
+func (api *SignerAPI) List() ([]common.Address, error) {
+	var addrs []common.Address
+	for _, acc := range api.ks.Accounts() {
+		addrs = append(addrs, acc.Address)
+	}
+	approved, err := api.approver.ApproveListing(addrs)
+	if err != nil {
+		log.Info("Account listing denied", "err", err)
+		return nil, err
+	}
+	// Never disclose accounts which were not in the keystore to begin with
+	known := make(map[common.Address]bool)
+	for _, addr := range addrs {
+		known[addr] = true
+	}
+	res := make([]common.Address, 0, len(approved))
+	for _, addr := range approved {
+		if known[addr] {
+			res = append(res, addr)
+		}
+	}
+	return res, nil
+}
+
+// SignHash signs the given hash with the given account, if approved.
+func (api *SignerAPI) SignHash(addr common.Address, hash hexutil.Bytes) (hexutil.Bytes, error) {
+	req := &SignRequest{Account: addr, Hash: hash}
+	account, passphrase, err := api.approve(req)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := api.ks.SignHashWithPassphrase(account, passphrase, hash)
+	if err = api.finish(req, err); err != nil {
+		return nil, err
+	}
+	log.Info("Signed hash", "account", addr, "hash", hash)
+	return sig, nil
+}
+
+// SignTransaction signs the given RLP encoded transaction with the given account
+// if approved, returning the RLP encoded signed transaction. If the chain ID is
+// nil, the transaction is signed with homestead rules.
+func (api *SignerAPI) SignTransaction(addr common.Address, rawTx hexutil.Bytes, chainID *hexutil.Big) (hexutil.Bytes, error) {
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(rawTx, tx); err != nil {
+		return nil, err
+	}
+	req := &SignRequest{Account: addr, Transaction: tx, ChainID: chainID}
+	account, passphrase, err := api.approve(req)
+	if err != nil {
+		return nil, err
+	}
+	var raw []byte
+
+	signed, err := api.ks.SignTxWithPassphrase(account, passphrase, tx, (*big.Int)(chainID))
+	if err == nil {
+		raw, err = rlp.EncodeToBytes(signed)
+	}
+	if err = api.finish(req, err); err != nil {
+		return nil, err
+	}
+	log.Info("Signed transaction", "account", addr, "hash", signed.Hash())
+	return raw, nil
+}
+
+// approve ensures the requested account is known by the keystore and consults
+// the approver about the request, returning the account and the passphrase to
+// sign with.
+func (api *SignerAPI) approve(req *SignRequest) (accounts.Account, string, error) {
+	account, err := api.ks.Find(accounts.Account{Address: req.Account})
+	if err != nil {
+		return accounts.Account{}, "", err
+	}
+	passphrase, err := api.approver.ApproveSigning(req)
+	if err != nil {
+		log.Info("Signing request denied", "account", req.Account, "err", err)
+		return accounts.Account{}, "", err
+	}
+	return account, passphrase, nil
+}
+
+// finish reports the outcome of signing an approved request to the approver,
+// returning the signing error, or the approver's if it withholds the signature.
+func (api *SignerAPI) finish(req *SignRequest, err error) error {
+	if aerr := api.approver.Signed(req, err); aerr != nil {
+		log.Warn("Signature withheld", "account", req.Account, "err", aerr)
+		if err == nil {
+			return aerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,162 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/accounts/keystore"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/rlp"
+)
+
+// testApprover is an Approver with canned decisions, recording the requests.
+type testApprover struct {
+	listing    []common.Address // Accounts to disclose
+	approve    bool             // Whether to approve signing requests
+	passphrase string           // Passphrase to unlock the accounts with
+
+	requests []*SignRequest
+	outcomes []error
+}
+
+func (ui *testApprover) ApproveListing(addrs []common.Address) ([]common.Address, error) {
+	return ui.listing, nil
+}
+
+func (ui *testApprover) ApproveSigning(req *SignRequest) (string, error) {
+	ui.requests = append(ui.requests, req)
+	if !ui.approve {
+		return "", errRequestDenied
+	}
+	return ui.passphrase, nil
+}
+
+func (ui *testApprover) Signed(req *SignRequest, err error) error {
+	ui.outcomes = append(ui.outcomes, err)
+	return nil
+}
+
+// newTestSigner creates a signer API over a temporary keystore containing a single
+// account, returning the account and the keystore directory to clean up.
+func newTestSigner(t *testing.T, approver Approver) (*SignerAPI, common.Address, string) {
+	dir, err := ioutil.TempDir("", "vapsigner-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary keystore: %v", err)
+	}
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+
+	account, err := ks.NewAccount("password")
+	if err != nil {
+		t.Fatalf("failed to create account: %v", err)
+	}
+	return NewSignerAPI(ks, approver), account.Address, dir
+}
+
+// Tests that only the approved accounts are disclosed by the signer.
+func TestListing(t *testing.T) {
+	approver := new(testApprover)
+	api, addr, dir := newTestSigner(t, approver)
+	defer os.RemoveAll(dir)
+
+	approver.listing = []common.Address{addr, {0x01}}
+	if addrs, err := api.List(); err != nil || len(addrs) != 1 || addrs[0] != addr {
+		t.Errorf("approved listing mismatch: have %v (%v), want [%x]", addrs, err, addr)
+	}
+	approver.listing = nil
+	if addrs, err := api.List(); err != nil || len(addrs) != 0 {
+		t.Errorf("denied listing mismatch: have %v (%v), want none", addrs, err)
+	}
+}
+
+// Tests that transactions are only signed if approved, with the passphrase
+// provided by the approver.
+func TestTransactionSigning(t *testing.T) {
+	approver := new(testApprover)
+	api, addr, dir := newTestSigner(t, approver)
+	defer os.RemoveAll(dir)
+
+	tx := types.NewTransaction(1, common.Address{0x02}, big.NewInt(3), 21000, big.NewInt(4), []byte{0x05})
+	raw, _ := rlp.EncodeToBytes(tx)
+	chainID := (*hexutil.Big)(big.NewInt(7))
+
+	// Ensure denied and wrongly unlocked requests fail
+	if _, err := api.SignTransaction(addr, raw, chainID); err != errRequestDenied {
+		t.Fatalf("denied signing error mismatch: have %v, want %v", err, errRequestDenied)
+	}
+	approver.approve, approver.passphrase = true, "wrong"
+	if _, err := api.SignTransaction(addr, raw, chainID); err != keystore.ErrDecrypt {
+		t.Fatalf("bad passphrase error mismatch: have %v, want %v", err, keystore.ErrDecrypt)
+	}
+	if len(approver.requests) != 2 {
+		t.Fatalf("request count mismatch: have %d, want 2", len(approver.requests))
+	}
+	if req := approver.requests[0]; req.Account != addr || req.Transaction.Hash() != tx.Hash() || req.ChainID.ToInt().Cmp(big.NewInt(7)) != 0 {
+		t.Errorf("approval request mismatch: have %+v", req)
+	}
+	if len(approver.outcomes) != 1 || approver.outcomes[0] != keystore.ErrDecrypt {
+		t.Errorf("signing outcome mismatch: have %v, want [%v]", approver.outcomes, keystore.ErrDecrypt)
+	}
+	// Ensure approved requests are signed correctly
+	approver.passphrase = "password"
+
+	signed, err := api.SignTransaction(addr, raw, chainID)
+	if err != nil {
+		t.Fatalf("failed to sign transaction: %v", err)
+	}
+	res := new(types.Transaction)
+	if err := rlp.DecodeBytes(signed, res); err != nil {
+		t.Fatalf("failed to decode signed transaction: %v", err)
+	}
+	if sender, err := types.Sender(types.NewEIP155Signer(big.NewInt(7)), res); err != nil || sender != addr {
+		t.Errorf("sender mismatch: have %x (%v), want %x", sender, err, addr)
+	}
+	// Ensure unknown accounts are rejected without consulting the approver
+	if _, err := api.SignTransaction(common.Address{0x01}, raw, chainID); err == nil {
+		t.Errorf("signed with unknown account")
+	}
+	if len(approver.requests) != 3 {
+		t.Errorf("request count mismatch: have %d, want 3", len(approver.requests))
+	}
+}
+
+// Tests that hashes are only signed if approved.
+func TestHashSigning(t *testing.T) {
+	approver := &testApprover{passphrase: "password"}
+	api, addr, dir := newTestSigner(t, approver)
+	defer os.RemoveAll(dir)
+
+	hash := crypto.Keccak256([]byte("hello"))
+	if _, err := api.SignHash(addr, hash); err != errRequestDenied {
+		t.Fatalf("denied signing error mismatch: have %v, want %v", err, errRequestDenied)
+	}
+	approver.approve = true
+
+	sig, err := api.SignHash(addr, hash)
+	if err != nil {
+		t.Fatalf("failed to sign hash: %v", err)
+	}
+	if pub, err := crypto.SigToPub(hash, sig); err != nil || crypto.PubkeyToAddress(*pub) != addr {
+		t.Errorf("signer mismatch: have %v, want %x", err, addr)
+	}
+}
@@ -0,0 +1,245 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+// vapsigner is a standalone signer, serving the accounts of a keystore over RPC
+// to a gvap node running elsewhere, subject to the approval of every request.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/vaporyco/go-vapory/accounts/keystore"
+	"github.com/vaporyco/go-vapory/cmd/utils"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/console"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/node"
+	"github.com/vaporyco/go-vapory/rpc"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Git SHA1 commit hash of the release (set via linker flags)
+var gitCommit = ""
+
+var app *cli.App
+
+func init() {
+	app = utils.NewApp(gitCommit, "an external signer for Vapory accounts")
+	app.Flags = []cli.Flag{
+		keystoreFlag,
+		lightKDFFlag,
+		configDirFlag,
+		rulesFlag,
+		credentialsFlag,
+		auditLogFlag,
+		ipcDisabledFlag,
+		ipcPathFlag,
+		httpEnabledFlag,
+		httpListenAddrFlag,
+		httpPortFlag,
+		httpVirtualHostsFlag,
+		verbosityFlag,
+	}
+	app.Action = signer
+}
+
+// Command line flags of the signer.
+var (
+	keystoreFlag = utils.DirectoryFlag{
+		Name:  "keystore",
+		Usage: "Directory for the keystore",
+		Value: utils.DirectoryString{Value: filepath.Join(node.DefaultDataDir(), "keystore")},
+	}
+	lightKDFFlag = cli.BoolFlag{
+		Name:  "lightkdf",
+		Usage: "Reduce key-derivation RAM & CPU usage at some expense of KDF strength",
+	}
+	configDirFlag = utils.DirectoryFlag{
+		Name:  "configdir",
+		Usage: "Directory for the rule storage and the audit log",
+		Value: utils.DirectoryString{Value: filepath.Join(node.DefaultDataDir(), "vapsigner")},
+	}
+	rulesFlag = cli.StringFlag{
+		Name:  "rules",
+		Usage: "JavaScript file with the rules to automatically approve or reject requests with",
+	}
+	credentialsFlag = cli.StringFlag{
+		Name:  "credentials",
+		Usage: "JSON file mapping addresses to the passphrases to sign rule approved requests with",
+	}
+	auditLogFlag = cli.StringFlag{
+		Name:  "auditlog",
+		Usage: "File to append all the request decisions to (default = inside the configdir)",
+	}
+	ipcDisabledFlag = cli.BoolFlag{
+		Name:  "ipcdisable",
+		Usage: "Disable the IPC-RPC server",
+	}
+	ipcPathFlag = utils.DirectoryFlag{
+		Name:  "ipcpath",
+		Usage: "Filename for the IPC socket/pipe",
+		Value: utils.DirectoryString{Value: filepath.Join(node.DefaultDataDir(), "vapsigner.ipc")},
+	}
+	httpEnabledFlag = cli.BoolFlag{
+		Name:  "http",
+		Usage: "Enable the HTTP-RPC server",
+	}
+	httpListenAddrFlag = cli.StringFlag{
+		Name:  "httpaddr",
+		Usage: "HTTP-RPC server listening interface",
+		Value: "localhost",
+	}
+	httpPortFlag = cli.IntFlag{
+		Name:  "httpport",
+		Usage: "HTTP-RPC server listening port",
+		Value: 8550,
+	}
+	httpVirtualHostsFlag = cli.StringFlag{
+		Name:  "httpvhosts",
+		Usage: "Comma separated list of virtual hostnames from which to accept requests (server enforced). Accepts '*' wildcard.",
+		Value: "localhost",
+	}
+	verbosityFlag = cli.IntFlag{
+		Name:  "verbosity",
+		Usage: "Logging verbosity: 0=silent, 1=error, 2=warn, 3=info, 4=debug, 5=detail",
+		Value: int(log.LvlInfo),
+	}
+)
+
+func main() {
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// signer opens the keystore and serves its accounts over the requested RPC
+// endpoints until interrupted.
+func signer(ctx *cli.Context) error {
+	glogger := log.NewGlogHandler(log.StreamHandler(os.Stderr, log.TerminalFormat(false)))
+	glogger.Verbosity(log.Lvl(ctx.Int(verbosityFlag.Name)))
+	log.Root().SetHandler(glogger)
+
+	if ctx.Bool(ipcDisabledFlag.Name) && !ctx.Bool(httpEnabledFlag.Name) {
+		utils.Fatalf("No RPC endpoint enabled")
+	}
+	// Open the keystore and assemble the API serving it
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if ctx.Bool(lightKDFFlag.Name) {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+	ks := keystore.NewKeyStore(ctx.String(keystoreFlag.Name), scryptN, scryptP)
+
+	approver := makeApprover(ctx)
+	defer approver.Close()
+	defer approver.audit.Close()
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("account", NewSignerAPI(ks, approver)); err != nil {
+		utils.Fatalf("Failed to register signer API: %v", err)
+	}
+	// Start the requested RPC endpoints
+	if !ctx.Bool(ipcDisabledFlag.Name) {
+		endpoint := ctx.String(ipcPathFlag.Name)
+
+		listener, err := rpc.CreateIPCListener(endpoint)
+		if err != nil {
+			utils.Fatalf("Failed to open IPC endpoint: %v", err)
+		}
+		defer listener.Close()
+
+		go server.ServeListener(listener)
+		log.Info("IPC endpoint opened", "url", endpoint)
+	}
+	if ctx.Bool(httpEnabledFlag.Name) {
+		endpoint := fmt.Sprintf("%s:%d", ctx.String(httpListenAddrFlag.Name), ctx.Int(httpPortFlag.Name))
+
+		listener, err := net.Listen("tcp", endpoint)
+		if err != nil {
+			utils.Fatalf("Failed to open HTTP endpoint: %v", err)
+		}
+		defer listener.Close()
+
+		vhosts := strings.Split(ctx.String(httpVirtualHostsFlag.Name), ",")
+		go rpc.NewHTTPServer(nil, vhosts, server).Serve(listener)
+		log.Info("HTTP endpoint opened", "url", fmt.Sprintf("http://%s", endpoint))
+	}
+	// Serve the requests until interrupted
+	sigc := make(chan os.Signal, 1)
+	signal.Notify(sigc, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sigc)
+
+	<-sigc
+	log.Info("Got interrupt, shutting down...")
+	server.Stop()
+	return nil
+}
+
+// makeApprover assembles the approver deciding about the requests, evaluating the
+// rules if configured and asking the user on the terminal otherwise.
+func makeApprover(ctx *cli.Context) *rulesetApprover {
+	configdir := ctx.String(configDirFlag.Name)
+
+	auditpath := ctx.String(auditLogFlag.Name)
+	if auditpath == "" {
+		auditpath = filepath.Join(configdir, "audit.log")
+	}
+	audit, err := newAuditLog(auditpath)
+	if err != nil {
+		utils.Fatalf("Failed to open audit log: %v", err)
+	}
+	var (
+		rules       string
+		storage     *ruleStorage
+		credentials = make(map[common.Address]string)
+	)
+	if path := ctx.String(rulesFlag.Name); path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			utils.Fatalf("Failed to read rules: %v", err)
+		}
+		rules = string(blob)
+
+		// Each rules file gets its own storage, keyed by its name
+		if storage, err = newRuleStorage(filepath.Join(configdir, "storage", filepath.Base(path)+".json")); err != nil {
+			utils.Fatalf("Failed to open rule storage: %v", err)
+		}
+		log.Info("Loaded approval rules", "file", path)
+	}
+	if path := ctx.String(credentialsFlag.Name); path != "" {
+		blob, err := ioutil.ReadFile(path)
+		if err != nil {
+			utils.Fatalf("Failed to read credentials: %v", err)
+		}
+		if err := json.Unmarshal(blob, &credentials); err != nil {
+			utils.Fatalf("Failed to parse credentials: %v", err)
+		}
+	}
+	approver, err := newRulesetApprover(rules, storage, credentials, newTerminalApprover(console.Stdin), audit)
+	if err != nil {
+		utils.Fatalf("Failed to create rule engine: %v", err)
+	}
+	log.Info("Recording decisions", "auditlog", auditpath)
+	return approver
+}
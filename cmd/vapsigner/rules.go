@@ -0,0 +1,609 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/internal/jsre"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/robertkrimen/otto"
+)
+
+// ruleTimeout is the maximum time a rule may run before it is aborted and the
+// request it evaluates is rejected.
+const ruleTimeout = time.Second
+
+var (
+	// errRuleTimeout is returned if a rule did not finish within the allowed time.
+	errRuleTimeout = errors.New("rule evaluation timed out")
+
+	// errAuditFailed is returned if a request was denied because its approval
+	// could not be recorded in the audit log.
+	errAuditFailed = errors.New("failed to record decision in audit log")
+
+	// errUnknownApproval is returned if the outcome of signing is reported for a
+	// request which was not approved.
+	errUnknownApproval = errors.New("unknown approval")
+
+	// errStorageFailed is returned if a request was denied because the values
+	// stored by the rules while approving it could not be persisted.
+	errStorageFailed = errors.New("failed to persist rule storage")
+)
+
+// Decisions the rules may return for a request. Any other return value defers
+// the request to the fallback approver.
+const (
+	ruleApprove = "Approve"
+	ruleReject  = "Reject"
+)
+
+// Sources of the decisions recorded in the audit log.
+const (
+	sourceRules = "rules"
+	sourceUser  = "user"
+)
+
+// rulesetApprover is an Approver evaluating every request against operator
+// supplied JavaScript rules, deferring the undecided ones to a fallback approver.
+// All decisions are recorded in an append-only audit log.
+//
+// The rules may define any of the functions ApproveTx, ApproveHash and
+// ApproveListing, each called with the request as a plain object and expected
+// to return "Approve" or "Reject". A persistent storage is available to the
+// rules via storage.get(key) and storage.put(key, value). The values stored while
+// evaluating a request are only persisted once it was served, so a signing
+// failure does not count against e.g. a rate limit.
+type rulesetApprover struct {
+	jsre        *jsre.JSRE                // JavaScript runtime holding the rules (nil = no rules)
+	storage     *ruleStorage              // Persistent key-value storage of the rules
+	credentials map[common.Address]string // Passphrases to unlock the accounts with for approved requests
+	fallback    Approver                  // Approver consulted about undecided requests
+	audit       *auditLog                 // Append-only log of all the decisions
+
+	pending     map[*SignRequest]*approval // Approvals awaiting their signing outcome
+	pendingLock sync.Mutex                 // Lock protecting the pending approvals
+
+	lock sync.Mutex // Lock serializing the evaluation of the rules
+}
+
+// approval is a signing request approved by the rules or the fallback approver,
+// along with the values the rules stored while evaluating it.
+type approval struct {
+	source string     // Source of the decision recorded in the audit log
+	writes *ruleBatch // Rule storage writes to persist if signing succeeds
+}
+
+// newRulesetApprover creates an approver evaluating the rules of the given source.
+// If the source is empty, every request is deferred to the fallback approver.
+func newRulesetApprover(rules string, storage *ruleStorage, credentials map[common.Address]string, fallback Approver, audit *auditLog) (*rulesetApprover, error) {
+	approver := &rulesetApprover{
+		storage:     storage,
+		credentials: credentials,
+		fallback:    fallback,
+		audit:       audit,
+		pending:     make(map[*SignRequest]*approval),
+	}
+	if rules == "" {
+		return approver, nil
+	}
+	re := jsre.New("", ioutil.Discard)
+	if err := re.Compile("bignumber.js", jsre.BigNumber_JS); err != nil {
+		re.Stop(false)
+		return nil, fmt.Errorf("failed to load bignumber.js: %v", err)
+	}
+	var err error
+	re.Do(func(vm *otto.Otto) {
+		var obj *otto.Object
+		if obj, err = vm.Object("({})"); err != nil {
+			return
+		}
+		obj.Set("get", storage.get)
+		obj.Set("put", storage.put)
+		if err = vm.Set("storage", obj); err != nil {
+			return
+		}
+		if obj, err = vm.Object("({})"); err != nil {
+			return
+		}
+		obj.Set("log", consoleLog)
+		if err = vm.Set("console", obj); err != nil {
+			return
+		}
+		_, err = vm.Run(rules)
+	})
+	if err != nil {
+		re.Stop(false)
+		return nil, fmt.Errorf("failed to load rules: %v", err)
+	}
+	approver.jsre = re
+	return approver, nil
+}
+
+// Close terminates the JavaScript runtime of the rules.
+func (r *rulesetApprover) Close() {
+	if r.jsre != nil {
+		r.jsre.Stop(false)
+	}
+}
+
+// ApproveListing implements Approver, evaluating the ApproveListing rule. The
+// accounts are only disclosed if the decision was recorded in the audit log.
+func (r *rulesetApprover) ApproveListing(addrs []common.Address) ([]common.Address, error) {
+	req := map[string]interface{}{
+		"kind":     "listing",
+		"accounts": addrs,
+	}
+	r.lock.Lock()
+	decision, writes, err := r.evaluate("ApproveListing", req)
+	r.lock.Unlock()
+
+	switch {
+	case err != nil:
+		r.audit.record(req, false, sourceRules, err)
+		return nil, err
+
+	case decision == ruleApprove:
+		if err := r.audit.record(req, true, sourceRules, nil); err != nil {
+			r.storage.discard(writes)
+			return nil, errAuditFailed
+		}
+		if err := r.storage.commit(writes); err != nil {
+			return nil, errStorageFailed
+		}
+		return addrs, nil
+
+	case decision == ruleReject:
+		r.storage.discard(writes)
+		r.audit.record(req, false, sourceRules, nil)
+		return nil, errRequestDenied
+	}
+	approved, err := r.fallback.ApproveListing(addrs)
+	if aerr := r.audit.record(req, err == nil && len(approved) > 0, sourceUser, err); aerr != nil && err == nil {
+		r.storage.discard(writes)
+		return nil, errAuditFailed
+	}
+	if err != nil || len(approved) == 0 {
+		r.storage.discard(writes)
+	} else if err := r.storage.commit(writes); err != nil {
+		return nil, errStorageFailed
+	}
+	return approved, err
+}
+
+// ApproveSigning implements Approver, evaluating the ApproveTx or ApproveHash rule
+// depending on the request. Approved requests are unlocked with the configured
+// credentials, or deferred to the fallback approver if there are none.
+//
+// Denials are recorded in the audit log right away, approvals only once the
+// outcome of signing is reported via Signed.
+func (r *rulesetApprover) ApproveSigning(req *SignRequest) (string, error) {
+	input, rule := jsSignRequest(req)
+
+	r.lock.Lock()
+	decision, writes, err := r.evaluate(rule, input)
+	r.lock.Unlock()
+
+	switch {
+	case err != nil:
+		r.audit.record(input, false, sourceRules, err)
+		return "", err
+
+	case decision == ruleReject:
+		r.storage.discard(writes)
+		r.audit.record(input, false, sourceRules, nil)
+		return "", errRequestDenied
+
+	case decision == ruleApprove:
+		if passphrase, ok := r.credentials[req.Account]; ok {
+			r.track(req, &approval{source: sourceRules, writes: writes})
+			return passphrase, nil
+		}
+		log.Warn("No credentials for rule approved account", "account", req.Account)
+	}
+	// Undecided by the rules, ask the fallback without blocking other requests
+	passphrase, err := r.fallback.ApproveSigning(req)
+	if err != nil {
+		r.storage.discard(writes)
+		r.audit.record(input, false, sourceUser, err)
+		return "", err
+	}
+	r.track(req, &approval{source: sourceUser, writes: writes})
+	return passphrase, nil
+}
+
+// Signed implements Approver, persisting the values stored by the rules for a
+// successfully signed request and recording the outcome in the audit log. If
+// either fails, the signature must be withheld.
+func (r *rulesetApprover) Signed(req *SignRequest, err error) error {
+	r.pendingLock.Lock()
+	approved, ok := r.pending[req]
+	delete(r.pending, req)
+	r.pendingLock.Unlock()
+
+	if !ok {
+		return errUnknownApproval
+	}
+	input, _ := jsSignRequest(req)
+	if err != nil {
+		r.storage.discard(approved.writes)
+	} else if err := r.storage.commit(approved.writes); err != nil {
+		r.audit.record(input, false, approved.source, err)
+		return errStorageFailed
+	}
+	if err := r.audit.record(input, err == nil, approved.source, err); err != nil {
+		return errAuditFailed
+	}
+	return nil
+}
+
+// track remembers an approved signing request until the outcome of signing it
+// is reported.
+func (r *rulesetApprover) track(req *SignRequest, approved *approval) {
+	r.pendingLock.Lock()
+	defer r.pendingLock.Unlock()
+
+	r.pending[req] = approved
+}
+
+// evaluate calls the named rule with the given request, returning its decision
+// and the storage writes it made, which the caller must commit or discard. If
+// the rule is not defined, the decision is empty.
+func (r *rulesetApprover) evaluate(rule string, req interface{}) (decision string, writes *ruleBatch, err error) {
+	if r.jsre == nil {
+		return "", nil, nil
+	}
+	blob, err := json.Marshal(req)
+	if err != nil {
+		return "", nil, err
+	}
+	writes = r.storage.begin()
+	defer r.storage.end()
+
+	r.jsre.Do(func(vm *otto.Otto) {
+		if fn, _ := vm.Get(rule); !fn.IsFunction() {
+			return
+		}
+		// Abort the rule if it runs for too long
+		interrupt := make(chan func(), 1)
+		vm.Interrupt = interrupt
+
+		timer := time.AfterFunc(ruleTimeout, func() {
+			interrupt <- func() { panic(errRuleTimeout) }
+		})
+		defer func() {
+			timer.Stop()
+			vm.Interrupt = nil
+
+			if caught := recover(); caught != nil {
+				if caught != errRuleTimeout {
+					panic(caught)
+				}
+				err = errRuleTimeout
+			}
+		}()
+		var obj, res otto.Value
+		if obj, err = vm.Call("JSON.parse", nil, string(blob)); err != nil {
+			return
+		}
+		if res, err = vm.Call(rule, nil, obj); err != nil {
+			return
+		}
+		if res.IsString() {
+			decision, _ = res.ToString()
+		}
+	})
+	if err != nil {
+		log.Warn("Rule evaluation failed", "rule", rule, "err", err)
+		r.storage.discard(writes)
+		return "", nil, err
+	}
+	return decision, writes, nil
+}
+
+// jsSignRequest converts a signing request into the plain object passed to the
+// rules, returning the name of the rule to evaluate it with. Amounts are given as
+// decimal strings to be used with BigNumber.
+func jsSignRequest(req *SignRequest) (map[string]interface{}, string) {
+	if req.Transaction == nil {
+		return map[string]interface{}{
+			"kind": "hash",
+			"from": req.Account,
+			"hash": req.Hash,
+		}, "ApproveHash"
+	}
+	tx := req.Transaction
+	obj := map[string]interface{}{
+		"kind":     "transaction",
+		"from":     req.Account,
+		"to":       tx.To(),
+		"value":    tx.Value().String(),
+		"gas":      tx.Gas(),
+		"gasPrice": tx.GasPrice().String(),
+		"nonce":    tx.Nonce(),
+		"data":     hexutil.Bytes(tx.Data()),
+		"selector": nil,
+		"chainId":  nil,
+	}
+	if data := tx.Data(); len(data) >= 4 {
+		obj["selector"] = hexutil.Bytes(data[:4])
+	}
+	if req.ChainID != nil {
+		obj["chainId"] = req.ChainID.ToInt().String()
+	}
+	return obj, "ApproveTx"
+}
+
+// consoleLog prints the arguments of console.log calls made by the rules.
+func consoleLog(call otto.FunctionCall) otto.Value {
+	args := make([]string, len(call.ArgumentList))
+	for i, arg := range call.ArgumentList {
+		args[i] = arg.String()
+	}
+	log.Info("Rule output", "msg", strings.Join(args, " "))
+	return otto.UndefinedValue()
+}
+
+// ruleWrite is a value stored by a rule, numbered in the order of the writes.
+type ruleWrite struct {
+	key   string
+	value string
+	seq   uint64
+}
+
+// ruleBatch is the set of values stored while evaluating a single request.
+type ruleBatch struct {
+	writes []ruleWrite
+}
+
+// ruleStorage is a key-value store available to the rules, persisted to a file
+// so that state such as rate limits survives restarts.
+//
+// The values stored while evaluating a request are held back in a batch until
+// the request is served (committed) or denied (discarded). Pending batches are
+// visible to the rules meanwhile, so concurrent requests see each other's writes.
+type ruleStorage struct {
+	path string            // File the storage is persisted to
+	data map[string]string // Persisted contents of the storage
+
+	versions map[string]uint64   // Sequence numbers of the persisted values
+	pending  map[*ruleBatch]bool // Batches awaiting their commit or discard
+	current  *ruleBatch          // Batch of the running rule evaluation
+	seq      uint64              // Sequence number of the last write
+	lock     sync.Mutex          // Lock protecting the storage
+}
+
+// newRuleStorage opens the rule storage persisted at the given path, creating
+// an empty one if it doesn't exist yet.
+func newRuleStorage(path string) (*ruleStorage, error) {
+	storage := &ruleStorage{
+		path:     path,
+		data:     make(map[string]string),
+		versions: make(map[string]uint64),
+		pending:  make(map[*ruleBatch]bool),
+	}
+
+	blob, err := ioutil.ReadFile(path)
+	switch {
+	case os.IsNotExist(err):
+		return storage, nil
+	case err != nil:
+		return nil, err
+	}
+	if err := json.Unmarshal(blob, &storage.data); err != nil {
+		return nil, fmt.Errorf("corrupt rule storage %s: %v", path, err)
+	}
+	return storage, nil
+}
+
+// get implements storage.get(key), returning the latest value stored, pending or
+// persisted, or an empty string if none is stored.
+func (s *ruleStorage) get(call otto.FunctionCall) otto.Value {
+	key, err := call.Argument(0).ToString()
+	if err != nil {
+		panic(call.Otto.MakeTypeError(err.Error()))
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	value, seq := s.data[key], s.versions[key]
+	for batch := range s.pending {
+		for _, w := range batch.writes {
+			if w.key == key && w.seq > seq {
+				value, seq = w.value, w.seq
+			}
+		}
+	}
+	val, _ := otto.ToValue(value)
+	return val
+}
+
+// put implements storage.put(key, value), adding the value to the batch of the
+// running evaluation. Values stored while loading the rules are persisted right
+// away, a failure to do so throws an exception in the rule.
+func (s *ruleStorage) put(call otto.FunctionCall) otto.Value {
+	key, err := call.Argument(0).ToString()
+	if err != nil {
+		panic(call.Otto.MakeTypeError(err.Error()))
+	}
+	val, err := call.Argument(1).ToString()
+	if err != nil {
+		panic(call.Otto.MakeTypeError(err.Error()))
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.seq++
+	if s.current != nil {
+		s.current.writes = append(s.current.writes, ruleWrite{key: key, value: val, seq: s.seq})
+		return otto.UndefinedValue()
+	}
+	s.data[key], s.versions[key] = val, s.seq
+	if err := s.flush(); err != nil {
+		panic(call.Otto.MakeCustomError("StorageError", err.Error()))
+	}
+	return otto.UndefinedValue()
+}
+
+// begin starts a new batch collecting the values stored by a rule evaluation.
+func (s *ruleStorage) begin() *ruleBatch {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.current = new(ruleBatch)
+	s.pending[s.current] = true
+	return s.current
+}
+
+// end stops collecting values into the batch of the finished rule evaluation,
+// leaving it pending.
+func (s *ruleStorage) end() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.current = nil
+}
+
+// commit persists the values of a pending batch, skipping the ones overwritten
+// by a batch committed earlier.
+func (s *ruleStorage) commit(batch *ruleBatch) error {
+	if batch == nil {
+		return nil
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.pending, batch)
+
+	var changed bool
+	for _, w := range batch.writes {
+		if w.seq > s.versions[w.key] {
+			s.data[w.key], s.versions[w.key] = w.value, w.seq
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	if err := s.flush(); err != nil {
+		log.Error("Failed to persist rule storage", "err", err)
+		return err
+	}
+	return nil
+}
+
+// discard drops the values of a pending batch.
+func (s *ruleStorage) discard(batch *ruleBatch) {
+	if batch == nil {
+		return
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	delete(s.pending, batch)
+}
+
+// flush atomically writes the storage to disk.
+func (s *ruleStorage) flush() error {
+	blob, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	}
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, blob, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// auditEntry is a single decision recorded in the audit log.
+type auditEntry struct {
+	Time     time.Time   `json:"time"`
+	Request  interface{} `json:"request"`
+	Approved bool        `json:"approved"`
+	Source   string      `json:"source"`
+	Error    string      `json:"error,omitempty"`
+}
+
+// auditLog is an append-only file recording every decision of the signer, one
+// JSON object per line.
+type auditLog struct {
+	file *os.File
+	lock sync.Mutex
+}
+
+// newAuditLog opens the audit log at the given path for appending, creating it
+// if it doesn't exist yet.
+func newAuditLog(path string) (*auditLog, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &auditLog{file: file}, nil
+}
+
+// record appends a decision to the audit log, syncing it to disk. Failures are
+// logged and returned, as approvals which were not recorded must not be served.
+func (a *auditLog) record(req interface{}, approved bool, source string, err error) error {
+	entry := &auditEntry{
+		Time:     time.Now(),
+		Request:  req,
+		Approved: approved,
+		Source:   source,
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	blob, err := json.Marshal(entry)
+	if err != nil {
+		log.Error("Failed to encode audit entry", "err", err)
+		return err
+	}
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if _, err := a.file.Write(append(blob, '\n')); err != nil {
+		log.Error("Failed to write audit entry", "err", err)
+		return err
+	}
+	if err := a.file.Sync(); err != nil {
+		log.Error("Failed to sync audit log", "err", err)
+		return err
+	}
+	return nil
+}
+
+// Close closes the audit log file.
+func (a *auditLog) Close() error {
+	return a.file.Close()
+}
@@ -0,0 +1,289 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/types"
+)
+
+// testRules approves payouts from the hot wallet to whitelisted addresses under a
+// daily limit of 100 wei, rejects token transfers and defers everything else.
+const testRules = `
+var whitelist = ["0x0100000000000000000000000000000000000000"];
+
+function ApproveTx(req) {
+	if (req.selector == "0xa9059cbb") {
+		return "Reject";
+	}
+	if (req.to === null || whitelist.indexOf(req.to) < 0 || req.data != "0x") {
+		return;
+	}
+	var day = Math.floor(new Date().getTime() / 86400000);
+	var spent = new BigNumber(0);
+	if (storage.get("day") == day) {
+		spent = new BigNumber(storage.get("spent"));
+	}
+	spent = spent.plus(req.value);
+	if (spent.greaterThan(100)) {
+		return "Reject";
+	}
+	storage.put("day", day);
+	storage.put("spent", spent.toString(10));
+	return "Approve";
+}
+
+function ApproveHash(req) {
+	while (true) {}
+}
+`
+
+// newTestRules creates a ruleset approver over the test rules, with its storage
+// and audit log in the given directory.
+func newTestRules(t *testing.T, dir string, fallback Approver, hot common.Address) *rulesetApprover {
+	storage, err := newRuleStorage(filepath.Join(dir, "storage", "rules.js.json"))
+	if err != nil {
+		t.Fatalf("failed to open rule storage: %v", err)
+	}
+	audit, err := newAuditLog(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	approver, err := newRulesetApprover(testRules, storage, map[common.Address]string{hot: "password"}, fallback, audit)
+	if err != nil {
+		t.Fatalf("failed to load rules: %v", err)
+	}
+	return approver
+}
+
+// Tests that requests are decided by the rules, the undecided ones deferred to
+// the fallback approver, and that the rule storage persists across restarts.
+func TestRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vapsigner-rules-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		hot      = common.Address{0xff}
+		listed   = common.Address{0x01}
+		unlisted = common.Address{0x02}
+		fallback = &testApprover{approve: true, passphrase: "fallback"}
+	)
+	payout := func(to common.Address, value int64, data []byte) *SignRequest {
+		return &SignRequest{
+			Account:     hot,
+			Transaction: types.NewTransaction(0, to, big.NewInt(value), 21000, big.NewInt(1), data),
+		}
+	}
+	tests := []struct {
+		req        *SignRequest
+		passphrase string
+		err        error
+	}{
+		{payout(listed, 60, nil), "password", nil},                                      // Whitelisted, within limit
+		{payout(listed, 60, nil), "", errRequestDenied},                                 // Whitelisted, over limit
+		{payout(unlisted, 1, nil), "fallback", nil},                                     // Not whitelisted, deferred
+		{payout(listed, 1, []byte{0xa9, 0x05, 0x9c, 0xbb, 0x00}), "", errRequestDenied}, // Token transfer
+		{&SignRequest{Account: hot, Hash: make([]byte, 32)}, "", errRuleTimeout},        // Runaway rule
+	}
+	approver := newTestRules(t, dir, fallback, hot)
+	for i, tt := range tests {
+		passphrase, err := approver.ApproveSigning(tt.req)
+		if passphrase != tt.passphrase || err != tt.err {
+			t.Errorf("test %d: decision mismatch: have %q/%v, want %q/%v", i, passphrase, err, tt.passphrase, tt.err)
+		}
+		if err == nil {
+			if err := approver.Signed(tt.req, nil); err != nil {
+				t.Errorf("test %d: failed to report signing outcome: %v", i, err)
+			}
+		}
+	}
+	// Ensure an approved request failing to sign is not recorded as approved, and
+	// that its spending only counts until the failure is reported
+	failed := payout(listed, 30, nil)
+	if _, err := approver.ApproveSigning(failed); err != nil {
+		t.Fatalf("failed to approve request: %v", err)
+	}
+	if _, err := approver.ApproveSigning(payout(listed, 11, nil)); err != errRequestDenied {
+		t.Errorf("over limit payout with pending approval: have %v, want %v", err, errRequestDenied)
+	}
+	if err := approver.Signed(failed, errSigningFailed); err != nil {
+		t.Fatalf("failed to report signing outcome: %v", err)
+	}
+	approver.Close()
+	approver.audit.Close()
+
+	if len(fallback.requests) != 1 {
+		t.Errorf("fallback request count mismatch: have %d, want 1", len(fallback.requests))
+	}
+	// Ensure the spent amount of served requests is remembered by a restarted signer
+	approver = newTestRules(t, dir, fallback, hot)
+	defer approver.Close()
+	defer approver.audit.Close()
+
+	if _, err := approver.ApproveSigning(payout(listed, 41, nil)); err != errRequestDenied {
+		t.Errorf("over limit payout after restart: have %v, want %v", err, errRequestDenied)
+	}
+	within := payout(listed, 40, nil)
+	if passphrase, err := approver.ApproveSigning(within); passphrase != "password" || err != nil {
+		t.Errorf("within limit payout after restart: have %q/%v, want %q/nil", passphrase, err, "password")
+	}
+	if err := approver.Signed(within, nil); err != nil {
+		t.Errorf("failed to report signing outcome: %v", err)
+	}
+	// Ensure all decisions were appended to the audit log
+	file, err := os.Open(filepath.Join(dir, "audit.log"))
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	var entries []auditEntry
+	for scanner := bufio.NewScanner(file); scanner.Scan(); {
+		var entry auditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			t.Fatalf("failed to decode audit entry %d: %v", len(entries), err)
+		}
+		entries = append(entries, entry)
+	}
+	want := []struct {
+		approved bool
+		source   string
+	}{
+		{true, sourceRules}, {false, sourceRules}, {true, sourceUser}, {false, sourceRules}, {false, sourceRules}, {false, sourceRules},
+		{false, sourceRules}, {false, sourceRules}, {true, sourceRules},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("audit entry count mismatch: have %d, want %d", len(entries), len(want))
+	}
+	for i, w := range want {
+		if entries[i].Approved != w.approved || entries[i].Source != w.source {
+			t.Errorf("audit entry %d mismatch: have %v/%s, want %v/%s", i, entries[i].Approved, entries[i].Source, w.approved, w.source)
+		}
+	}
+	if entries[4].Error != errRuleTimeout.Error() {
+		t.Errorf("runaway rule audit error mismatch: have %q, want %q", entries[4].Error, errRuleTimeout)
+	}
+	if entries[6].Error != errSigningFailed.Error() {
+		t.Errorf("failed signing audit error mismatch: have %q, want %q", entries[6].Error, errSigningFailed)
+	}
+}
+
+// errSigningFailed is a canned signing failure reported to the approver.
+var errSigningFailed = errors.New("signing failed")
+
+// Tests that approvals are denied if they cannot be recorded in the audit log.
+func TestRulesAuditFailure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vapsigner-rules-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		hot      = common.Address{0xff}
+		fallback = &testApprover{listing: []common.Address{hot}}
+		req      = &SignRequest{
+			Account:     hot,
+			Transaction: types.NewTransaction(0, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil),
+		}
+	)
+	approver := newTestRules(t, dir, fallback, hot)
+	defer approver.Close()
+
+	approver.audit.Close()
+	if addrs, err := approver.ApproveListing([]common.Address{hot}); addrs != nil || err != errAuditFailed {
+		t.Errorf("unrecorded listing mismatch: have %v/%v, want nil/%v", addrs, err, errAuditFailed)
+	}
+	if _, err := approver.ApproveSigning(req); err != nil {
+		t.Fatalf("failed to approve request: %v", err)
+	}
+	if err := approver.Signed(req, nil); err != errAuditFailed {
+		t.Errorf("unrecorded signing mismatch: have %v, want %v", err, errAuditFailed)
+	}
+}
+
+// blockingApprover is an Approver deferring to a testApprover once released,
+// simulating a user who has not yet answered a prompt.
+type blockingApprover struct {
+	testApprover
+	release chan struct{}
+}
+
+func (ui *blockingApprover) ApproveSigning(req *SignRequest) (string, error) {
+	<-ui.release
+	return ui.testApprover.ApproveSigning(req)
+}
+
+// Tests that a request pending with the fallback approver does not block the
+// requests decided by the rules.
+func TestRulesPendingFallback(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vapsigner-rules-test")
+	if err != nil {
+		t.Fatalf("failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		hot      = common.Address{0xff}
+		fallback = &blockingApprover{release: make(chan struct{})}
+	)
+	payout := func(to common.Address) *SignRequest {
+		return &SignRequest{
+			Account:     hot,
+			Transaction: types.NewTransaction(0, to, big.NewInt(1), 21000, big.NewInt(1), nil),
+		}
+	}
+	approver := newTestRules(t, dir, fallback, hot)
+	defer approver.Close()
+	defer approver.audit.Close()
+
+	deferred := make(chan error, 1)
+	go func() {
+		_, err := approver.ApproveSigning(payout(common.Address{0x02}))
+		deferred <- err
+	}()
+	decided := make(chan error, 1)
+	go func() {
+		_, err := approver.ApproveSigning(payout(common.Address{0x01}))
+		decided <- err
+	}()
+	select {
+	case err := <-decided:
+		if err != nil {
+			t.Errorf("rule approved request failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Errorf("rule approved request blocked by pending prompt")
+	}
+	close(fallback.release)
+	if err := <-deferred; err != errRequestDenied {
+		t.Errorf("deferred request mismatch: have %v, want %v", err, errRequestDenied)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of go-vapory.
+//
+// go-vapory is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// go-vapory is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with go-vapory. If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/console"
+)
+
+// terminalApprover is an Approver asking the user on the terminal about every
+// signing request. Whether to disclose the accounts is asked only once, and the
+// decision is remembered for the lifetime of the signer.
+type terminalApprover struct {
+	prompter console.UserPrompter
+
+	listing  *bool      // Decision about disclosing the accounts (nil = not yet asked)
+	promptMu sync.Mutex // Lock serializing the prompts shown to the user
+}
+
+// newTerminalApprover creates an approver prompting the user through the given
+// prompter.
+func newTerminalApprover(prompter console.UserPrompter) *terminalApprover {
+	return &terminalApprover{prompter: prompter}
+}
+
+// ApproveListing implements Approver, asking the user whether the accounts may be
+// disclosed if not yet decided.
+func (ui *terminalApprover) ApproveListing(addrs []common.Address) ([]common.Address, error) {
+	ui.promptMu.Lock()
+	defer ui.promptMu.Unlock()
+
+	if ui.listing == nil {
+		fmt.Println("-------- Account listing request --------")
+		for _, addr := range addrs {
+			fmt.Printf("  %s\n", addr.Hex())
+		}
+		approved, err := ui.prompter.PromptConfirm("Disclose these accounts to the client for this session?")
+		if err != nil {
+			return nil, err
+		}
+		ui.listing = &approved
+	}
+	if !*ui.listing {
+		return nil, errRequestDenied
+	}
+	return addrs, nil
+}
+
+// ApproveSigning implements Approver, showing the details of the request to the
+// user and asking for the passphrase of the account if approved.
+func (ui *terminalApprover) ApproveSigning(req *SignRequest) (string, error) {
+	ui.promptMu.Lock()
+	defer ui.promptMu.Unlock()
+
+	if tx := req.Transaction; tx != nil {
+		fmt.Println("-------- Transaction signing request --------")
+		fmt.Printf("from:     %s\n", req.Account.Hex())
+		if to := tx.To(); to != nil {
+			fmt.Printf("to:       %s\n", to.Hex())
+		} else {
+			fmt.Printf("to:       <contract creation>\n")
+		}
+		fmt.Printf("value:    %v wei\n", tx.Value())
+		fmt.Printf("gas:      %d\n", tx.Gas())
+		fmt.Printf("gasprice: %v wei\n", tx.GasPrice())
+		fmt.Printf("nonce:    %d\n", tx.Nonce())
+		fmt.Printf("data:     %x\n", tx.Data())
+		if req.ChainID != nil {
+			fmt.Printf("chainid:  %v\n", req.ChainID.ToInt())
+		} else {
+			fmt.Printf("chainid:  <none, replayable>\n")
+		}
+	} else {
+		fmt.Println("-------- Hash signing request --------")
+		fmt.Printf("account:  %s\n", req.Account.Hex())
+		fmt.Printf("hash:     %x\n", []byte(req.Hash))
+	}
+	approved, err := ui.prompter.PromptConfirm("Approve the request?")
+	if err != nil {
+		return "", err
+	}
+	if !approved {
+		return "", errRequestDenied
+	}
+	return ui.prompter.PromptPassword("Passphrase: ")
+}
+
+// Signed implements Approver. The user was already shown the request, so there
+// is nothing left to do.
+func (ui *terminalApprover) Signed(req *SignRequest, err error) error {
+	return nil
+}
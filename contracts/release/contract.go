@@ -29,13 +29,14 @@ func DeployReleaseOracle(auth *bind.TransactOpts, backend bind.ContractBackend,
 	if err != nil {
 		return common.Address{}, nil, nil, err
 	}
-	return address, tx, &ReleaseOracle{ReleaseOracleCaller: ReleaseOracleCaller{contract: contract}, ReleaseOracleTransactor: ReleaseOracleTransactor{contract: contract}}, nil
+	return address, tx, &ReleaseOracle{ReleaseOracleCaller: ReleaseOracleCaller{contract: contract}, ReleaseOracleTransactor: ReleaseOracleTransactor{contract: contract}, ReleaseOracleFilterer: ReleaseOracleFilterer{contract: contract}}, nil
 }
 
 // ReleaseOracle is an auto generated Go binding around an Vapory contract.
 type ReleaseOracle struct {
 	ReleaseOracleCaller     // Read-only binding to the contract
 	ReleaseOracleTransactor // Write-only binding to the contract
+	ReleaseOracleFilterer   // Log filterer for contract events
 }
 
 // ReleaseOracleCaller is an auto generated read-only Go binding around an Vapory contract.
@@ -48,6 +49,11 @@ type ReleaseOracleTransactor struct {
 	contract *bind.BoundContract // Generic contract wrapper for the low level calls
 }
 
+// ReleaseOracleFilterer is an auto generated log filtering Go binding around an Vapory contract events.
+type ReleaseOracleFilterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
 // ReleaseOracleSession is an auto generated Go binding around an Vapory contract,
 // with pre-set call and transact options.
 type ReleaseOracleSession struct {
@@ -87,16 +93,16 @@ type ReleaseOracleTransactorRaw struct {
 
 // NewReleaseOracle creates a new instance of ReleaseOracle, bound to a specific deployed contract.
 func NewReleaseOracle(address common.Address, backend bind.ContractBackend) (*ReleaseOracle, error) {
-	contract, err := bindReleaseOracle(address, backend, backend)
+	contract, err := bindReleaseOracle(address, backend, backend, backend)
 	if err != nil {
 		return nil, err
 	}
-	return &ReleaseOracle{ReleaseOracleCaller: ReleaseOracleCaller{contract: contract}, ReleaseOracleTransactor: ReleaseOracleTransactor{contract: contract}}, nil
+	return &ReleaseOracle{ReleaseOracleCaller: ReleaseOracleCaller{contract: contract}, ReleaseOracleTransactor: ReleaseOracleTransactor{contract: contract}, ReleaseOracleFilterer: ReleaseOracleFilterer{contract: contract}}, nil
 }
 
 // NewReleaseOracleCaller creates a new read-only instance of ReleaseOracle, bound to a specific deployed contract.
 func NewReleaseOracleCaller(address common.Address, caller bind.ContractCaller) (*ReleaseOracleCaller, error) {
-	contract, err := bindReleaseOracle(address, caller, nil)
+	contract, err := bindReleaseOracle(address, caller, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -105,20 +111,29 @@ func NewReleaseOracleCaller(address common.Address, caller bind.ContractCaller)
 
 // NewReleaseOracleTransactor creates a new write-only instance of ReleaseOracle, bound to a specific deployed contract.
 func NewReleaseOracleTransactor(address common.Address, transactor bind.ContractTransactor) (*ReleaseOracleTransactor, error) {
-	contract, err := bindReleaseOracle(address, nil, transactor)
+	contract, err := bindReleaseOracle(address, nil, transactor, nil)
 	if err != nil {
 		return nil, err
 	}
 	return &ReleaseOracleTransactor{contract: contract}, nil
 }
 
+// NewReleaseOracleFilterer creates a new log filterer instance of ReleaseOracle, bound to a specific deployed contract.
+func NewReleaseOracleFilterer(address common.Address, filterer bind.ContractFilterer) (*ReleaseOracleFilterer, error) {
+	contract, err := bindReleaseOracle(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &ReleaseOracleFilterer{contract: contract}, nil
+}
+
 // bindReleaseOracle binds a generic wrapper to an already deployed contract.
-func bindReleaseOracle(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor) (*bind.BoundContract, error) {
+func bindReleaseOracle(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
 	parsed, err := abi.JSON(strings.NewReader(ReleaseOracleABI))
 	if err != nil {
 		return nil, err
 	}
-	return bind.NewBoundContract(address, parsed, caller, transactor), nil
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
 }
 
 // Call invokes the (constant) contract method with params as input values and
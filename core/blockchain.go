@@ -31,6 +31,7 @@ import (
 	"github.com/vaporyco/go-vapory/common/mclock"
 	"github.com/vaporyco/go-vapory/consensus"
 	"github.com/vaporyco/go-vapory/core/state"
+	"github.com/vaporyco/go-vapory/core/state/snapshot"
 	"github.com/vaporyco/go-vapory/core/types"
 	"github.com/vaporyco/go-vapory/core/vm"
 	"github.com/vaporyco/go-vapory/crypto"
@@ -42,6 +43,7 @@ import (
 	"github.com/vaporyco/go-vapory/rlp"
 	"github.com/vaporyco/go-vapory/trie"
 	"github.com/hashicorp/golang-lru"
+	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 )
 
 var (
@@ -56,11 +58,37 @@ const (
 	maxFutureBlocks     = 256
 	maxTimeFutureBlocks = 30
 	badBlockLimit       = 10
+	triesInMemory       = 128
+
+	// freezerRecheckInterval is the frequency to check the chain for blocks to
+	// migrate into the ancient store.
+	freezerRecheckInterval = time.Minute
+
+	// freezerBatchLimit is the maximum number of blocks to migrate into the
+	// ancient store in one go, bounding how long chain insertion is blocked.
+	freezerBatchLimit = 30000
+
+	// MinFreezerThreshold is the minimum number of recent blocks to keep in the
+	// key-value store, covering the reorg depth and the in-memory state window.
+	MinFreezerThreshold = triesInMemory
 
 	// BlockChainVersion ensures that an incompatible database forces a resync from scratch.
 	BlockChainVersion = 3
 )
 
+// CacheConfig contains the configuration values for the trie caching/pruning
+// that's resident in a blockchain.
+type CacheConfig struct {
+	Disabled       bool          // Whether to disable trie write caching (archive node)
+	TrieNodeLimit  int           // Memory limit (MB) at which to flush the current in-memory trie to disk
+	TrieTimeLimit  time.Duration // Time limit after which to flush the current in-memory trie to disk
+	TrieBlockLimit uint64        // Number of blocks after which to flush the current in-memory trie to disk (0 = disabled)
+	TriesInMemory  uint64        // Number of recent state tries to retain in memory before garbage collecting them
+	Snapshot       bool          // Whether to maintain a flat snapshot of the state for fast state reads
+
+	FreezerThreshold uint64 // Number of recent blocks to keep in the key-value store before freezing them (0 = disabled)
+}
+
 // BlockChain represents the canonical chain given a database with a genesis
 // block. The Blockchain manages chain imports, reverts, chain reorganisations.
 //
@@ -76,10 +104,15 @@ const (
 // included in the canonical one where as GetBlockByNumber always represents the
 // canonical chain.
 type BlockChain struct {
-	config *params.ChainConfig // chain & network configuration
+	config      *params.ChainConfig // chain & network configuration
+	cacheConfig *CacheConfig        // Cache configuration for pruning
+
+	chainDb   vapdb.Database // Low level persistent database to store final content in
+	triegc    *prque.Prque   // Priority queue mapping block numbers to tries to gc
+	gcproc    time.Duration  // Accumulates canonical block processing for trie dumping
+	lastWrite uint64         // Number of the last block whose state was flushed to disk
 
 	hc            *HeaderChain
-	chainDb       vapdb.Database
 	rmLogsFeed    event.Feed
 	chainFeed     event.Feed
 	chainSideFeed event.Feed
@@ -97,6 +130,7 @@ type BlockChain struct {
 	currentFastBlock *types.Block // Current head of the fast-sync chain (may be above the block chain!)
 
 	stateCache   state.Database // State database to reuse between imports (contains state cache)
+	snaps        *snapshot.Tree // Flat snapshot of the recent states for fast access (nil if disabled)
 	bodyCache    *lru.Cache     // Cache for the most recent block bodies
 	bodyRLPCache *lru.Cache     // Cache for the most recent block bodies in RLP encoded format
 	blockCache   *lru.Cache     // Cache for the most recent entire blocks
@@ -119,7 +153,27 @@ type BlockChain struct {
 // NewBlockChain returns a fully initialised block chain using information
 // available in the database. It initialises the default Vapory Validator and
 // Processor.
-func NewBlockChain(chainDb vapdb.Database, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config) (*BlockChain, error) {
+func NewBlockChain(chainDb vapdb.Database, cacheConfig *CacheConfig, config *params.ChainConfig, engine consensus.Engine, vmConfig vm.Config) (*BlockChain, error) {
+	if cacheConfig == nil {
+		cacheConfig = &CacheConfig{
+			TrieNodeLimit: 256,
+			TrieTimeLimit: 5 * time.Minute,
+			TriesInMemory: triesInMemory,
+		}
+	}
+	if cacheConfig.TriesInMemory == 0 {
+		cacheConfig.TriesInMemory = triesInMemory
+	}
+	if threshold := cacheConfig.FreezerThreshold; threshold > 0 {
+		min := uint64(MinFreezerThreshold)
+		if cacheConfig.TriesInMemory > min {
+			min = cacheConfig.TriesInMemory
+		}
+		if threshold < min {
+			log.Warn("Sanitizing freezer threshold", "provided", threshold, "updated", min)
+			cacheConfig.FreezerThreshold = min
+		}
+	}
 	bodyCache, _ := lru.New(bodyCacheLimit)
 	bodyRLPCache, _ := lru.New(bodyCacheLimit)
 	blockCache, _ := lru.New(blockCacheLimit)
@@ -128,7 +182,9 @@ func NewBlockChain(chainDb vapdb.Database, config *params.ChainConfig, engine co
 
 	bc := &BlockChain{
 		config:       config,
+		cacheConfig:  cacheConfig,
 		chainDb:      chainDb,
+		triegc:       prque.New(),
 		stateCache:   state.NewDatabase(chainDb),
 		quit:         make(chan struct{}),
 		bodyCache:    bodyCache,
@@ -154,6 +210,11 @@ func NewBlockChain(chainDb vapdb.Database, config *params.ChainConfig, engine co
 	if err := bc.loadLastState(); err != nil {
 		return nil, err
 	}
+	// Load the state snapshot, regenerating it in the background if needed
+	if cacheConfig.Snapshot {
+		bc.snaps = snapshot.New(chainDb, bc.stateCache.TrieDB(), bc.currentBlock.Root())
+		bc.stateCache = state.WithSnapshots(bc.stateCache, bc.snaps)
+	}
 	// Check the current state of the block hashes and make sure that we do not have any of the bad blocks in our chain
 	for hash := range BadHashes {
 		if header := bc.GetHeaderByHash(hash); header != nil {
@@ -169,6 +230,12 @@ func NewBlockChain(chainDb vapdb.Database, config *params.ChainConfig, engine co
 	}
 	// Take ownership of this particular state
 	go bc.update()
+
+	// Start migrating old chain segments if an ancient store is available
+	if _, ok := chainDb.(AncientStore); ok && cacheConfig.FreezerThreshold > 0 {
+		bc.wg.Add(1)
+		go bc.freezeLoop()
+	}
 	return bc, nil
 }
 
@@ -196,8 +263,10 @@ func (bc *BlockChain) loadLastState() error {
 	// Make sure the state associated with the block is available
 	if _, err := state.New(currentBlock.Root(), bc.stateCache); err != nil {
 		// Dangling block without a state associated, init from scratch
-		log.Warn("Head state missing, resetting chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
-		return bc.Reset()
+		log.Warn("Head state missing, repairing chain", "number", currentBlock.Number(), "hash", currentBlock.Hash())
+		if err := bc.repair(&currentBlock); err != nil {
+			return err
+		}
 	}
 	// Everything seems to be fine, set as the head block
 	bc.currentBlock = currentBlock
@@ -248,6 +317,13 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	bc.hc.SetHead(head, delFn)
 	currentHeader := bc.hc.CurrentHeader()
 
+	// Discard any frozen blocks above the new head from the ancient store
+	if store, ok := bc.chainDb.(AncientStore); ok {
+		if err := store.TruncateAncients(currentHeader.Number.Uint64() + 1); err != nil {
+			log.Crit("Failed to truncate ancient store", "err", err)
+		}
+	}
+
 	// Clear out any stale content from the caches
 	bc.bodyCache.Purge()
 	bc.bodyRLPCache.Purge()
@@ -275,6 +351,10 @@ func (bc *BlockChain) SetHead(head uint64) error {
 	if bc.currentFastBlock == nil {
 		bc.currentFastBlock = bc.genesisBlock
 	}
+	// The state snapshot can't be rewound, regenerate it from the new head
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(bc.currentBlock.Root())
+	}
 	if err := WriteHeadBlockHash(bc.chainDb, bc.currentBlock.Hash()); err != nil {
 		log.Crit("Failed to reset head full block", "err", err)
 	}
@@ -292,7 +372,7 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	if block == nil {
 		return fmt.Errorf("non existent block [%x…]", hash[:4])
 	}
-	if _, err := trie.NewSecure(block.Root(), bc.chainDb, 0); err != nil {
+	if _, err := trie.NewSecure(block.Root(), bc.stateCache.TrieDB(), 0); err != nil {
 		return err
 	}
 	// If all checks out, manually set the head block
@@ -300,6 +380,11 @@ func (bc *BlockChain) FastSyncCommitHead(hash common.Hash) error {
 	bc.currentBlock = block
 	bc.mu.Unlock()
 
+	// Regenerate the state snapshot from the newly synced state
+	if bc.snaps != nil {
+		bc.snaps.Rebuild(block.Root())
+	}
+
 	log.Info("Committed new head block", "number", block.Number(), "hash", hash)
 	return nil
 }
@@ -385,6 +470,28 @@ func (bc *BlockChain) StateAt(root common.Hash) (*state.StateDB, error) {
 	return state.New(root, bc.stateCache)
 }
 
+// repair tries to repair the current blockchain by rolling back the current block
+// until one with associated state is found. This is needed to fix incomplete db
+// writes caused either by crashes/power outages, or simply non-committed tries.
+//
+// This method only rolls back the current block. The current header and current
+// fast block are left intact.
+func (bc *BlockChain) repair(head **types.Block) error {
+	for {
+		// Abort if we've rewound to a head block that does have associated state
+		if _, err := state.New((*head).Root(), bc.stateCache); err == nil {
+			log.Info("Rewound blockchain to past state", "number", (*head).Number(), "hash", (*head).Hash())
+			return nil
+		}
+		// Otherwise rewind one block and recheck state availability there
+		parent := bc.GetBlock((*head).ParentHash(), (*head).NumberU64()-1)
+		if parent == nil {
+			return fmt.Errorf("missing block %d [%x…]", (*head).NumberU64()-1, (*head).ParentHash().Bytes()[:4])
+		}
+		*head = parent
+	}
+}
+
 // Reset purges the entire blockchain, restoring it to its genesis state.
 func (bc *BlockChain) Reset() error {
 	return bc.ResetWithGenesisBlock(bc.genesisBlock)
@@ -520,6 +627,9 @@ func (bc *BlockChain) HasBlock(hash common.Hash, number uint64) bool {
 		return true
 	}
 	ok, _ := bc.chainDb.Has(blockBodyKey(hash, number))
+	if !ok {
+		ok = hasAncient(bc.chainDb, hash, number)
+	}
 	return ok
 }
 
@@ -532,7 +642,12 @@ func (bc *BlockChain) HasBlockAndState(hash common.Hash) bool {
 		return false
 	}
 	// Ensure the associated state is also present
-	_, err := bc.stateCache.OpenTrie(block.Root())
+	return bc.HasState(block.Root())
+}
+
+// HasState checks if state trie is fully present in the database or not.
+func (bc *BlockChain) HasState(hash common.Hash) bool {
+	_, err := bc.stateCache.OpenTrie(hash)
 	return err == nil
 }
 
@@ -606,6 +721,41 @@ func (bc *BlockChain) Stop() {
 	atomic.StoreInt32(&bc.procInterrupt, 1)
 
 	bc.wg.Wait()
+
+	// Ensure the state of a recent block is also stored to disk before exiting.
+	// We're writing three different states to catch different restart scenarios:
+	//  - HEAD:     So we don't need to reprocess any blocks in the general case
+	//  - HEAD-1:   So we don't do large reorgs if our HEAD becomes an uncle
+	//  - HEAD-N-1: So we have a hard limit on the number of blocks reexecuted
+	if !bc.cacheConfig.Disabled {
+		triedb := bc.stateCache.TrieDB()
+
+		for _, offset := range []uint64{0, 1, bc.cacheConfig.TriesInMemory - 1} {
+			if number := bc.CurrentBlock().NumberU64(); number > offset {
+				recent := bc.GetBlockByNumber(number - offset)
+				if recent == nil {
+					continue
+				}
+				log.Info("Writing cached state to disk", "block", recent.Number(), "hash", recent.Hash(), "root", recent.Root())
+				if err := triedb.Commit(recent.Root(), true); err != nil {
+					log.Error("Failed to commit recent state trie", "err", err)
+				}
+			}
+		}
+		for !bc.triegc.Empty() {
+			triedb.Dereference(bc.triegc.PopItem().(common.Hash), common.Hash{})
+		}
+		if size := triedb.Size(); size != 0 {
+			log.Error("Dangling trie nodes after full cleanup")
+		}
+	}
+	// Persist the state snapshot of the head, which was just flushed to disk
+	if bc.snaps != nil {
+		if err := bc.snaps.Cap(bc.CurrentBlock().Root(), 0); err != nil {
+			log.Error("Failed to persist state snapshot", "err", err)
+		}
+		bc.snaps.Close()
+	}
 	log.Info("Blockchain manager stopped")
 }
 
@@ -749,7 +899,7 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 				return 0, err
 			}
 			bytes += batch.ValueSize()
-			batch = bc.chainDb.NewBatch()
+			batch.Reset()
 		}
 	}
 	if batch.ValueSize() > 0 {
@@ -782,7 +932,23 @@ func (bc *BlockChain) InsertReceiptChain(blockChain types.Blocks, receiptChain [
 	return 0, nil
 }
 
-// WriteBlock writes the block to the chain.
+// WriteBlockWithoutState writes only the block and its metadata to the database,
+// but does not write any state. This is used to construct competing side forks
+// up to the point where they exceed the canonical total difficulty.
+func (bc *BlockChain) WriteBlockWithoutState(block *types.Block, td *big.Int) (err error) {
+	bc.wg.Add(1)
+	defer bc.wg.Done()
+
+	if err := bc.hc.WriteTd(block.Hash(), block.NumberU64(), td); err != nil {
+		return err
+	}
+	if err := WriteBlock(bc.chainDb, block); err != nil {
+		return err
+	}
+	return nil
+}
+
+// WriteBlockAndState writes the block and all associated state to the database.
 func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.Receipt, state *state.StateDB) (status WriteStatus, err error) {
 	bc.wg.Add(1)
 	defer bc.wg.Done()
@@ -808,9 +974,71 @@ func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.R
 	if err := WriteBlock(batch, block); err != nil {
 		return NonStatTy, err
 	}
-	if _, err := state.CommitTo(batch, bc.config.IsEIP158(block.Number())); err != nil {
+	root, err := state.Commit(bc.config.IsEIP158(block.Number()))
+	if err != nil {
 		return NonStatTy, err
 	}
+	triedb := bc.stateCache.TrieDB()
+
+	// If we're running an archive node, always flush
+	if bc.cacheConfig.Disabled {
+		if err := triedb.Commit(root, false); err != nil {
+			return NonStatTy, err
+		}
+	} else {
+		// Full but not archive node, do proper garbage collection
+		triedb.Reference(root, common.Hash{}) // metadata reference to keep trie alive
+		bc.triegc.Push(root, -float32(block.NumberU64()))
+
+		if current := block.NumberU64(); current > bc.cacheConfig.TriesInMemory {
+			// Find the next state trie we need to commit
+			header := bc.GetHeaderByNumber(current - bc.cacheConfig.TriesInMemory)
+			chosen := header.Number.Uint64()
+
+			// Only write to disk if we exceeded our memory allowance *and* also have at
+			// least a given number of tries gapped, or if the flush interval elapsed.
+			var (
+				size     = triedb.Size()
+				limit    = common.StorageSize(bc.cacheConfig.TrieNodeLimit) * 1024 * 1024
+				interval = bc.cacheConfig.TrieBlockLimit > 0 && chosen >= bc.lastWrite+bc.cacheConfig.TrieBlockLimit
+			)
+			if interval {
+				if err := triedb.Commit(header.Root, true); err != nil {
+					return NonStatTy, err
+				}
+				bc.lastWrite = chosen
+				bc.gcproc = 0
+			} else if size > limit || bc.gcproc > bc.cacheConfig.TrieTimeLimit {
+				// If we're exceeding limits but haven't reached a large enough memory gap,
+				// warn the user that the system is becoming unstable.
+				if chosen < bc.lastWrite+bc.cacheConfig.TriesInMemory {
+					switch {
+					case size >= 2*limit:
+						log.Warn("State memory usage too high, committing", "size", size, "limit", limit, "optimum", float64(chosen-bc.lastWrite)/float64(bc.cacheConfig.TriesInMemory))
+					case bc.gcproc >= 2*bc.cacheConfig.TrieTimeLimit:
+						log.Info("State in memory for too long, committing", "time", bc.gcproc, "allowance", bc.cacheConfig.TrieTimeLimit, "optimum", float64(chosen-bc.lastWrite)/float64(bc.cacheConfig.TriesInMemory))
+					}
+				}
+				// If optimum or critical limits reached, write to disk
+				if chosen >= bc.lastWrite+bc.cacheConfig.TriesInMemory || size >= 2*limit || bc.gcproc >= 2*bc.cacheConfig.TrieTimeLimit {
+					if err := triedb.Commit(header.Root, true); err != nil {
+						return NonStatTy, err
+					}
+					bc.lastWrite = chosen
+					bc.gcproc = 0
+				}
+			}
+			// Garbage collect anything below our required write retention
+			for !bc.triegc.Empty() {
+				root, number := bc.triegc.Pop()
+				if uint64(-number) > chosen {
+					bc.triegc.Push(root, number)
+					break
+				}
+				triedb.Dereference(root.(common.Hash), common.Hash{})
+			}
+		}
+	}
 	if err := WriteBlockReceipts(batch, block.Hash(), block.NumberU64(), receipts); err != nil {
 		return NonStatTy, err
 	}
@@ -846,9 +1074,18 @@ func (bc *BlockChain) WriteBlockAndState(block *types.Block, receipts []*types.R
 		return NonStatTy, err
 	}
 
-	// Set new head.
+	// Set new head, flattening the state snapshot layers beyond the retained tries
 	if status == CanonStatTy {
 		bc.insert(block)
+
+		if bc.snaps != nil {
+			if bc.snaps.Snapshot(block.Root()) == nil {
+				log.Warn("State snapshot missing for head, regenerating", "number", block.Number(), "root", block.Root())
+				bc.snaps.Rebuild(block.Root())
+			} else if err := bc.snaps.Cap(block.Root(), int(bc.cacheConfig.TriesInMemory)-1); err != nil {
+				log.Warn("Failed to cap snapshot tree", "root", block.Root(), "err", err)
+			}
+		}
 	}
 	bc.futureBlocks.Remove(block.Hash())
 	return status, nil
@@ -952,8 +1189,44 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 				continue
 			}
 
-			bc.reportBlock(block, nil, err)
-			return i, events, coalescedLogs, err
+			if err == consensus.ErrPrunedAncestor {
+				// Block competing with the canonical chain, store in the db, but don't process
+				// until the competitor TD goes above the canonical TD
+				localTd := bc.GetTd(bc.currentBlock.Hash(), bc.currentBlock.NumberU64())
+				externTd := new(big.Int).Add(bc.GetTd(block.ParentHash(), block.NumberU64()-1), block.Difficulty())
+				if localTd.Cmp(externTd) > 0 {
+					if err = bc.WriteBlockWithoutState(block, externTd); err != nil {
+						return i, events, coalescedLogs, err
+					}
+					continue
+				}
+				// Competitor chain beat canonical, gather all blocks from the common ancestor
+				var winner []*types.Block
+
+				parent := bc.GetBlock(block.ParentHash(), block.NumberU64()-1)
+				for !bc.HasState(parent.Root()) {
+					winner = append(winner, parent)
+					parent = bc.GetBlock(parent.ParentHash(), parent.NumberU64()-1)
+				}
+				for j := 0; j < len(winner)/2; j++ {
+					winner[j], winner[len(winner)-1-j] = winner[len(winner)-1-j], winner[j]
+				}
+				// Import all the pruned blocks to make the state available
+				bc.chainmu.Unlock()
+				_, evs, logs, err := bc.insertChain(winner)
+				bc.chainmu.Lock()
+				events, coalescedLogs = append(events, evs...), append(coalescedLogs, logs...)
+
+				if err != nil {
+					return i, events, coalescedLogs, err
+				}
+				// State of the parent is available now, fall through to block processing
+				err = nil
+			}
+			if err != nil {
+				bc.reportBlock(block, nil, err)
+				return i, events, coalescedLogs, err
+			}
 		}
 		// Create a new statedb using the parent block and report an
 		// error if it fails.
@@ -979,6 +1252,8 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 			bc.reportBlock(block, receipts, err)
 			return i, events, coalescedLogs, err
 		}
+		proctime := time.Since(bstart)
+
 		// Write the block to the chain and get the status.
 		status, err := bc.WriteBlockAndState(block, receipts, state)
 		if err != nil {
@@ -994,6 +1269,9 @@ func (bc *BlockChain) insertChain(chain types.Blocks) (int, []interface{}, []*ty
 			events = append(events, ChainEvent{block, block.Hash(), logs})
 			lastCanon = block
 
+			// Only count canonical blocks for GC processing time
+			bc.gcproc += proctime
+
 		case SideStatTy:
 			log.Debug("Inserted forked block", "number", block.Number(), "hash", block.Hash(), "diff", block.Difficulty(), "elapsed",
 				common.PrettyDuration(time.Since(bstart)), "txs", len(block.Transactions()), "gas", block.GasUsed(), "uncles", len(block.Uncles()))
@@ -1207,6 +1485,158 @@ func (bc *BlockChain) update() {
 	}
 }
 
+// freezeLoop periodically migrates the canonical chain segment older than the
+// configured threshold from the key-value store into the ancient store.
+func (bc *BlockChain) freezeLoop() {
+	defer bc.wg.Done()
+
+	ticker := time.NewTicker(freezerRecheckInterval)
+	defer ticker.Stop()
+	for {
+		for {
+			frozen, err := bc.freeze()
+			if err != nil {
+				log.Error("Failed to freeze ancient blocks", "err", err)
+			}
+			if err != nil || frozen < freezerBatchLimit {
+				break
+			}
+			// A full batch was migrated, continue unless shutting down
+			select {
+			case <-bc.quit:
+				return
+			default:
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-bc.quit:
+			return
+		}
+	}
+}
+
+// freeze moves a batch of canonical blocks older than the freezer threshold into
+// the ancient store and deletes them from the key-value store, returning the
+// number of blocks migrated. The genesis block and the hash to number mappings
+// are retained in the key-value store, side chain blocks at the frozen heights
+// are deleted altogether.
+//
+// The blocks are migrated without holding the chain lock, which is only taken to
+// ensure they are still canonical before deleting them.
+func (bc *BlockChain) freeze() (int, error) {
+	store := bc.chainDb.(AncientStore)
+
+	// Calculate the range of blocks to migrate
+	bc.mu.RLock()
+	head := bc.currentBlock.NumberU64()
+	if fast := bc.currentFastBlock.NumberU64(); fast > head {
+		head = fast
+	}
+	bc.mu.RUnlock()
+
+	if head <= bc.cacheConfig.FreezerThreshold {
+		return 0, nil
+	}
+	frozen, limit := store.Ancients(), head-bc.cacheConfig.FreezerThreshold
+	if limit > frozen+freezerBatchLimit {
+		limit = frozen + freezerBatchLimit
+	}
+	// Append all the data of the blocks to the ancient store
+	var (
+		start  = time.Now()
+		hashes []common.Hash
+	)
+	for number := frozen; number < limit; number++ {
+		hash := GetCanonicalHash(bc.chainDb, number)
+		if hash == (common.Hash{}) {
+			break
+		}
+		header := GetHeaderRLP(bc.chainDb, hash, number)
+		body := GetBodyRLP(bc.chainDb, hash, number)
+		receipts, _ := bc.chainDb.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash.Bytes()...))
+		td, _ := bc.chainDb.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash.Bytes()...), tdSuffix...))
+		if len(header) == 0 || len(body) == 0 || len(receipts) == 0 || len(td) == 0 {
+			break // Block not fully available yet (e.g. fast sync in progress)
+		}
+		if err := store.AppendAncient(number, hash.Bytes(), header, body, receipts, td); err != nil {
+			return 0, err
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+	if err := store.Sync(); err != nil {
+		return 0, err
+	}
+	bc.mu.Lock()
+	defer bc.mu.Unlock()
+
+	// Drop any frozen blocks rewound or reorged meanwhile from the ancient store
+	if ancients := store.Ancients(); ancients < frozen+uint64(len(hashes)) {
+		if ancients < frozen {
+			ancients = frozen
+		}
+		hashes = hashes[:ancients-frozen]
+	}
+	for i, hash := range hashes {
+		number := frozen + uint64(i)
+		if canon, _ := bc.chainDb.Get(append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...)); common.BytesToHash(canon) != hash {
+			if err := store.TruncateAncients(number); err != nil {
+				return 0, err
+			}
+			hashes = hashes[:i]
+			break
+		}
+	}
+	if len(hashes) == 0 {
+		return 0, nil
+	}
+	// Wipe the migrated blocks and the side chains at their heights from the
+	// key-value store in a single atomic batch
+	batch := bc.chainDb.NewBatch()
+	for i, hash := range hashes {
+		number := frozen + uint64(i)
+		if number == 0 {
+			continue
+		}
+		batch.Delete(headerKey(hash, number))
+		DeleteBody(batch, hash, number)
+		DeleteBlockReceipts(batch, hash, number)
+		DeleteTd(batch, hash, number)
+		DeleteCanonicalHash(batch, number)
+
+		for _, side := range bc.sideHashes(number, hash) {
+			DeleteBlock(batch, side, number)
+		}
+	}
+	if err := batch.Write(); err != nil {
+		return 0, err
+	}
+	log.Info("Moved blocks into ancient store", "count", len(hashes), "number", frozen+uint64(len(hashes))-1, "hash", hashes[len(hashes)-1], "elapsed", common.PrettyDuration(time.Since(start)))
+	return len(hashes), nil
+}
+
+// sideHashes returns the hashes of all the headers stored in the key-value store
+// at the given height, other than the canonical one.
+func (bc *BlockChain) sideHashes(number uint64, canon common.Hash) []common.Hash {
+	prefix := append(append([]byte{}, headerPrefix...), encodeBlockNumber(number)...)
+
+	it := bc.chainDb.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	var hashes []common.Hash
+	for it.Next() {
+		if key := it.Key(); len(key) == len(prefix)+common.HashLength {
+			if hash := common.BytesToHash(key[len(prefix):]); hash != canon {
+				hashes = append(hashes, hash)
+			}
+		}
+	}
+	return hashes
+}
+
 // BadBlockArgs represents the entries in the list returned when bad blocks are queried.
 type BadBlockArgs struct {
 	Hash   common.Hash   `json:"hash"`
@@ -0,0 +1,104 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/consensus/vapash"
+	"github.com/vaporyco/go-vapory/core/state/snapshot"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/core/vm"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// Tests that a chain maintaining a state snapshot processes blocks correctly,
+// and that the snapshot is persisted for the head state on shutdown.
+func TestBlockChainSnapshot(t *testing.T) {
+	var (
+		key, _   = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address  = crypto.PubkeyToAddress(key.PublicKey)
+		contract = common.Address{0xaa}
+		gspec    = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc: GenesisAlloc{
+				address:  {Balance: big.NewInt(1000000000)},
+				contract: {Balance: big.NewInt(0), Code: common.FromHex("0x43600155")}, // sstore(1, number)
+			},
+		}
+		signer = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	gendb, _ := vapdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+	blocks, _ := GenerateChain(gspec.Config, genesis, vapash.NewFaker(), gendb, 16, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), contract, big.NewInt(1000), 100000, nil, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	db, _ := vapdb.NewMemDatabase()
+	gspec.MustCommit(db)
+
+	config := &CacheConfig{TrieNodeLimit: 256, TrieTimeLimit: 5 * time.Minute, TriesInMemory: 4, Snapshot: true}
+	chain, err := NewBlockChain(db, config, gspec.Config, vapash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	head := blocks[len(blocks)-1]
+	if chain.snaps.Snapshot(head.Root()) == nil {
+		t.Fatalf("snapshot missing for head state")
+	}
+	statedb, err := chain.State()
+	if err != nil {
+		t.Fatalf("failed to retrieve head state: %v", err)
+	}
+	if have, want := statedb.GetState(contract, common.BigToHash(big.NewInt(1))), common.BigToHash(head.Number()); have != want {
+		t.Errorf("contract storage mismatch: have %x, want %x", have, want)
+	}
+	// Stop the chain and ensure the head snapshot was flushed to disk
+	chain.Stop()
+
+	root, _ := db.Get(snapshot.RootKey)
+	if common.BytesToHash(root) != head.Root() {
+		t.Fatalf("persisted snapshot root mismatch: have %x, want %x", root, head.Root())
+	}
+	// Reopen the chain and ensure the persisted snapshot is picked up
+	chain, err = NewBlockChain(db, config, gspec.Config, vapash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to reopen tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if chain.snaps.Snapshot(head.Root()) == nil {
+		t.Fatalf("snapshot missing for head state after restart")
+	}
+	if statedb, err = chain.State(); err != nil {
+		t.Fatalf("failed to retrieve head state: %v", err)
+	}
+	if have, want := statedb.GetState(contract, common.BigToHash(big.NewInt(1))), common.BigToHash(head.Number()); have != want {
+		t.Errorf("contract storage mismatch after restart: have %x, want %x", have, want)
+	}
+}
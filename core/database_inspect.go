@@ -0,0 +1,236 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/state/snapshot"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// Table prefixes of the light client's chain indexers. These are owned by the
+// light package, which depends on core, so they can only be mirrored here.
+var (
+	chtTablePrefix       = []byte("cht-")
+	chtIndexPrefix       = []byte("chtIndex-")
+	bloomTrieTablePrefix = []byte("blt-")
+	bloomTrieIndexPrefix = []byte("bltIndex-")
+)
+
+// DatabaseStat is the accumulated number and size of the database entries
+// belonging to a single data category.
+type DatabaseStat struct {
+	Name  string             // Human readable name of the data category
+	Count uint64             // Number of entries in the category
+	Size  common.StorageSize // Total size of the keys and values in the category
+
+	DanglingCount uint64             // Number of entries not belonging to the canonical chain
+	DanglingSize  common.StorageSize // Total size of the non-canonical entries
+}
+
+// add accounts a single database entry into the category.
+func (s *DatabaseStat) add(size int, dangling bool) {
+	s.Count++
+	s.Size += common.StorageSize(size)
+	if dangling {
+		s.DanglingCount++
+		s.DanglingSize += common.StorageSize(size)
+	}
+}
+
+// DatabaseStats is the storage usage breakdown of a chain database.
+type DatabaseStats struct {
+	Headers         DatabaseStat // Block headers
+	Bodies          DatabaseStat // Block bodies
+	Receipts        DatabaseStat // Block receipts
+	Difficulties    DatabaseStat // Total difficulties
+	CanonicalHashes DatabaseStat // Block number to canonical hash mappings
+	HashNumbers     DatabaseStat // Block hash to number mappings
+	TxLookups       DatabaseStat // Transaction lookup entries
+	BloomBits       DatabaseStat // Bloom bit vectors
+	BloomIndex      DatabaseStat // Bloom bits chain indexer progress
+	TrieNodes       DatabaseStat // State and storage trie nodes, contract code
+	AccountSnaps    DatabaseStat // Flat state snapshot account entries
+	StorageSnaps    DatabaseStat // Flat state snapshot storage entries
+	Preimages       DatabaseStat // Secure trie key preimages
+	Configs         DatabaseStat // Stored chain configurations
+	Metadata        DatabaseStat // Head markers and database version
+	ChtTries        DatabaseStat // Light client canonical hash trie nodes and index
+	BloomTries      DatabaseStat // Light client bloom trie nodes and index
+	LegacyReceipts  DatabaseStat // Receipts stored by the pre-lookup database schema
+	LegacyTxMeta    DatabaseStat // Transaction metadata of the pre-lookup database schema
+	Unknown         DatabaseStat // Entries not matching any known schema
+
+	Ancients []*DatabaseStat // Tables of the ancient store, if the database has one
+}
+
+// Categories returns the statistics of every data category, in a stable order
+// suitable for reporting.
+func (s *DatabaseStats) Categories() []*DatabaseStat {
+	return append([]*DatabaseStat{
+		&s.Headers, &s.Bodies, &s.Receipts, &s.Difficulties, &s.CanonicalHashes,
+		&s.HashNumbers, &s.TxLookups, &s.BloomBits, &s.BloomIndex, &s.TrieNodes,
+		&s.AccountSnaps, &s.StorageSnaps, &s.Preimages, &s.Configs, &s.Metadata, &s.ChtTries, &s.BloomTries,
+		&s.LegacyReceipts, &s.LegacyTxMeta, &s.Unknown,
+	}, s.Ancients...)
+}
+
+// Total returns the accumulated statistics across all data categories.
+func (s *DatabaseStats) Total() DatabaseStat {
+	total := DatabaseStat{Name: "Total"}
+	for _, stat := range s.Categories() {
+		total.Count += stat.Count
+		total.Size += stat.Size
+		total.DanglingCount += stat.DanglingCount
+		total.DanglingSize += stat.DanglingSize
+	}
+	return total
+}
+
+// newDatabaseStats creates an empty statistics set with all categories named.
+func newDatabaseStats() *DatabaseStats {
+	return &DatabaseStats{
+		Headers:         DatabaseStat{Name: "Headers"},
+		Bodies:          DatabaseStat{Name: "Bodies"},
+		Receipts:        DatabaseStat{Name: "Receipts"},
+		Difficulties:    DatabaseStat{Name: "Difficulties"},
+		CanonicalHashes: DatabaseStat{Name: "Canonical hashes"},
+		HashNumbers:     DatabaseStat{Name: "Block number lookups"},
+		TxLookups:       DatabaseStat{Name: "Transaction lookups"},
+		BloomBits:       DatabaseStat{Name: "Bloom bits"},
+		BloomIndex:      DatabaseStat{Name: "Bloom bits index"},
+		TrieNodes:       DatabaseStat{Name: "Trie nodes and code"},
+		AccountSnaps:    DatabaseStat{Name: "Account snapshot"},
+		StorageSnaps:    DatabaseStat{Name: "Storage snapshot"},
+		Preimages:       DatabaseStat{Name: "Trie preimages"},
+		Configs:         DatabaseStat{Name: "Chain configs"},
+		Metadata:        DatabaseStat{Name: "Metadata"},
+		ChtTries:        DatabaseStat{Name: "Light CHT tries"},
+		BloomTries:      DatabaseStat{Name: "Light bloom tries"},
+		LegacyReceipts:  DatabaseStat{Name: "Legacy receipts"},
+		LegacyTxMeta:    DatabaseStat{Name: "Legacy tx metadata"},
+		Unknown:         DatabaseStat{Name: "Unknown"},
+	}
+}
+
+// InspectDatabase iterates over every entry of the chain database and sorts it
+// into data categories based on the key schema, accumulating entry counts and
+// storage sizes. Chain data (headers, bodies, receipts and difficulties) not
+// belonging to the canonical chain is additionally reported as dangling. If the
+// database is backed by a freezer, the sizes of its tables are reported too.
+func InspectDatabase(db vapdb.Database) (*DatabaseStats, error) {
+	it := db.NewIteratorWithStart(nil)
+	defer it.Release()
+
+	var (
+		stats  = newDatabaseStats()
+		start  = time.Now()
+		logged = time.Now()
+
+		// Canonical hash lookups are cached as chain data is laid out by number
+		canonNumber = missingNumber
+		canonHash   common.Hash
+	)
+	// isDangling reports whether the given number-hash pair is not canonical
+	isDangling := func(number uint64, hash []byte) bool {
+		if number != canonNumber {
+			canonNumber, canonHash = number, GetCanonicalHash(db, number)
+		}
+		return !bytes.Equal(canonHash[:], hash)
+	}
+	for it.Next() {
+		var (
+			key  = it.Key()
+			size = len(key) + len(it.Value())
+		)
+		switch {
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength:
+			stats.Headers.add(size, isDangling(binary.BigEndian.Uint64(key[1:9]), key[9:]))
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+common.HashLength+len(tdSuffix) && bytes.HasSuffix(key, tdSuffix):
+			stats.Difficulties.add(size, isDangling(binary.BigEndian.Uint64(key[1:9]), key[9:9+common.HashLength]))
+		case bytes.HasPrefix(key, headerPrefix) && len(key) == len(headerPrefix)+8+len(numSuffix) && bytes.HasSuffix(key, numSuffix):
+			stats.CanonicalHashes.add(size, false)
+		case bytes.HasPrefix(key, blockHashPrefix) && len(key) == len(blockHashPrefix)+common.HashLength:
+			stats.HashNumbers.add(size, false)
+		case bytes.HasPrefix(key, bodyPrefix) && len(key) == len(bodyPrefix)+8+common.HashLength:
+			stats.Bodies.add(size, isDangling(binary.BigEndian.Uint64(key[1:9]), key[9:]))
+		case bytes.HasPrefix(key, blockReceiptsPrefix) && len(key) == len(blockReceiptsPrefix)+8+common.HashLength:
+			stats.Receipts.add(size, isDangling(binary.BigEndian.Uint64(key[1:9]), key[9:]))
+		case bytes.HasPrefix(key, lookupPrefix) && len(key) == len(lookupPrefix)+common.HashLength:
+			stats.TxLookups.add(size, false)
+		case bytes.HasPrefix(key, bloomBitsPrefix) && len(key) == len(bloomBitsPrefix)+2+8+common.HashLength:
+			stats.BloomBits.add(size, false)
+		case bytes.HasPrefix(key, BloomBitsIndexPrefix):
+			stats.BloomIndex.add(size, false)
+		case bytes.HasPrefix(key, []byte(preimagePrefix)) && len(key) == len(preimagePrefix)+common.HashLength:
+			stats.Preimages.add(size, false)
+		case bytes.HasPrefix(key, configPrefix) && len(key) == len(configPrefix)+common.HashLength:
+			stats.Configs.add(size, false)
+		case bytes.HasPrefix(key, chtTablePrefix) || bytes.HasPrefix(key, chtIndexPrefix):
+			stats.ChtTries.add(size, false)
+		case bytes.HasPrefix(key, bloomTrieTablePrefix) || bytes.HasPrefix(key, bloomTrieIndexPrefix):
+			stats.BloomTries.add(size, false)
+		case bytes.HasPrefix(key, oldReceiptsPrefix) && len(key) == len(oldReceiptsPrefix)+common.HashLength:
+			stats.LegacyReceipts.add(size, false)
+		case len(key) == common.HashLength+len(oldTxMetaSuffix) && bytes.HasSuffix(key, oldTxMetaSuffix):
+			stats.LegacyTxMeta.add(size, false)
+		case len(key) == common.HashLength:
+			stats.TrieNodes.add(size, false)
+		case bytes.HasPrefix(key, snapshot.AccountPrefix) && len(key) == len(snapshot.AccountPrefix)+common.HashLength:
+			stats.AccountSnaps.add(size, false)
+		case bytes.HasPrefix(key, snapshot.StoragePrefix) && len(key) == len(snapshot.StoragePrefix)+2*common.HashLength:
+			stats.StorageSnaps.add(size, false)
+		default:
+			var metadata bool
+			for _, meta := range [][]byte{headHeaderKey, headBlockKey, headFastKey, pruningCheckpointKey, snapshot.RootKey, snapshot.GeneratorKey, StateSyncProgressKey, []byte("BlockchainVersion")} {
+				if bytes.Equal(key, meta) {
+					metadata = true
+					break
+				}
+			}
+			if metadata {
+				stats.Metadata.add(size, false)
+			} else {
+				stats.Unknown.add(size, false)
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			total := stats.Total()
+			log.Info("Inspecting database", "count", total.Count, "size", total.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if frdb, ok := db.(*freezerDatabase); ok {
+		for _, name := range []string{freezerHeaderTable, freezerBodiesTable, freezerReceiptTable, freezerDifficultyTable, freezerHashTable} {
+			table := frdb.tables[name]
+			stats.Ancients = append(stats.Ancients, &DatabaseStat{
+				Name:  "Ancient " + name,
+				Count: table.Items(),
+				Size:  common.StorageSize(table.Size()),
+			})
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// Tests that database inspection sorts entries into the correct categories and
+// flags non-canonical chain data as dangling.
+func TestInspectDatabase(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+
+	// Write a canonical and a side block at the same height
+	canon := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte("canonical")})
+	side := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(1), Extra: []byte("side")})
+
+	for _, block := range []*types.Block{canon, side} {
+		if err := WriteBlock(db, block); err != nil {
+			t.Fatalf("failed to write block: %v", err)
+		}
+		if err := WriteTd(db, block.Hash(), block.NumberU64(), big.NewInt(1)); err != nil {
+			t.Fatalf("failed to write td: %v", err)
+		}
+		if err := WriteBlockReceipts(db, block.Hash(), block.NumberU64(), nil); err != nil {
+			t.Fatalf("failed to write receipts: %v", err)
+		}
+	}
+	WriteCanonicalHash(db, canon.Hash(), canon.NumberU64())
+	WriteHeadBlockHash(db, canon.Hash())
+
+	// Add some state and a few unknown entries
+	db.Put(common.Hash{0x01}.Bytes(), []byte("trie node"))
+	db.Put(append([]byte(preimagePrefix), common.Hash{0x02}.Bytes()...), []byte("preimage"))
+	db.Put([]byte("garbage"), []byte("value"))
+
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	tests := []struct {
+		stat            *DatabaseStat
+		count, dangling uint64
+	}{
+		{&stats.Headers, 2, 1},
+		{&stats.Bodies, 2, 1},
+		{&stats.Receipts, 2, 1},
+		{&stats.Difficulties, 2, 1},
+		{&stats.CanonicalHashes, 1, 0},
+		{&stats.HashNumbers, 2, 0},
+		{&stats.TrieNodes, 1, 0},
+		{&stats.Preimages, 1, 0},
+		{&stats.Metadata, 1, 0},
+		{&stats.Unknown, 1, 0},
+	}
+	for _, tt := range tests {
+		if tt.stat.Count != tt.count {
+			t.Errorf("%s: count mismatch: have %d, want %d", tt.stat.Name, tt.stat.Count, tt.count)
+		}
+		if tt.stat.DanglingCount != tt.dangling {
+			t.Errorf("%s: dangling count mismatch: have %d, want %d", tt.stat.Name, tt.stat.DanglingCount, tt.dangling)
+		}
+	}
+	if total := stats.Total(); total.Count != 15 {
+		t.Errorf("total count mismatch: have %d, want %d", total.Count, 15)
+	}
+}
+
+// Tests that the tables of the ancient store are reported if the database is
+// backed by a freezer.
+func TestInspectAncientDatabase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	kvdb, _ := vapdb.NewMemDatabase()
+	db, err := NewDatabaseWithFreezer(kvdb, dir)
+	if err != nil {
+		t.Fatalf("failed to create ancient database: %v", err)
+	}
+	defer db.Close()
+
+	blob := []byte("ancient")
+	for i := uint64(0); i < 2; i++ {
+		if err := db.(AncientStore).AppendAncient(i, common.Hash{byte(i)}.Bytes(), blob, blob, blob, blob); err != nil {
+			t.Fatalf("failed to append ancient block %d: %v", i, err)
+		}
+	}
+	stats, err := InspectDatabase(db)
+	if err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	if len(stats.Ancients) != len(freezerNoSnappy) {
+		t.Fatalf("ancient table count mismatch: have %d, want %d", len(stats.Ancients), len(freezerNoSnappy))
+	}
+	for _, stat := range stats.Ancients {
+		if stat.Count != 2 {
+			t.Errorf("%s: count mismatch: have %d, want %d", stat.Name, stat.Count, 2)
+		}
+		if stat.Size <= 2*indexEntrySize {
+			t.Errorf("%s: size too small: %v", stat.Name, stat.Size)
+		}
+	}
+	if total := stats.Total(); total.Count != 2*uint64(len(freezerNoSnappy)) {
+		t.Errorf("total count mismatch: have %d, want %d", total.Count, 2*len(freezerNoSnappy))
+	}
+}
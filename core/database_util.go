@@ -48,6 +48,12 @@ var (
 	headBlockKey  = []byte("LastBlock")
 	headFastKey   = []byte("LastFast")
 
+	// pruningCheckpointKey tracks the progress of an interrupted state pruning.
+	pruningCheckpointKey = []byte("PruningCheckpoint")
+
+	// StateSyncProgressKey tracks the progress of an interrupted state sync.
+	StateSyncProgressKey = []byte("StateSyncProgress")
+
 	// Data item prefixes (use single byte to avoid mixing data types, avoid `i`).
 	headerPrefix        = []byte("h") // headerPrefix + num (uint64 big endian) + hash -> header
 	tdSuffix            = []byte("t") // headerPrefix + num (uint64 big endian) + hash + tdSuffix -> td
@@ -92,6 +98,12 @@ func encodeBlockNumber(number uint64) []byte {
 // GetCanonicalHash retrieves a hash assigned to a canonical block number.
 func GetCanonicalHash(db DatabaseReader, number uint64) common.Hash {
 	data, _ := db.Get(append(append(headerPrefix, encodeBlockNumber(number)...), numSuffix...))
+	if len(data) == 0 {
+		// Canonical hashes of frozen blocks are only kept in the ancient store
+		if store, ok := db.(AncientReader); ok && store.HasAncient(freezerHashTable, number) {
+			data, _ = store.Ancient(freezerHashTable, number)
+		}
+	}
 	if len(data) == 0 {
 		return common.Hash{}
 	}
@@ -150,6 +162,9 @@ func GetHeadFastBlockHash(db DatabaseReader) common.Hash {
 // if the header's not found.
 func GetHeaderRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(headerKey(hash, number))
+	if len(data) == 0 {
+		data = readAncient(db, freezerHeaderTable, hash, number)
+	}
 	return data
 }
 
@@ -171,6 +186,9 @@ func GetHeader(db DatabaseReader, hash common.Hash, number uint64) *types.Header
 // GetBodyRLP retrieves the block body (transactions and uncles) in RLP encoding.
 func GetBodyRLP(db DatabaseReader, hash common.Hash, number uint64) rlp.RawValue {
 	data, _ := db.Get(blockBodyKey(hash, number))
+	if len(data) == 0 {
+		data = readAncient(db, freezerBodiesTable, hash, number)
+	}
 	return data
 }
 
@@ -201,6 +219,9 @@ func GetBody(db DatabaseReader, hash common.Hash, number uint64) *types.Body {
 // none found.
 func GetTd(db DatabaseReader, hash common.Hash, number uint64) *big.Int {
 	data, _ := db.Get(append(append(append(headerPrefix, encodeBlockNumber(number)...), hash[:]...), tdSuffix...))
+	if len(data) == 0 {
+		data = readAncient(db, freezerDifficultyTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -236,6 +257,9 @@ func GetBlock(db DatabaseReader, hash common.Hash, number uint64) *types.Block {
 // in a block given by its hash.
 func GetBlockReceipts(db DatabaseReader, hash common.Hash, number uint64) types.Receipts {
 	data, _ := db.Get(append(append(blockReceiptsPrefix, encodeBlockNumber(number)...), hash[:]...))
+	if len(data) == 0 {
+		data = readAncient(db, freezerReceiptTable, hash, number)
+	}
 	if len(data) == 0 {
 		return nil
 	}
@@ -0,0 +1,308 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/vapdb"
+	"github.com/syndtr/goleveldb/leveldb"
+)
+
+const (
+	// freezerHeaderTable indicates the name of the freezer header table.
+	freezerHeaderTable = "headers"
+
+	// freezerHashTable indicates the name of the freezer canonical hash table.
+	freezerHashTable = "hashes"
+
+	// freezerBodiesTable indicates the name of the freezer block body table.
+	freezerBodiesTable = "bodies"
+
+	// freezerReceiptTable indicates the name of the freezer receipts table.
+	freezerReceiptTable = "receipts"
+
+	// freezerDifficultyTable indicates the name of the freezer total difficulty table.
+	freezerDifficultyTable = "diffs"
+)
+
+// freezerNoSnappy configures whether compression is disabled for the ancient
+// tables. Hashes are random and wouldn't compress anyway.
+var freezerNoSnappy = map[string]bool{
+	freezerHeaderTable:     false,
+	freezerHashTable:       true,
+	freezerBodiesTable:     false,
+	freezerReceiptTable:    false,
+	freezerDifficultyTable: false,
+}
+
+// AncientReader contains the methods required to read from immutable ancient data.
+type AncientReader interface {
+	// HasAncient returns an indicator whether the specified data exists in the
+	// ancient store.
+	HasAncient(kind string, number uint64) bool
+
+	// Ancient retrieves an ancient binary blob from the append-only immutable files.
+	Ancient(kind string, number uint64) ([]byte, error)
+
+	// Ancients returns the number of ancient items stored.
+	Ancients() uint64
+}
+
+// AncientWriter contains the methods required to write to immutable ancient data.
+type AncientWriter interface {
+	// AppendAncient injects all binary blobs belonging to a block at the end of
+	// the append-only immutable table files.
+	AppendAncient(number uint64, hash, header, body, receipts, td []byte) error
+
+	// TruncateAncients discards all but the first n ancient data from the store.
+	TruncateAncients(n uint64) error
+
+	// Sync flushes all in-memory ancient store data to disk.
+	Sync() error
+}
+
+// AncientStore contains all the methods required to read from and write to
+// immutable ancient data.
+type AncientStore interface {
+	AncientReader
+	AncientWriter
+}
+
+// freezer is an append-only database to store immutable chain data into flat
+// files:
+//
+// - The append only nature ensures that disk writes are minimized.
+// - The in-order storage allows the data to be looked up by block number only.
+type freezer struct {
+	tables map[string]*freezerTable // Data tables for storing everything
+	frozen uint64                   // Number of blocks already frozen
+	lock   sync.RWMutex             // Protects the frozen counter against concurrent appends
+}
+
+// newFreezer creates a chain freezer that moves ancient chain data into
+// append-only flat file containers.
+func newFreezer(datadir string) (*freezer, error) {
+	freezer := &freezer{
+		tables: make(map[string]*freezerTable),
+	}
+	for name, noSnappy := range freezerNoSnappy {
+		table, err := newTable(datadir, name, noSnappy)
+		if err != nil {
+			for _, table := range freezer.tables {
+				table.Close()
+			}
+			return nil, err
+		}
+		freezer.tables[name] = table
+	}
+	if err := freezer.repair(); err != nil {
+		freezer.Close()
+		return nil, err
+	}
+	log.Info("Opened ancient database", "database", datadir, "frozen", freezer.frozen)
+	return freezer, nil
+}
+
+// repair truncates all data tables to the same length.
+func (f *freezer) repair() error {
+	min := uint64(1<<64 - 1)
+	for _, table := range f.tables {
+		if items := table.Items(); items < min {
+			min = items
+		}
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(min); err != nil {
+			return err
+		}
+	}
+	f.frozen = min
+	return nil
+}
+
+// Close terminates the chain freezer, closing all the data files.
+func (f *freezer) Close() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// HasAncient returns an indicator whether the specified ancient data exists
+// in the freezer.
+func (f *freezer) HasAncient(kind string, number uint64) bool {
+	if _, ok := f.tables[kind]; !ok {
+		return false
+	}
+	return number < f.Ancients()
+}
+
+// Ancient retrieves an ancient binary blob from the append-only immutable files.
+func (f *freezer) Ancient(kind string, number uint64) ([]byte, error) {
+	table := f.tables[kind]
+	if table == nil {
+		return nil, fmt.Errorf("unknown ancient table %q", kind)
+	}
+	if number >= f.Ancients() {
+		return nil, errOutOfBounds
+	}
+	return table.Retrieve(number)
+}
+
+// Ancients returns the length of the frozen items.
+func (f *freezer) Ancients() uint64 {
+	f.lock.RLock()
+	defer f.lock.RUnlock()
+
+	return f.frozen
+}
+
+// AppendAncient injects all binary blobs belonging to a block at the end of the
+// append-only immutable table files. All out-of-order injections are rejected,
+// and a failed injection rolls back any tables already appended to.
+func (f *freezer) AppendAncient(number uint64, hash, header, body, receipts, td []byte) (err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if number != f.frozen {
+		return errOutOrderInsertion
+	}
+	// Roll back all tables to the starting position in case of error
+	defer func() {
+		if err != nil {
+			for _, table := range f.tables {
+				if rerr := table.truncate(f.frozen); rerr != nil {
+					log.Error("Failed to roll back ancient tables", "number", number, "err", rerr)
+				}
+			}
+		}
+	}()
+	blobs := map[string][]byte{
+		freezerHashTable:       hash,
+		freezerHeaderTable:     header,
+		freezerBodiesTable:     body,
+		freezerReceiptTable:    receipts,
+		freezerDifficultyTable: td,
+	}
+	for kind, blob := range blobs {
+		if err := f.tables[kind].Append(number, blob); err != nil {
+			log.Error("Failed to append ancient data", "kind", kind, "number", number, "err", err)
+			return err
+		}
+	}
+	f.frozen++
+	return nil
+}
+
+// TruncateAncients discards any recent data above the provided threshold number.
+func (f *freezer) TruncateAncients(items uint64) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.frozen <= items {
+		return nil
+	}
+	for _, table := range f.tables {
+		if err := table.truncate(items); err != nil {
+			return err
+		}
+	}
+	f.frozen = items
+	return nil
+}
+
+// Sync flushes all data tables to disk.
+func (f *freezer) Sync() error {
+	var errs []error
+	for _, table := range f.tables {
+		if err := table.Sync(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
+
+// freezerDatabase is a key-value database backed by a freezer holding the
+// immutable ancient chain segments.
+type freezerDatabase struct {
+	vapdb.Database
+	*freezer
+}
+
+// NewDatabaseWithFreezer creates a chain database backed by the given key-value
+// store for the recent chain segment and state, and by an append-only flat file
+// freezer in the ancient directory for the immutable chain segment.
+func NewDatabaseWithFreezer(db vapdb.Database, ancient string) (vapdb.Database, error) {
+	frdb, err := newFreezer(ancient)
+	if err != nil {
+		return nil, err
+	}
+	return &freezerDatabase{Database: db, freezer: frdb}, nil
+}
+
+// Close closes both the ancient freezer and the key-value database.
+func (db *freezerDatabase) Close() {
+	if err := db.freezer.Close(); err != nil {
+		log.Error("Failed to close ancient database", "err", err)
+	}
+	db.Database.Close()
+}
+
+// LDB retrieves the leveldb instance backing the key-value store, or nil if the
+// freezer wraps a database not backed by leveldb (e.g. in-memory).
+func (db *freezerDatabase) LDB() *leveldb.DB {
+	if ldb, ok := db.Database.(interface {
+		LDB() *leveldb.DB
+	}); ok {
+		return ldb.LDB()
+	}
+	return nil
+}
+
+// readAncient retrieves an item of the given kind from the ancient store backing
+// the database, if any, ensuring it belongs to the block with the given hash.
+func readAncient(db DatabaseReader, kind string, hash common.Hash, number uint64) []byte {
+	if !hasAncient(db, hash, number) {
+		return nil
+	}
+	data, _ := db.(AncientReader).Ancient(kind, number)
+	return data
+}
+
+// hasAncient checks whether the block with the given hash and number is stored
+// in the ancient store backing the database, if any.
+func hasAncient(db DatabaseReader, hash common.Hash, number uint64) bool {
+	store, ok := db.(AncientReader)
+	if !ok || !store.HasAncient(freezerHashTable, number) {
+		return false
+	}
+	canon, _ := store.Ancient(freezerHashTable, number)
+	return common.BytesToHash(canon) == hash
+}
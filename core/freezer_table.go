@@ -0,0 +1,295 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/golang/snappy"
+	"github.com/vaporyco/go-vapory/log"
+)
+
+var (
+	// errClosed is returned if an operation attempts to read from or write to the
+	// freezer table after it has already been closed.
+	errClosed = errors.New("closed")
+
+	// errOutOfBounds is returned if the item requested is not contained within the
+	// freezer table.
+	errOutOfBounds = errors.New("out of bounds")
+
+	// errOutOrderInsertion is returned if the user attempts to inject out-of-order
+	// binary blobs into the freezer.
+	errOutOrderInsertion = errors.New("the append operation is out-order")
+)
+
+// indexEntrySize is the size of a single entry in a freezer table's index file,
+// containing the end offset of the item in the data file.
+const indexEntrySize = 8
+
+// freezerTable represents a single chained data table within the freezer. It
+// consists of an append-only data file holding the (optionally snappy compressed)
+// items back to back, and an index file holding the end offset of each item.
+type freezerTable struct {
+	items     uint64 // Number of items stored in the table
+	dataBytes uint64 // Number of bytes written to the data file
+	noSnappy  bool   // Whether to store the items uncompressed
+	data      *os.File
+	index     *os.File
+	lock      sync.RWMutex // Mutex protecting the data file descriptors
+	logger    log.Logger   // Logger with database path and table name embedded
+}
+
+// newTable opens a freezer table, creating the data and index files if they do
+// not yet exist and repairing any inconsistency left by an unclean shutdown.
+func newTable(path string, name string, noSnappy bool) (*freezerTable, error) {
+	if err := os.MkdirAll(path, 0755); err != nil {
+		return nil, err
+	}
+	// Compressed and uncompressed tables use different file names to avoid
+	// interpreting the content of one as the other
+	dataName, indexName := fmt.Sprintf("%s.cdat", name), fmt.Sprintf("%s.cidx", name)
+	if noSnappy {
+		dataName, indexName = fmt.Sprintf("%s.rdat", name), fmt.Sprintf("%s.ridx", name)
+	}
+	index, err := os.OpenFile(filepath.Join(path, indexName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.OpenFile(filepath.Join(path, dataName), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		index.Close()
+		return nil, err
+	}
+	tab := &freezerTable{
+		noSnappy: noSnappy,
+		data:     data,
+		index:    index,
+		logger:   log.New("database", path, "table", name),
+	}
+	if err := tab.repair(); err != nil {
+		tab.Close()
+		return nil, err
+	}
+	return tab, nil
+}
+
+// repair cross checks the data and index files, truncating them to the last item
+// fully contained in both.
+func (t *freezerTable) repair() error {
+	stat, err := t.index.Stat()
+	if err != nil {
+		return err
+	}
+	// Drop any partially written index entry
+	indexSize := uint64(stat.Size())
+	if overflow := indexSize % indexEntrySize; overflow != 0 {
+		indexSize -= overflow
+		if err := t.index.Truncate(int64(indexSize)); err != nil {
+			return err
+		}
+	}
+	if stat, err = t.data.Stat(); err != nil {
+		return err
+	}
+	dataSize := uint64(stat.Size())
+
+	// Drop all index entries pointing beyond the end of the data file
+	t.items = indexSize / indexEntrySize
+	for {
+		end, err := t.offset(t.items)
+		if err != nil {
+			return err
+		}
+		if end <= dataSize {
+			t.dataBytes = end
+			break
+		}
+		t.items--
+	}
+	if t.items*indexEntrySize != indexSize {
+		t.logger.Warn("Truncated dangling freezer index", "items", t.items)
+		if err := t.index.Truncate(int64(t.items * indexEntrySize)); err != nil {
+			return err
+		}
+	}
+	// Drop any data not referenced by the index
+	if t.dataBytes != dataSize {
+		t.logger.Warn("Truncated dangling freezer data", "size", t.dataBytes)
+		if err := t.data.Truncate(int64(t.dataBytes)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// offset returns the end offset of the first n items in the data file, i.e. the
+// offset at which the n-th item (zero based) starts.
+func (t *freezerTable) offset(n uint64) (uint64, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	var buf [indexEntrySize]byte
+	if _, err := t.index.ReadAt(buf[:], int64((n-1)*indexEntrySize)); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint64(buf[:]), nil
+}
+
+// Items returns the number of items stored in the table.
+func (t *freezerTable) Items() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.items
+}
+
+// Size returns the total number of bytes stored in the data and index files of
+// the table.
+func (t *freezerTable) Size() uint64 {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	return t.dataBytes + t.items*indexEntrySize
+}
+
+// Append injects a binary blob at the end of the freezer table. The item number
+// must be exactly the number of items already stored.
+func (t *freezerTable) Append(item uint64, blob []byte) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if t.items != item {
+		return errOutOrderInsertion
+	}
+	if !t.noSnappy {
+		blob = snappy.Encode(nil, blob)
+	}
+	// Write the data first, the index entry makes it visible
+	if _, err := t.data.WriteAt(blob, int64(t.dataBytes)); err != nil {
+		return err
+	}
+	var entry [indexEntrySize]byte
+	binary.BigEndian.PutUint64(entry[:], t.dataBytes+uint64(len(blob)))
+	if _, err := t.index.WriteAt(entry[:], int64(t.items*indexEntrySize)); err != nil {
+		return err
+	}
+	t.dataBytes += uint64(len(blob))
+	t.items++
+	return nil
+}
+
+// Retrieve looks up the data offset of an item with the given number and
+// retrieves the raw binary blob from the data file.
+func (t *freezerTable) Retrieve(item uint64) ([]byte, error) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return nil, errClosed
+	}
+	if item >= t.items {
+		return nil, errOutOfBounds
+	}
+	start, err := t.offset(item)
+	if err != nil {
+		return nil, err
+	}
+	end, err := t.offset(item + 1)
+	if err != nil {
+		return nil, err
+	}
+	blob := make([]byte, end-start)
+	if _, err := t.data.ReadAt(blob, int64(start)); err != nil {
+		return nil, err
+	}
+	if t.noSnappy {
+		return blob, nil
+	}
+	return snappy.Decode(nil, blob)
+}
+
+// truncate discards any recent data above the provided threshold number.
+func (t *freezerTable) truncate(items uint64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if t.items <= items {
+		return nil
+	}
+	end, err := t.offset(items)
+	if err != nil {
+		return err
+	}
+	if err := t.index.Truncate(int64(items * indexEntrySize)); err != nil {
+		return err
+	}
+	if err := t.data.Truncate(int64(end)); err != nil {
+		return err
+	}
+	t.items, t.dataBytes = items, end
+	return nil
+}
+
+// Sync pushes any pending data from memory out to disk.
+func (t *freezerTable) Sync() error {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if t.index == nil || t.data == nil {
+		return errClosed
+	}
+	if err := t.data.Sync(); err != nil {
+		return err
+	}
+	return t.index.Sync()
+}
+
+// Close closes all opened files.
+func (t *freezerTable) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	var errs []error
+	if t.index != nil {
+		if err := t.index.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.index = nil
+	}
+	if t.data != nil {
+		if err := t.data.Close(); err != nil {
+			errs = append(errs, err)
+		}
+		t.data = nil
+	}
+	if errs != nil {
+		return fmt.Errorf("%v", errs)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// getChunk returns a deterministic test blob of the given size.
+func getChunk(size int, b int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(b)
+	}
+	return data
+}
+
+// Tests that items appended to a freezer table can be retrieved, also after the
+// table is closed and reopened, and that out of order appends are rejected.
+func TestFreezerTableAppendRetrieve(t *testing.T) {
+	for _, noSnappy := range []bool{false, true} {
+		dir, err := ioutil.TempDir("", "freezer")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer os.RemoveAll(dir)
+
+		table, err := newTable(dir, "test", noSnappy)
+		if err != nil {
+			t.Fatalf("failed to create table: %v", err)
+		}
+		for i := 0; i < 255; i++ {
+			if err := table.Append(uint64(i), getChunk(i%16+1, i)); err != nil {
+				t.Fatalf("failed to append item %d: %v", i, err)
+			}
+		}
+		if err := table.Append(300, getChunk(1, 0)); err != errOutOrderInsertion {
+			t.Fatalf("out of order append error mismatch: have %v, want %v", err, errOutOrderInsertion)
+		}
+		table.Close()
+
+		if table, err = newTable(dir, "test", noSnappy); err != nil {
+			t.Fatalf("failed to reopen table: %v", err)
+		}
+		if items := table.Items(); items != 255 {
+			t.Fatalf("item count mismatch: have %d, want %d", items, 255)
+		}
+		for i := 0; i < 255; i++ {
+			blob, err := table.Retrieve(uint64(i))
+			if err != nil {
+				t.Fatalf("failed to retrieve item %d: %v", i, err)
+			}
+			if !bytes.Equal(blob, getChunk(i%16+1, i)) {
+				t.Fatalf("item %d mismatch: have %x, want %x", i, blob, getChunk(i%16+1, i))
+			}
+		}
+		if _, err := table.Retrieve(255); err != errOutOfBounds {
+			t.Fatalf("out of bounds retrieval error mismatch: have %v, want %v", err, errOutOfBounds)
+		}
+		table.Close()
+	}
+}
+
+// Tests that truncating a freezer table discards the items above the limit and
+// that new items can be appended afterwards.
+func TestFreezerTableTruncate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := newTable(dir, "test", false)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	defer table.Close()
+
+	for i := 0; i < 30; i++ {
+		table.Append(uint64(i), getChunk(15, i))
+	}
+	if err := table.truncate(10); err != nil {
+		t.Fatalf("failed to truncate table: %v", err)
+	}
+	if items := table.Items(); items != 10 {
+		t.Fatalf("item count mismatch: have %d, want %d", items, 10)
+	}
+	if _, err := table.Retrieve(10); err != errOutOfBounds {
+		t.Fatalf("truncated item retrievable: %v", err)
+	}
+	if err := table.Append(10, getChunk(15, 0xff)); err != nil {
+		t.Fatalf("failed to append after truncation: %v", err)
+	}
+	if blob, _ := table.Retrieve(10); !bytes.Equal(blob, getChunk(15, 0xff)) {
+		t.Fatalf("item mismatch after truncation: have %x, want %x", blob, getChunk(15, 0xff))
+	}
+}
+
+// Tests that a freezer table with a partially written item (e.g. after a crash)
+// is repaired on open, dropping the dangling data.
+func TestFreezerTableRepair(t *testing.T) {
+	dir, err := ioutil.TempDir("", "freezer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	table, err := newTable(dir, "test", true)
+	if err != nil {
+		t.Fatalf("failed to create table: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		table.Append(uint64(i), getChunk(20, i))
+	}
+	table.Close()
+
+	// Chop off part of the last item and add a partial index entry
+	data := filepath.Join(dir, "test.rdat")
+	if err := os.Truncate(data, 9*20+5); err != nil {
+		t.Fatal(err)
+	}
+	index, err := os.OpenFile(filepath.Join(dir, "test.ridx"), os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	index.Write([]byte{0x00, 0x01, 0x02})
+	index.Close()
+
+	if table, err = newTable(dir, "test", true); err != nil {
+		t.Fatalf("failed to reopen table: %v", err)
+	}
+	defer table.Close()
+
+	if items := table.Items(); items != 9 {
+		t.Fatalf("item count mismatch: have %d, want %d", items, 9)
+	}
+	if stat, _ := os.Stat(data); stat.Size() != 9*20 {
+		t.Fatalf("data size mismatch: have %d, want %d", stat.Size(), 9*20)
+	}
+	for i := 0; i < 9; i++ {
+		if blob, err := table.Retrieve(uint64(i)); err != nil || !bytes.Equal(blob, getChunk(20, i)) {
+			t.Fatalf("item %d mismatch: have %x (%v), want %x", i, blob, err, getChunk(20, i))
+		}
+	}
+	if err := table.Append(9, getChunk(20, 9)); err != nil {
+		t.Fatalf("failed to append after repair: %v", err)
+	}
+}
@@ -0,0 +1,175 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/consensus/vapash"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/core/vm"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// Tests that old canonical blocks are migrated from the key-value store into the
+// ancient store, remain retrievable, and are truncated when rewinding the chain.
+func TestBlockChainFreeze(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		key, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+		address = crypto.PubkeyToAddress(key.PublicKey)
+		gspec   = &Genesis{
+			Config: params.TestChainConfig,
+			Alloc:  GenesisAlloc{address: {Balance: big.NewInt(1000000000)}},
+		}
+		signer = types.NewEIP155Signer(gspec.Config.ChainId)
+	)
+	gendb, _ := vapdb.NewMemDatabase()
+	genesis := gspec.MustCommit(gendb)
+	blocks, receipts := GenerateChain(gspec.Config, genesis, vapash.NewFaker(), gendb, 32, func(i int, block *BlockGen) {
+		tx, err := types.SignTx(types.NewTransaction(block.TxNonce(address), common.Address{0x01}, big.NewInt(1000), params.TxGas, nil, nil), signer, key)
+		if err != nil {
+			panic(err)
+		}
+		block.AddTx(tx)
+	})
+	// Fork off a shorter side chain, which should be wiped when frozen
+	forks, _ := GenerateChain(gspec.Config, blocks[4], vapash.NewFaker(), gendb, 4, func(i int, block *BlockGen) {
+		block.SetCoinbase(common.Address{0x02})
+	})
+	kvdb, _ := vapdb.NewMemDatabase()
+	db, err := NewDatabaseWithFreezer(kvdb, dir)
+	if err != nil {
+		t.Fatalf("failed to create ancient database: %v", err)
+	}
+	gspec.MustCommit(db)
+
+	chain, err := NewBlockChain(db, nil, gspec.Config, vapash.NewFaker(), vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	defer chain.Stop()
+
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert chain: %v", err)
+	}
+	if _, err := chain.InsertChain(forks); err != nil {
+		t.Fatalf("failed to insert side chain: %v", err)
+	}
+	// Freeze everything but the last 8 blocks and check that it was all moved
+	chain.cacheConfig.FreezerThreshold = 8
+	if frozen, err := chain.freeze(); err != nil || frozen != 24 {
+		t.Fatalf("frozen block count mismatch: have %d (%v), want %d", frozen, err, 24)
+	}
+	store := db.(AncientStore)
+	if ancients := store.Ancients(); ancients != 24 {
+		t.Fatalf("ancient count mismatch: have %d, want %d", ancients, 24)
+	}
+	for i := uint64(1); i <= 32; i++ {
+		block := blocks[i-1]
+
+		frozen := i < 24
+		if have, _ := kvdb.Has(headerKey(block.Hash(), i)); have == frozen {
+			t.Errorf("block %d: header presence in key-value store mismatch: have %v, want %v", i, have, !frozen)
+		}
+		if hash := GetCanonicalHash(db, i); hash != block.Hash() {
+			t.Errorf("block %d: canonical hash mismatch: have %x, want %x", i, hash, block.Hash())
+		}
+		if stored := GetBlock(db, block.Hash(), i); stored == nil || stored.Hash() != block.Hash() {
+			t.Errorf("block %d: block mismatch: have %v, want %x", i, stored, block.Hash())
+		}
+		if stored := GetBlockReceipts(db, block.Hash(), i); types.DeriveSha(stored) != types.DeriveSha(receipts[i-1]) {
+			t.Errorf("block %d: receipts mismatch", i)
+		}
+		if td := GetTd(db, block.Hash(), i); td == nil || td.Cmp(chain.GetTdByHash(block.Hash())) != 0 {
+			t.Errorf("block %d: total difficulty mismatch: have %v", i, td)
+		}
+		if !chain.HasBlock(block.Hash(), i) {
+			t.Errorf("block %d: not reported as present", i)
+		}
+	}
+	for _, block := range forks {
+		if have, _ := kvdb.Has(headerKey(block.Hash(), block.NumberU64())); have {
+			t.Errorf("side block %d: header not deleted", block.NumberU64())
+		}
+		if GetBody(db, block.Hash(), block.NumberU64()) != nil {
+			t.Errorf("side block %d: body not deleted", block.NumberU64())
+		}
+	}
+	if GetHeader(db, blocks[9].Hash(), 11) != nil {
+		t.Errorf("ancient data returned for mismatching number")
+	}
+	// Rewind the chain into the frozen segment and check that it's truncated
+	if err := chain.SetHead(20); err != nil {
+		t.Fatalf("failed to rewind chain: %v", err)
+	}
+	if ancients := store.Ancients(); ancients != 21 {
+		t.Fatalf("ancient count mismatch after rewind: have %d, want %d", ancients, 21)
+	}
+	if header := GetHeader(db, blocks[21].Hash(), 22); header != nil {
+		t.Errorf("rewound header still retrievable")
+	}
+	if hash := GetCanonicalHash(db, 20); hash != blocks[19].Hash() {
+		t.Errorf("canonical hash mismatch after rewind: have %x, want %x", hash, blocks[19].Hash())
+	}
+}
+
+// Tests that the leveldb instance backing a freezer database is still reachable
+// through the wrapper.
+func TestFreezerDatabaseLDB(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ancient")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ldb, err := vapdb.NewLDBDatabase(filepath.Join(dir, "chaindata"), 0, 0)
+	if err != nil {
+		t.Fatalf("failed to create leveldb database: %v", err)
+	}
+	db, err := NewDatabaseWithFreezer(ldb, filepath.Join(dir, "ancient"))
+	if err != nil {
+		t.Fatalf("failed to create ancient database: %v", err)
+	}
+	defer db.Close()
+
+	if have := db.(*freezerDatabase).LDB(); have != ldb.LDB() {
+		t.Errorf("leveldb instance mismatch: have %p, want %p", have, ldb.LDB())
+	}
+	mdb, _ := vapdb.NewMemDatabase()
+	mem, err := NewDatabaseWithFreezer(mdb, filepath.Join(dir, "ancient-mem"))
+	if err != nil {
+		t.Fatalf("failed to create ancient database: %v", err)
+	}
+	defer mem.Close()
+
+	if have := mem.(*freezerDatabase).LDB(); have != nil {
+		t.Errorf("leveldb instance reported for memory database: %p", have)
+	}
+}
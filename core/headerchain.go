@@ -367,6 +367,9 @@ func (hc *HeaderChain) HasHeader(hash common.Hash, number uint64) bool {
 		return true
 	}
 	ok, _ := hc.chainDb.Has(headerKey(hash, number))
+	if !ok {
+		ok = hasAncient(hc.chainDb, hash, number)
+	}
 	return ok
 }
 
@@ -0,0 +1,203 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"math/big"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+)
+
+// BalanceDiff is the balance of an account before and after a modification.
+type BalanceDiff struct {
+	From *hexutil.Big `json:"from"`
+	To   *hexutil.Big `json:"to"`
+}
+
+// NonceDiff is the nonce of an account before and after a modification.
+type NonceDiff struct {
+	From hexutil.Uint64 `json:"from"`
+	To   hexutil.Uint64 `json:"to"`
+}
+
+// CodeDiff is the code of an account before and after a modification.
+type CodeDiff struct {
+	From hexutil.Bytes `json:"from"`
+	To   hexutil.Bytes `json:"to"`
+}
+
+// StorageDiff is the value of a storage slot before and after a modification.
+type StorageDiff struct {
+	From common.Hash `json:"from"`
+	To   common.Hash `json:"to"`
+}
+
+// AccountDiff contains the modifications made to a single account. Fields that
+// ended up with their original values are omitted.
+type AccountDiff struct {
+	Created bool                         `json:"created,omitempty"` // Account did not exist before
+	Deleted bool                         `json:"deleted,omitempty"` // Account self destructed
+	Balance *BalanceDiff                 `json:"balance,omitempty"`
+	Nonce   *NonceDiff                   `json:"nonce,omitempty"`
+	Code    *CodeDiff                    `json:"code,omitempty"`
+	Storage map[common.Hash]*StorageDiff `json:"storage,omitempty"`
+}
+
+// Diff is the set of modifications made to the state, keyed by account.
+type Diff map[common.Address]*AccountDiff
+
+// original tracks the values an account held before the modifications recorded
+// in the journal.
+type original struct {
+	created bool
+	balance *big.Int
+	nonce   *uint64
+	code    []byte
+	codeSet bool
+	storage map[common.Hash]common.Hash
+}
+
+// setBalance records the original balance, unless already known.
+func (o *original) setBalance(balance *big.Int) {
+	if o.balance == nil {
+		o.balance = new(big.Int).Set(balance)
+	}
+}
+
+// setNonce records the original nonce, unless already known.
+func (o *original) setNonce(nonce uint64) {
+	if o.nonce == nil {
+		o.nonce = &nonce
+	}
+}
+
+// setCode records the original code, unless already known.
+func (o *original) setCode(code []byte) {
+	if !o.codeSet {
+		o.code, o.codeSet = common.CopyBytes(code), true
+	}
+}
+
+// setState records the original value of a storage slot, unless already known.
+func (o *original) setState(key, value common.Hash) {
+	if _, ok := o.storage[key]; !ok {
+		o.storage[key] = value
+	}
+}
+
+// Diff returns the modifications made to the state since the journal was last
+// cleared, i.e. by the transaction currently being processed. Original values
+// are retrieved from the journal, so reverted changes are never reported.
+//
+// If deleteEmptyObjects is set (EIP-158), accounts created but left empty are
+// omitted, as they are swept away when the transaction is finalised.
+func (self *StateDB) Diff(deleteEmptyObjects bool) Diff {
+	// Gather the original values of everything recorded in the journal
+	originals := make(map[common.Address]*original)
+	lookup := func(addr common.Address) *original {
+		if orig, ok := originals[addr]; ok {
+			return orig
+		}
+		orig := &original{storage: make(map[common.Hash]common.Hash)}
+		originals[addr] = orig
+		return orig
+	}
+	for _, entry := range self.journal {
+		switch entry := entry.(type) {
+		case createObjectChange:
+			orig := lookup(*entry.account)
+			if orig.balance == nil && orig.nonce == nil && !orig.codeSet {
+				orig.created = true
+			}
+			orig.setBalance(new(big.Int))
+			orig.setNonce(0)
+			orig.setCode(nil)
+
+		case resetObjectChange:
+			orig := lookup(entry.prev.address)
+			orig.setBalance(entry.prev.Balance())
+			orig.setNonce(entry.prev.Nonce())
+			orig.setCode(entry.prev.Code(self.db))
+
+		case suicideChange:
+			lookup(*entry.account).setBalance(entry.prevbalance)
+
+		case balanceChange:
+			lookup(*entry.account).setBalance(entry.prev)
+
+		case nonceChange:
+			lookup(*entry.account).setNonce(entry.prev)
+
+		case codeChange:
+			lookup(*entry.account).setCode(entry.prevcode)
+
+		case storageChange:
+			lookup(*entry.account).setState(entry.key, entry.prevalue)
+		}
+	}
+	// Compare the original values against the current ones
+	diff := make(Diff)
+	for addr, orig := range originals {
+		account := &AccountDiff{
+			Created: orig.created,
+			Deleted: self.HasSuicided(addr),
+		}
+		if account.Created && account.Deleted {
+			continue
+		}
+		if account.Created && deleteEmptyObjects {
+			if obj := self.getStateObject(addr); obj == nil || obj.empty() {
+				continue
+			}
+		}
+		var (
+			balance = self.GetBalance(addr)
+			nonce   = self.GetNonce(addr)
+			code    = self.GetCode(addr)
+		)
+		if account.Deleted {
+			balance, nonce, code = new(big.Int), 0, nil
+		}
+		if orig.balance != nil && orig.balance.Cmp(balance) != 0 {
+			account.Balance = &BalanceDiff{From: (*hexutil.Big)(orig.balance), To: (*hexutil.Big)(new(big.Int).Set(balance))}
+		}
+		if orig.nonce != nil && *orig.nonce != nonce {
+			account.Nonce = &NonceDiff{From: hexutil.Uint64(*orig.nonce), To: hexutil.Uint64(nonce)}
+		}
+		if orig.codeSet && !bytes.Equal(orig.code, code) {
+			account.Code = &CodeDiff{From: orig.code, To: common.CopyBytes(code)}
+		}
+		for key, prev := range orig.storage {
+			var value common.Hash
+			if !account.Deleted {
+				value = self.GetState(addr, key)
+			}
+			if value != prev {
+				if account.Storage == nil {
+					account.Storage = make(map[common.Hash]*StorageDiff)
+				}
+				account.Storage[key] = &StorageDiff{From: prev, To: value}
+			}
+		}
+		if account.Created || account.Deleted || account.Balance != nil || account.Nonce != nil || account.Code != nil || account.Storage != nil {
+			diff[addr] = account
+		}
+	}
+	return diff
+}
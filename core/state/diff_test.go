@@ -0,0 +1,111 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/vm"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// Tests that the state diff reports the original and current values of all the
+// modifications since the journal was cleared, ignoring reverted and no-op ones.
+func TestDiff(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	state, _ := New(common.Hash{}, NewDatabase(db))
+
+	var (
+		sender   = common.Address{0x01}
+		contract = common.Address{0x02}
+		created  = common.Address{0x03}
+		doomed   = common.Address{0x04}
+		noop     = common.Address{0x05}
+		reverted = common.Address{0x06}
+		touched  = common.BytesToAddress([]byte{0x01}) // ecrecover precompile
+	)
+	// Assemble and commit an initial state to diff against
+	state.SetBalance(sender, big.NewInt(1000))
+	state.SetNonce(sender, 1)
+	state.SetCode(contract, []byte{0x01})
+	state.SetState(contract, common.Hash{0x01}, common.Hash{0x01})
+	state.SetState(contract, common.Hash{0x02}, common.Hash{0x02})
+	state.SetBalance(doomed, big.NewInt(10))
+	state.SetBalance(noop, big.NewInt(5))
+
+	root, _ := state.Commit(false)
+	state.Reset(root)
+
+	if diff := state.Diff(true); len(diff) != 0 {
+		t.Fatalf("diff of unmodified state: have %v, want none", diff)
+	}
+	// Modify the state and ensure all changes are reported
+	state.SubBalance(sender, big.NewInt(100))
+	state.SetNonce(sender, 2)
+	state.AddBalance(contract, big.NewInt(100))
+	state.SetState(contract, common.Hash{0x01}, common.Hash{0x03})
+	state.SetState(contract, common.Hash{0x02}, common.Hash{0x04})
+	state.SetState(contract, common.Hash{0x02}, common.Hash{0x02})
+	state.CreateAccount(created)
+	state.SetCode(created, []byte{0x02, 0x03})
+	state.SetNonce(created, 1)
+	state.Suicide(doomed)
+	state.AddBalance(noop, big.NewInt(1))
+	state.SubBalance(noop, big.NewInt(1))
+
+	// Call a precompile without an account, which creates an empty one
+	vmctx := vm.Context{
+		CanTransfer: func(vm.StateDB, common.Address, *big.Int) bool { return true },
+		Transfer:    func(vm.StateDB, common.Address, common.Address, *big.Int) {},
+		BlockNumber: new(big.Int),
+	}
+	if _, _, err := vm.NewVVM(vmctx, state, params.AllVapashProtocolChanges, vm.Config{}).Call(vm.AccountRef(sender), touched, nil, 10000, new(big.Int)); err != nil {
+		t.Fatalf("failed to call precompile: %v", err)
+	}
+
+	snapshot := state.Snapshot()
+	state.SetBalance(reverted, big.NewInt(1))
+	state.SetState(contract, common.Hash{0x01}, common.Hash{0x05})
+	state.RevertToSnapshot(snapshot)
+
+	blob, err := json.Marshal(state.Diff(true))
+	if err != nil {
+		t.Fatalf("failed to marshal diff: %v", err)
+	}
+	want := `{` +
+		`"0x0100000000000000000000000000000000000000":{"balance":{"from":"0x3e8","to":"0x384"},"nonce":{"from":"0x1","to":"0x2"}},` +
+		`"0x0200000000000000000000000000000000000000":{"balance":{"from":"0x0","to":"0x64"},"storage":{"0x0100000000000000000000000000000000000000000000000000000000000000":{"from":"0x0100000000000000000000000000000000000000000000000000000000000000","to":"0x0300000000000000000000000000000000000000000000000000000000000000"}}},` +
+		`"0x0300000000000000000000000000000000000000":{"created":true,"nonce":{"from":"0x0","to":"0x1"},"code":{"from":"0x","to":"0x0203"}},` +
+		`"0x0400000000000000000000000000000000000000":{"deleted":true,"balance":{"from":"0xa","to":"0x0"}}` +
+		`}`
+	if string(blob) != want {
+		t.Errorf("diff mismatch:\nhave %s\nwant %s", blob, want)
+	}
+	// Ensure empty accounts are only reported if they survive finalisation
+	if diff := state.Diff(false); diff[touched] == nil || !diff[touched].Created {
+		t.Errorf("pre-EIP158 empty account mismatch: have %v, want created", diff[touched])
+	}
+	// Ensure the diff is reset together with the journal
+	state.Finalise(true)
+	if diff := state.Diff(true); len(diff) != 0 {
+		t.Fatalf("diff of finalised state: have %v, want none", diff)
+	}
+}
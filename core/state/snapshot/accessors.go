@@ -0,0 +1,151 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+var (
+	// AccountPrefix is the database key prefix of the account snapshot entries:
+	// AccountPrefix + account hash -> account trie value
+	AccountPrefix = []byte("a")
+
+	// StoragePrefix is the database key prefix of the storage snapshot entries:
+	// StoragePrefix + account hash + storage hash -> storage trie value
+	StoragePrefix = []byte("o")
+
+	// RootKey tracks the state root of the persisted snapshot.
+	RootKey = []byte("SnapshotRoot")
+
+	// GeneratorKey tracks the progress of the snapshot generation.
+	GeneratorKey = []byte("SnapshotGenerator")
+)
+
+// generatorProgress is the persisted progress of the snapshot generation.
+type generatorProgress struct {
+	Done   bool   // Whether the generation finished
+	Marker []byte // Hash of the last account fully generated
+}
+
+// accountSnapshotKey = AccountPrefix + hash
+func accountSnapshotKey(hash common.Hash) []byte {
+	return append(append([]byte{}, AccountPrefix...), hash.Bytes()...)
+}
+
+// storageSnapshotsKey = StoragePrefix + account hash
+func storageSnapshotsKey(accountHash common.Hash) []byte {
+	return append(append([]byte{}, StoragePrefix...), accountHash.Bytes()...)
+}
+
+// storageSnapshotKey = StoragePrefix + account hash + storage hash
+func storageSnapshotKey(accountHash, storageHash common.Hash) []byte {
+	return append(storageSnapshotsKey(accountHash), storageHash.Bytes()...)
+}
+
+// readAccountSnapshot retrieves the snapshot entry of an account, or nil if the
+// account does not exist.
+func readAccountSnapshot(db vapdb.Database, hash common.Hash) []byte {
+	data, _ := db.Get(accountSnapshotKey(hash))
+	return data
+}
+
+// deleteAccountSnapshot removes the snapshot entry of an account.
+func deleteAccountSnapshot(db vapdb.Database, hash common.Hash) {
+	if err := db.Delete(accountSnapshotKey(hash)); err != nil {
+		log.Crit("Failed to delete account snapshot", "err", err)
+	}
+}
+
+// readStorageSnapshot retrieves the snapshot entry of a storage slot, or nil if
+// the slot is empty.
+func readStorageSnapshot(db vapdb.Database, accountHash, storageHash common.Hash) []byte {
+	data, _ := db.Get(storageSnapshotKey(accountHash, storageHash))
+	return data
+}
+
+// deleteStorageSnapshot removes the snapshot entry of a storage slot.
+func deleteStorageSnapshot(db vapdb.Database, accountHash, storageHash common.Hash) {
+	if err := db.Delete(storageSnapshotKey(accountHash, storageHash)); err != nil {
+		log.Crit("Failed to delete storage snapshot", "err", err)
+	}
+}
+
+// deleteStorageSnapshots removes all the storage snapshot entries of an account.
+func deleteStorageSnapshots(db vapdb.Database, accountHash common.Hash) {
+	deleteRange(db, storageSnapshotsKey(accountHash), len(StoragePrefix)+2*common.HashLength)
+}
+
+// deleteRange removes all the entries with the given key prefix and key length
+// from the database.
+func deleteRange(db vapdb.Database, prefix []byte, keylen int) {
+	it := db.NewIteratorWithPrefix(prefix)
+	defer it.Release()
+
+	for it.Next() {
+		if key := it.Key(); len(key) == keylen {
+			if err := db.Delete(common.CopyBytes(key)); err != nil {
+				log.Crit("Failed to delete snapshot entry", "err", err)
+			}
+		}
+	}
+}
+
+// readSnapshotRoot retrieves the root of the persisted snapshot, or an empty
+// hash if no snapshot is available.
+func readSnapshotRoot(db vapdb.Database) common.Hash {
+	data, _ := db.Get(RootKey)
+	if len(data) != common.HashLength {
+		return common.Hash{}
+	}
+	return common.BytesToHash(data)
+}
+
+// writeSnapshotRoot stores the root of the persisted snapshot.
+func writeSnapshotRoot(db vapdb.Putter, root common.Hash) {
+	if err := db.Put(RootKey, root.Bytes()); err != nil {
+		log.Crit("Failed to store snapshot root", "err", err)
+	}
+}
+
+// readGeneratorProgress retrieves the progress of the snapshot generation.
+func readGeneratorProgress(db vapdb.Database) (*generatorProgress, error) {
+	data, err := db.Get(GeneratorKey)
+	if err != nil {
+		return nil, err
+	}
+	progress := new(generatorProgress)
+	if err := rlp.DecodeBytes(data, progress); err != nil {
+		return nil, err
+	}
+	return progress, nil
+}
+
+// writeGeneratorProgress stores the progress of the snapshot generation, with a
+// nil marker meaning the generation is done.
+func writeGeneratorProgress(db vapdb.Putter, marker []byte) {
+	data, err := rlp.EncodeToBytes(&generatorProgress{Done: marker == nil, Marker: marker})
+	if err != nil {
+		log.Crit("Failed to encode snapshot generator progress", "err", err)
+	}
+	if err := db.Put(GeneratorKey, data); err != nil {
+		log.Crit("Failed to store snapshot generator progress", "err", err)
+	}
+}
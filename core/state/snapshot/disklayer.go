@@ -0,0 +1,142 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/trie"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// diskLayer is a low level persistent snapshot built on top of a key-value store.
+type diskLayer struct {
+	diskdb vapdb.Database // Key-value store containing the base snapshot
+	triedb *trie.Database // Trie node cache for reconstruction purposes
+	root   common.Hash    // Root hash of the base snapshot
+	stale  bool           // Signals that the layer became stale (state progressed)
+
+	genMarker  []byte           // Marker for the state that's indexed during initial layer generation (nil = done)
+	genPending chan struct{}    // Notification channel when generation is done
+	genAbort   chan chan []byte // Notification channel to abort generating the snapshot in this layer
+
+	lock sync.RWMutex
+}
+
+// Root returns root hash for which this snapshot was made.
+func (dl *diskLayer) Root() common.Hash {
+	return dl.root
+}
+
+// Parent always returns nil as there's no layer below the disk.
+func (dl *diskLayer) Parent() snapshot {
+	return nil
+}
+
+// Stale return whether this layer has become stale (was flattened across) or if
+// it's still live.
+func (dl *diskLayer) Stale() bool {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	return dl.stale
+}
+
+// markStale flags the layer as stale, failing any further reads from it.
+func (dl *diskLayer) markStale() {
+	dl.lock.Lock()
+	defer dl.lock.Unlock()
+
+	dl.stale = true
+}
+
+// AccountRLP directly retrieves the account RLP associated with a particular
+// hash in the snapshot slim data format.
+func (dl *diskLayer) AccountRLP(hash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	// If the layer was flattened into, consider it invalid (any live reference to
+	// the original should be marked as unusable).
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	// If the layer is being generated, ensure the requested hash has already been
+	// covered by the generator.
+	if !covered(hash, dl.genMarker) {
+		return nil, ErrNotCoveredYet
+	}
+	return readAccountSnapshot(dl.diskdb, hash), nil
+}
+
+// Storage directly retrieves the storage data associated with a particular hash,
+// within a particular account.
+func (dl *diskLayer) Storage(accountHash, storageHash common.Hash) ([]byte, error) {
+	dl.lock.RLock()
+	defer dl.lock.RUnlock()
+
+	if dl.stale {
+		return nil, ErrSnapshotStale
+	}
+	if !covered(accountHash, dl.genMarker) {
+		return nil, ErrNotCoveredYet
+	}
+	return readStorageSnapshot(dl.diskdb, accountHash, storageHash), nil
+}
+
+// Update creates a new layer on top of the existing snapshot diff tree with
+// the specified data items. Note, the maps are retained by the method to avoid
+// copying everything.
+func (dl *diskLayer) Update(blockHash common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *diffLayer {
+	return newDiffLayer(dl, blockHash, destructs, accounts, storage)
+}
+
+// startGeneration starts generating the snapshot in the background, resuming
+// from the given marker.
+func (dl *diskLayer) startGeneration(marker []byte) {
+	dl.genMarker = marker
+	dl.genPending = make(chan struct{})
+	dl.genAbort = make(chan chan []byte)
+
+	go dl.generate(dl.genPending, dl.genAbort)
+}
+
+// stopGeneration aborts any running background generation of the snapshot,
+// returning the progress marker at which it was stopped (nil = done).
+func (dl *diskLayer) stopGeneration() []byte {
+	if dl.genAbort == nil {
+		return nil
+	}
+	abort := make(chan []byte)
+	select {
+	case dl.genAbort <- abort:
+		marker := <-abort
+		dl.genAbort = nil
+		return marker
+	case <-dl.genPending:
+		dl.genAbort = nil
+		return nil
+	}
+}
+
+// covered returns whether the account with the given hash is already contained
+// in a snapshot whose generation reached the given marker.
+func covered(hash common.Hash, marker []byte) bool {
+	return marker == nil || bytes.Compare(hash[:], marker) <= 0
+}
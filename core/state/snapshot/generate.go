@@ -0,0 +1,200 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/trie"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// emptyRoot is the known root hash of an empty trie.
+var emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
+
+// account is the consensus representation of an account, as stored in the
+// account trie and in the snapshot.
+type account struct {
+	Nonce    uint64
+	Balance  *big.Int
+	Root     common.Hash
+	CodeHash []byte
+}
+
+// loadSnapshot loads a pre-existing state snapshot backed by a key-value store,
+// resuming its generation if it was interrupted.
+func loadSnapshot(diskdb vapdb.Database, triedb *trie.Database, root common.Hash) (*diskLayer, error) {
+	base := readSnapshotRoot(diskdb)
+	if base == (common.Hash{}) {
+		return nil, errors.New("missing or corrupted snapshot")
+	}
+	if base != root {
+		return nil, fmt.Errorf("head doesn't match snapshot: have %#x, want %#x", base, root)
+	}
+	progress, err := readGeneratorProgress(diskdb)
+	if err != nil {
+		return nil, fmt.Errorf("missing or corrupted snapshot generator: %v", err)
+	}
+	dl := &diskLayer{
+		diskdb: diskdb,
+		triedb: triedb,
+		root:   base,
+	}
+	if !progress.Done {
+		log.Info("Resuming state snapshot generation", "root", root, "marker", common.ToHex(progress.Marker))
+		dl.startGeneration(append([]byte{}, progress.Marker...))
+	}
+	return dl, nil
+}
+
+// generateSnapshot wipes any previous snapshot data from the database and starts
+// generating a new snapshot of the given state root in the background.
+func generateSnapshot(diskdb vapdb.Database, triedb *trie.Database, root common.Hash) *diskLayer {
+	if err := diskdb.Delete(RootKey); err != nil {
+		log.Crit("Failed to remove snapshot root", "err", err)
+	}
+	deleteRange(diskdb, AccountPrefix, len(AccountPrefix)+common.HashLength)
+	deleteRange(diskdb, StoragePrefix, len(StoragePrefix)+2*common.HashLength)
+
+	batch := diskdb.NewBatch()
+	writeGeneratorProgress(batch, []byte{})
+	writeSnapshotRoot(batch, root)
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write snapshot", "err", err)
+	}
+	log.Info("Generating state snapshot", "root", root)
+
+	dl := &diskLayer{
+		diskdb: diskdb,
+		triedb: triedb,
+		root:   root,
+	}
+	dl.startGeneration([]byte{})
+	return dl
+}
+
+// generate is a background thread that iterates over the state and storage tries
+// of the disk layer's root, constructing the snapshot of all accounts after the
+// current generation marker. Progress is persisted after every batch, and an
+// abort request is served between accounts.
+//
+// If a trie node is missing (e.g. the state was garbage collected), generation
+// pauses until aborted, to be resumed on a newer disk layer.
+func (dl *diskLayer) generate(pending chan struct{}, abort chan chan []byte) {
+	dl.lock.RLock()
+	marker := dl.genMarker
+	dl.lock.RUnlock()
+
+	var (
+		batch     = dl.diskdb.NewBatch()
+		persisted = marker // Marker of the last batch written to disk
+		accounts  uint64
+		slots     uint64
+		start     = time.Now()
+		logged    = time.Now()
+	)
+	// flush persists the batch along with the marker, exposing it to readers
+	flush := func(marker []byte) {
+		writeGeneratorProgress(batch, marker)
+		if err := batch.Write(); err != nil {
+			log.Crit("Failed to write snapshot", "err", err)
+		}
+		batch.Reset()
+		persisted = marker
+
+		dl.lock.Lock()
+		dl.genMarker = marker
+		dl.lock.Unlock()
+	}
+	// fail discards any partially generated account and waits for an abort
+	fail := func(err error) {
+		log.Warn("State snapshot generation paused", "root", dl.root, "marker", common.ToHex(persisted), "err", err)
+		batch.Reset()
+
+		done := <-abort
+		done <- persisted
+	}
+	accTrie, err := trie.NewSecure(dl.root, dl.triedb, 0)
+	if err != nil {
+		fail(err)
+		return
+	}
+	it := trie.NewIterator(accTrie.NodeIterator(marker))
+	for it.Next() {
+		// Skip the marker account itself, it was already generated
+		if len(marker) > 0 && bytes.Compare(it.Key, marker) <= 0 {
+			continue
+		}
+		accountHash := common.BytesToHash(it.Key)
+
+		var acc account
+		if err := rlp.DecodeBytes(it.Value, &acc); err != nil {
+			log.Crit("Invalid account encountered during snapshot creation", "err", err)
+		}
+		batch.Put(accountSnapshotKey(accountHash), it.Value)
+
+		// If the account has storage, generate that too
+		if acc.Root != emptyRoot {
+			storeTrie, err := trie.NewSecure(acc.Root, dl.triedb, 0)
+			if err != nil {
+				fail(err)
+				return
+			}
+			storeIt := trie.NewIterator(storeTrie.NodeIterator(nil))
+			for storeIt.Next() {
+				batch.Put(storageSnapshotKey(accountHash, common.BytesToHash(storeIt.Key)), storeIt.Value)
+				slots++
+			}
+			if storeIt.Err != nil {
+				fail(storeIt.Err)
+				return
+			}
+		}
+		accounts++
+		marker = accountHash.Bytes()
+
+		if batch.ValueSize() > vapdb.IdealBatchSize {
+			flush(marker)
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Generating state snapshot", "at", accountHash, "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+		// Serve any abort request now that the account is complete
+		select {
+		case done := <-abort:
+			flush(marker)
+			done <- marker
+			return
+		default:
+		}
+	}
+	if it.Err != nil {
+		fail(it.Err)
+		return
+	}
+	flush(nil)
+	log.Info("Generated state snapshot", "accounts", accounts, "slots", slots, "elapsed", common.PrettyDuration(time.Since(start)))
+	close(pending)
+}
@@ -0,0 +1,331 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package snapshot implements a flat view of the Vapory state, keyed by hashed
+// account address and storage slot, allowing state reads without trie traversal.
+package snapshot
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/trie"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+var (
+	// ErrSnapshotStale is returned from data accessors if the underlying snapshot
+	// layer had been invalidated due to the chain progressing forward far enough
+	// to not maintain the layer's original state.
+	ErrSnapshotStale = errors.New("snapshot stale")
+
+	// ErrNotCoveredYet is returned from data accessors if the underlying snapshot
+	// is being generated currently and the requested data item is not yet in the
+	// range of accounts covered.
+	ErrNotCoveredYet = errors.New("not covered yet")
+
+	// errSnapshotCycle is returned if a snapshot is attempted to be inserted
+	// that forms a cycle in the snapshot tree.
+	errSnapshotCycle = errors.New("snapshot cycle")
+)
+
+// Snapshot represents the functionality supported by a snapshot storage layer.
+type Snapshot interface {
+	// Root returns the root hash for which this snapshot was made.
+	Root() common.Hash
+
+	// AccountRLP directly retrieves the account RLP associated with a particular
+	// hash in the snapshot, or nil if the account does not exist.
+	AccountRLP(hash common.Hash) ([]byte, error)
+
+	// Storage directly retrieves the storage data associated with a particular
+	// hash, within a particular account, in the same encoding as the storage trie.
+	Storage(accountHash, storageHash common.Hash) ([]byte, error)
+}
+
+// snapshot is the internal version of the snapshot data layer that supports some
+// additional methods compared to the public API.
+type snapshot interface {
+	Snapshot
+
+	// Parent returns the subsequent layer of a snapshot, or nil if the base was
+	// reached.
+	Parent() snapshot
+
+	// Update creates a new layer on top of the existing snapshot diff tree with
+	// the specified data items.
+	Update(blockRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) *diffLayer
+
+	// Stale return whether this layer has become stale (was flattened across) or
+	// if it's still live.
+	Stale() bool
+}
+
+// Tree is a Vapory state snapshot tree. It consists of one persistent base
+// layer backed by a key-value store, on top of which arbitrarily many in-memory
+// diff layers are topped. The memory diffs can form a tree with branching, but
+// the disk layer is singleton and common to all. If a reorg goes deeper than the
+// disk layer, everything needs to be regenerated.
+//
+// The goal of a state snapshot is twofold: to allow direct access to account and
+// storage data to avoid expensive multi-level trie lookups; and to allow sorted,
+// cheap iteration of the account/storage tries for sync aid.
+type Tree struct {
+	diskdb vapdb.Database           // Persistent database to store the snapshot
+	triedb *trie.Database           // In-memory cache to access the trie through
+	layers map[common.Hash]snapshot // Collection of all known layers
+	lock   sync.RWMutex
+}
+
+// New attempts to load an already existing snapshot from a persistent key-value
+// store, ensuring that the head of the snapshot matches the expected one.
+//
+// If the snapshot is missing, corrupted or stale, it is wiped and regenerated
+// from the state trie in the background. Until the generation finishes, reads
+// of accounts not yet covered return ErrNotCoveredYet.
+func New(diskdb vapdb.Database, triedb *trie.Database, root common.Hash) *Tree {
+	snap := &Tree{
+		diskdb: diskdb,
+		triedb: triedb,
+		layers: make(map[common.Hash]snapshot),
+	}
+	base, err := loadSnapshot(diskdb, triedb, root)
+	if err != nil {
+		log.Warn("Failed to load snapshot, regenerating", "err", err)
+		base = generateSnapshot(diskdb, triedb, root)
+	}
+	snap.layers[base.root] = base
+	return snap
+}
+
+// Snapshot retrieves a snapshot belonging to the given block root, or nil if no
+// snapshot is maintained for that block.
+func (t *Tree) Snapshot(blockRoot common.Hash) Snapshot {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if layer, ok := t.layers[blockRoot]; ok {
+		return layer
+	}
+	return nil
+}
+
+// Update adds a new snapshot into the tree, if that can be linked to an existing
+// old parent. It is disallowed to insert a disk layer (the origin of all).
+func (t *Tree) Update(blockRoot common.Hash, parentRoot common.Hash, destructs map[common.Hash]struct{}, accounts map[common.Hash][]byte, storage map[common.Hash]map[common.Hash][]byte) error {
+	// Reject noop updates to avoid self-loops in the snapshot tree. This is a
+	// special case that can only happen for Clique networks where empty blocks
+	// don't modify the state (0 block subsidy).
+	if blockRoot == parentRoot {
+		return errSnapshotCycle
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	parent, ok := t.layers[parentRoot]
+	if !ok {
+		return fmt.Errorf("parent [%#x] snapshot missing", parentRoot)
+	}
+	t.layers[blockRoot] = parent.Update(blockRoot, destructs, accounts, storage)
+	return nil
+}
+
+// Cap traverses downwards the snapshot tree from a head block hash until the
+// number of allowed layers are crossed. All layers beyond the permitted number
+// are flattened downwards into the disk layer. Any layers on side branches that
+// were based on the flattened ones are discarded.
+func (t *Tree) Cap(root common.Hash, layers int) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	snap, ok := t.layers[root]
+	if !ok {
+		return fmt.Errorf("snapshot [%#x] missing", root)
+	}
+	// Collect the diff layers between the requested head and the disk layer
+	var diffs []*diffLayer
+	for layer := snap; ; {
+		diff, ok := layer.(*diffLayer)
+		if !ok {
+			break
+		}
+		diffs = append(diffs, diff)
+		layer = diff.Parent()
+	}
+	if len(diffs) <= layers {
+		return nil
+	}
+	// Flatten the overflowing diff layers into the disk one by one, oldest first
+	base := diffs[len(diffs)-1].Parent().(*diskLayer)
+	for i := len(diffs) - 1; i >= layers; i-- {
+		base = diffToDisk(diffs[i], base)
+	}
+	if layers > 0 {
+		diffs[layers-1].lock.Lock()
+		diffs[layers-1].parent = base
+		diffs[layers-1].lock.Unlock()
+	}
+	// Remove any layer that is no longer linked to the new disk layer
+	for root, layer := range t.layers {
+		if bottom(layer) != base {
+			if diff, ok := layer.(*diffLayer); ok {
+				diff.markStale()
+			}
+			delete(t.layers, root)
+		}
+	}
+	t.layers[base.root] = base
+	return nil
+}
+
+// Rebuild wipes all available snapshot data from the persistent database and
+// discards all diff layers, then starts regenerating the snapshot of the given
+// state root in the background.
+func (t *Tree) Rebuild(root common.Hash) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		switch layer := layer.(type) {
+		case *diskLayer:
+			layer.stopGeneration()
+			layer.markStale()
+		case *diffLayer:
+			layer.markStale()
+		}
+	}
+	base := generateSnapshot(t.diskdb, t.triedb, root)
+	t.layers = map[common.Hash]snapshot{root: base}
+}
+
+// Close stops any background snapshot generation, persisting its progress so
+// that it can be resumed when the snapshot is next loaded.
+func (t *Tree) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, layer := range t.layers {
+		if disk, ok := layer.(*diskLayer); ok {
+			disk.stopGeneration()
+		}
+	}
+}
+
+// bottom returns the disk layer the given snapshot layer is based on, or nil if
+// it was linked to a stale one.
+func bottom(layer snapshot) *diskLayer {
+	for {
+		if layer.Stale() {
+			return nil
+		}
+		parent := layer.Parent()
+		if parent == nil {
+			return layer.(*diskLayer)
+		}
+		layer = parent
+	}
+}
+
+// diffToDisk merges a bottom-most diff into the persistent disk layer underneath
+// it. The method will panic if called onto a non-bottom-most diff layer.
+func diffToDisk(bottom *diffLayer, base *diskLayer) *diskLayer {
+	// Hold any running generation while the diff is being persisted
+	marker := base.stopGeneration()
+
+	base.lock.Lock()
+	if base.stale {
+		panic("parent disk layer is stale") // we've committed into the same base from two children, boo
+	}
+	base.stale = true
+	base.lock.Unlock()
+
+	bottom.lock.Lock()
+	defer bottom.lock.Unlock()
+
+	// Invalidate the persisted snapshot while it's being updated, so that a crash
+	// in between results in a regeneration instead of a corrupted snapshot
+	db := base.diskdb
+	if err := db.Delete(RootKey); err != nil {
+		log.Crit("Failed to remove snapshot root", "err", err)
+	}
+	batch := db.NewBatch()
+	flush := func() {
+		if batch.ValueSize() > vapdb.IdealBatchSize {
+			if err := batch.Write(); err != nil {
+				log.Crit("Failed to write snapshot", "err", err)
+			}
+			batch.Reset()
+		}
+	}
+	// Destroy all the destructed accounts from the database, along with their
+	// storage. Anything not yet covered by generation is skipped, the generator
+	// will pick up the new state for them.
+	for hash := range bottom.destructSet {
+		if !covered(hash, marker) {
+			continue
+		}
+		deleteAccountSnapshot(db, hash)
+		deleteStorageSnapshots(db, hash)
+	}
+	// Push all updated accounts and storage slots into the database
+	for hash, data := range bottom.accountData {
+		if !covered(hash, marker) {
+			continue
+		}
+		if len(data) == 0 {
+			deleteAccountSnapshot(db, hash)
+			continue
+		}
+		batch.Put(accountSnapshotKey(hash), data)
+		flush()
+	}
+	for accountHash, storage := range bottom.storageData {
+		if !covered(accountHash, marker) {
+			continue
+		}
+		for storageHash, data := range storage {
+			if len(data) == 0 {
+				deleteStorageSnapshot(db, accountHash, storageHash)
+				continue
+			}
+			batch.Put(storageSnapshotKey(accountHash, storageHash), data)
+			flush()
+		}
+	}
+	// Update the snapshot block marker and the generation progress
+	writeSnapshotRoot(batch, bottom.root)
+	if marker != nil {
+		writeGeneratorProgress(batch, marker)
+	}
+	if err := batch.Write(); err != nil {
+		log.Crit("Failed to write snapshot", "err", err)
+	}
+	bottom.stale = true
+
+	res := &diskLayer{
+		diskdb: base.diskdb,
+		triedb: base.triedb,
+		root:   bottom.root,
+	}
+	// If the snapshot was still being generated, resume on the new state
+	if marker != nil {
+		res.startGeneration(marker)
+	}
+	return res
+}
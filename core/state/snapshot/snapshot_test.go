@@ -0,0 +1,251 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package snapshot
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/trie"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// testState is a state trie committed to disk, along with the flat account and
+// storage data expected in its snapshot.
+type testState struct {
+	diskdb   *vapdb.MemDatabase
+	triedb   *trie.Database
+	root     common.Hash
+	accounts map[common.Hash][]byte
+	storage  map[common.Hash]map[common.Hash][]byte
+}
+
+// newTestState creates a state of a number of accounts, every third of them
+// having a few storage slots too.
+func newTestState(t *testing.T, accounts int) *testState {
+	diskdb, _ := vapdb.NewMemDatabase()
+	state := &testState{
+		diskdb:   diskdb,
+		triedb:   trie.NewDatabase(diskdb),
+		accounts: make(map[common.Hash][]byte),
+		storage:  make(map[common.Hash]map[common.Hash][]byte),
+	}
+	accTrie, _ := trie.NewSecure(common.Hash{}, state.triedb, 0)
+	for i := 0; i < accounts; i++ {
+		addr := common.BytesToAddress([]byte{byte(i >> 8), byte(i)})
+		hash := crypto.Keccak256Hash(addr[:])
+
+		acc := &account{Nonce: uint64(i), Balance: big.NewInt(int64(i)), Root: emptyRoot, CodeHash: crypto.Keccak256(nil)}
+		if i%3 == 0 {
+			stTrie, _ := trie.NewSecure(common.Hash{}, state.triedb, 0)
+			state.storage[hash] = make(map[common.Hash][]byte)
+			for j := 1; j <= i%7+1; j++ {
+				key := common.BigToHash(big.NewInt(int64(j)))
+				val, _ := rlp.EncodeToBytes(big.NewInt(int64(i*j + 1)).Bytes())
+				stTrie.Update(key[:], val)
+				state.storage[hash][crypto.Keccak256Hash(key[:])] = val
+			}
+			acc.Root, _ = stTrie.Commit(nil)
+			if err := state.triedb.Commit(acc.Root, false); err != nil {
+				t.Fatalf("failed to commit storage trie: %v", err)
+			}
+		}
+		enc, _ := rlp.EncodeToBytes(acc)
+		accTrie.Update(addr[:], enc)
+		state.accounts[hash] = enc
+	}
+	state.root, _ = accTrie.Commit(nil)
+	if err := state.triedb.Commit(state.root, false); err != nil {
+		t.Fatalf("failed to commit account trie: %v", err)
+	}
+	return state
+}
+
+// waitGeneration waits until the disk layer of the tree finishes generating.
+func waitGeneration(t *testing.T, tree *Tree, root common.Hash) {
+	tree.lock.RLock()
+	disk := tree.layers[root].(*diskLayer)
+	tree.lock.RUnlock()
+
+	if disk.genPending == nil {
+		return
+	}
+	select {
+	case <-disk.genPending:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("snapshot generation timed out")
+	}
+}
+
+// verify checks that the snapshot contains exactly the expected state.
+func (s *testState) verify(t *testing.T, snap Snapshot) {
+	for hash, data := range s.accounts {
+		blob, err := snap.AccountRLP(hash)
+		if err != nil {
+			t.Fatalf("account %x: failed to retrieve: %v", hash, err)
+		}
+		if !bytes.Equal(blob, data) {
+			t.Errorf("account %x: data mismatch: have %x, want %x", hash, blob, data)
+		}
+	}
+	for accHash, storage := range s.storage {
+		for hash, data := range storage {
+			blob, err := snap.Storage(accHash, hash)
+			if err != nil {
+				t.Fatalf("slot %x:%x: failed to retrieve: %v", accHash, hash, err)
+			}
+			if !bytes.Equal(blob, data) {
+				t.Errorf("slot %x:%x: data mismatch: have %x, want %x", accHash, hash, blob, data)
+			}
+		}
+	}
+	if blob, err := snap.AccountRLP(common.Hash{0xff}); err != nil || blob != nil {
+		t.Errorf("missing account: have %x (%v), want nil", blob, err)
+	}
+}
+
+// Tests that a snapshot is generated from the state trie if it's missing.
+func TestGeneration(t *testing.T) {
+	state := newTestState(t, 100)
+
+	tree := New(state.diskdb, state.triedb, state.root)
+	waitGeneration(t, tree, state.root)
+	state.verify(t, tree.Snapshot(state.root))
+
+	if root := readSnapshotRoot(state.diskdb); root != state.root {
+		t.Errorf("persisted root mismatch: have %x, want %x", root, state.root)
+	}
+	if progress, err := readGeneratorProgress(state.diskdb); err != nil || !progress.Done {
+		t.Errorf("generation not marked done: %v, %v", progress, err)
+	}
+}
+
+// Tests that an interrupted snapshot generation is resumed from its marker on
+// the next load.
+func TestGenerationResume(t *testing.T) {
+	state := newTestState(t, 100)
+
+	tree := New(state.diskdb, state.triedb, state.root)
+	waitGeneration(t, tree, state.root)
+
+	// Delete every entry beyond a marker, simulating an interrupted generation
+	marker := common.Hash{0x80}
+	for _, key := range state.diskdb.Keys() {
+		isAccount := bytes.HasPrefix(key, AccountPrefix) && len(key) == 1+common.HashLength
+		isStorage := bytes.HasPrefix(key, StoragePrefix) && len(key) == 1+2*common.HashLength
+		if (isAccount || isStorage) && bytes.Compare(key[1:1+common.HashLength], marker[:]) > 0 {
+			state.diskdb.Delete(key)
+		}
+	}
+	writeGeneratorProgress(state.diskdb, marker[:])
+
+	tree = New(state.diskdb, state.triedb, state.root)
+	if _, err := tree.Snapshot(state.root).AccountRLP(common.Hash{0xff}); err != ErrNotCoveredYet && err != nil {
+		t.Fatalf("unexpected error for uncovered account: %v", err)
+	}
+	waitGeneration(t, tree, state.root)
+	state.verify(t, tree.Snapshot(state.root))
+}
+
+// Tests that diff layers are resolved top down and that capping the tree
+// flattens them into the disk layer, discarding the stale layers.
+func TestDiffLayersCap(t *testing.T) {
+	state := newTestState(t, 30)
+
+	tree := New(state.diskdb, state.triedb, state.root)
+	waitGeneration(t, tree, state.root)
+
+	var (
+		destructed = crypto.Keccak256Hash(common.BytesToAddress([]byte{0, 0}).Bytes()) // has storage
+		modified   = crypto.Keccak256Hash(common.BytesToAddress([]byte{0, 1}).Bytes())
+		created    = common.Hash{0xee}
+		slot       = common.Hash{0x01}
+	)
+	// Create a chain of two diff layers and a side branch
+	if err := tree.Update(common.Hash{0x02}, state.root, map[common.Hash]struct{}{destructed: {}}, map[common.Hash][]byte{modified: {0x01}}, nil); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if err := tree.Update(common.Hash{0x03}, common.Hash{0x02}, nil, map[common.Hash][]byte{created: {0x02}}, map[common.Hash]map[common.Hash][]byte{created: {slot: {0x03}}}); err != nil {
+		t.Fatalf("failed to create diff layer: %v", err)
+	}
+	if err := tree.Update(common.Hash{0x12}, state.root, nil, map[common.Hash][]byte{modified: {0x04}}, nil); err != nil {
+		t.Fatalf("failed to create side diff layer: %v", err)
+	}
+	if err := tree.Update(common.Hash{0x04}, common.Hash{0x05}, nil, nil, nil); err == nil {
+		t.Fatalf("diff layer created on missing parent")
+	}
+	check := func(snap Snapshot, hash common.Hash, want []byte) {
+		if blob, err := snap.AccountRLP(hash); err != nil || !bytes.Equal(blob, want) {
+			t.Errorf("root %x account %x: have %x (%v), want %x", snap.Root(), hash, blob, err, want)
+		}
+	}
+	head, side := tree.Snapshot(common.Hash{0x03}), tree.Snapshot(common.Hash{0x12})
+	check(head, destructed, nil)
+	check(head, modified, []byte{0x01})
+	check(head, created, []byte{0x02})
+	check(side, destructed, state.accounts[destructed])
+	check(side, modified, []byte{0x04})
+
+	for hash := range state.storage[destructed] {
+		if blob, err := head.Storage(destructed, hash); err != nil || blob != nil {
+			t.Errorf("destructed slot %x: have %x (%v), want nil", hash, blob, err)
+		}
+	}
+	// Flatten the bottom diff layer and check that the side branch got dropped
+	if err := tree.Cap(common.Hash{0x03}, 1); err != nil {
+		t.Fatalf("failed to cap tree: %v", err)
+	}
+	if tree.Snapshot(state.root) != nil || tree.Snapshot(common.Hash{0x12}) != nil {
+		t.Errorf("stale layers retained")
+	}
+	if _, err := side.AccountRLP(modified); err != ErrSnapshotStale {
+		t.Errorf("stale layer readable: %v", err)
+	}
+	check(head, modified, []byte{0x01})
+	check(head, created, []byte{0x02})
+	if blob := readAccountSnapshot(state.diskdb, modified); !bytes.Equal(blob, []byte{0x01}) {
+		t.Errorf("flattened account mismatch: have %x, want %x", blob, []byte{0x01})
+	}
+	for hash := range state.storage[destructed] {
+		if blob := readStorageSnapshot(state.diskdb, destructed, hash); blob != nil {
+			t.Errorf("destructed slot %x persisted: %x", hash, blob)
+		}
+	}
+	// Flatten everything and check the persisted state
+	if err := tree.Cap(common.Hash{0x03}, 0); err != nil {
+		t.Fatalf("failed to cap tree: %v", err)
+	}
+	if root := readSnapshotRoot(state.diskdb); root != (common.Hash{0x03}) {
+		t.Errorf("persisted root mismatch: have %x, want %x", root, common.Hash{0x03})
+	}
+	if blob := readStorageSnapshot(state.diskdb, created, slot); !bytes.Equal(blob, []byte{0x03}) {
+		t.Errorf("flattened slot mismatch: have %x, want %x", blob, []byte{0x03})
+	}
+	// Loading the snapshot for a different root should regenerate it
+	tree = New(state.diskdb, state.triedb, state.root)
+	waitGeneration(t, tree, state.root)
+	state.verify(t, tree.Snapshot(state.root))
+
+	if blob := readAccountSnapshot(state.diskdb, created); blob != nil {
+		t.Errorf("stale account retained after regeneration: %x", blob)
+	}
+}
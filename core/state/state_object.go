@@ -25,7 +25,6 @@ import (
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/crypto"
 	"github.com/vaporyco/go-vapory/rlp"
-	"github.com/vaporyco/go-vapory/trie"
 )
 
 var emptyCodeHash = crypto.Keccak256(nil)
@@ -88,6 +87,7 @@ type stateObject struct {
 	suicided  bool
 	touched   bool
 	deleted   bool
+	persisted bool                      // true if the object was loaded from the database
 	onDirty   func(addr common.Address) // Callback method to mark a state object newly dirty
 }
 
@@ -176,7 +176,38 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		return value
 	}
 	// Load from DB in case it is missing.
-	enc, err := self.getTrie(db).TryGet(key[:])
+	value = self.loadState(db, key)
+	if (value != common.Hash{}) {
+		self.cachedStorage[key] = value
+	}
+	return value
+}
+
+// GetCommittedState returns the value of a storage slot as it was at the start
+// of the current transaction, ignoring any modifications made since.
+func (self *stateObject) GetCommittedState(db Database, key common.Hash) common.Hash {
+	if _, dirty := self.dirtyStorage[key]; !dirty {
+		return self.GetState(db, key)
+	}
+	return self.loadState(db, key)
+}
+
+// loadState retrieves a storage slot directly from the account's storage trie.
+func (self *stateObject) loadState(db Database, key common.Hash) common.Hash {
+	var (
+		value common.Hash
+		enc   []byte
+		err   error
+	)
+	// Serve the slot from the snapshot if it still reflects the storage trie,
+	// falling back to the trie otherwise.
+	snap := self.snapshotted(key)
+	if snap {
+		enc, err = self.db.snap.Storage(self.addrHash, crypto.Keccak256Hash(key[:]))
+	}
+	if !snap || err != nil {
+		enc, err = self.getTrie(db).TryGet(key[:])
+	}
 	if err != nil {
 		self.setError(err)
 		return common.Hash{}
@@ -188,12 +219,27 @@ func (self *stateObject) GetState(db Database, key common.Hash) common.Hash {
 		}
 		value.SetBytes(content)
 	}
-	if (value != common.Hash{}) {
-		self.cachedStorage[key] = value
-	}
 	return value
 }
 
+// snapshotted returns whether the given storage slot can be read from the state
+// snapshot, i.e. the account was loaded from the database and the slot was not
+// written to the storage trie since.
+func (self *stateObject) snapshotted(key common.Hash) bool {
+	if self.db.snap == nil || !self.persisted {
+		return false
+	}
+	if _, destructed := self.db.snapDestructs[self.addrHash]; destructed {
+		return false
+	}
+	if storage, ok := self.db.snapStorage[self.addrHash]; ok {
+		if _, written := storage[crypto.Keccak256Hash(key[:])]; written {
+			return false
+		}
+	}
+	return true
+}
+
 // SetState updates a value in account storage.
 func (self *stateObject) SetState(db Database, key, value common.Hash) {
 	self.db.journal = append(self.db.journal, storageChange{
@@ -219,13 +265,24 @@ func (self *stateObject) updateTrie(db Database) Trie {
 	tr := self.getTrie(db)
 	for key, value := range self.dirtyStorage {
 		delete(self.dirtyStorage, key)
+
+		var v []byte
 		if (value == common.Hash{}) {
 			self.setError(tr.TryDelete(key[:]))
-			continue
+		} else {
+			// Encoding []byte cannot fail, ok to ignore the error.
+			v, _ = rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
+			self.setError(tr.TryUpdate(key[:], v))
+		}
+		// Track the change for the snapshot too
+		if self.db.snap != nil {
+			storage := self.db.snapStorage[self.addrHash]
+			if storage == nil {
+				storage = make(map[common.Hash][]byte)
+				self.db.snapStorage[self.addrHash] = storage
+			}
+			storage[crypto.Keccak256Hash(key[:])] = v
 		}
-		// Encoding []byte cannot fail, ok to ignore the error.
-		v, _ := rlp.EncodeToBytes(bytes.TrimLeft(value[:], "\x00"))
-		self.setError(tr.TryUpdate(key[:], v))
 	}
 	return tr
 }
@@ -236,14 +293,14 @@ func (self *stateObject) updateRoot(db Database) {
 	self.data.Root = self.trie.Hash()
 }
 
-// CommitTrie the storage trie of the object to dwb.
+// CommitTrie the storage trie of the object to db.
 // This updates the trie root.
-func (self *stateObject) CommitTrie(db Database, dbw trie.DatabaseWriter) error {
+func (self *stateObject) CommitTrie(db Database) error {
 	self.updateTrie(db)
 	if self.dbErr != nil {
 		return self.dbErr
 	}
-	root, err := self.trie.CommitTo(dbw)
+	root, err := self.trie.Commit(nil)
 	if err == nil {
 		self.data.Root = root
 	}
@@ -304,6 +361,7 @@ func (self *stateObject) deepCopy(db *StateDB, onDirty func(addr common.Address)
 	stateObject.suicided = self.suicided
 	stateObject.dirtyCode = self.dirtyCode
 	stateObject.deleted = self.deleted
+	stateObject.persisted = self.persisted
 	return stateObject
 }
 
@@ -97,12 +97,18 @@ func TestIntermediateLeaks(t *testing.T) {
 	}
 
 	// Commit and cross check the databases.
-	if _, err := transState.CommitTo(transDb, false); err != nil {
+	if _, err := transState.Commit(false); err != nil {
 		t.Fatalf("failed to commit transition state: %v", err)
 	}
-	if _, err := finalState.CommitTo(finalDb, false); err != nil {
+	if err := transState.Database().TrieDB().Commit(transState.IntermediateRoot(false), false); err != nil {
+		t.Errorf("can not commit trie %v to persistent database", transState.IntermediateRoot(false).Hex())
+	}
+	if _, err := finalState.Commit(false); err != nil {
 		t.Fatalf("failed to commit final state: %v", err)
 	}
+	if err := finalState.Database().TrieDB().Commit(finalState.IntermediateRoot(false), false); err != nil {
+		t.Errorf("can not commit trie %v to persistent database", finalState.IntermediateRoot(false).Hex())
+	}
 	for _, key := range finalDb.Keys() {
 		if _, err := transDb.Get(key); err != nil {
 			val, _ := finalDb.Get(key)
@@ -409,7 +415,7 @@ func (test *snapshotTest) checkEqual(state, checkstate *StateDB) error {
 
 func (s *StateSuite) TestTouchDelete(c *check.C) {
 	s.state.GetOrNewStateObject(common.Address{})
-	root, _ := s.state.CommitTo(s.db, false)
+	root, _ := s.state.Commit(false)
 	s.state.Reset(root)
 
 	snapshot := s.state.Snapshot()
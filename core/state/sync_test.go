@@ -61,7 +61,8 @@ func makeTestState() (Database, *vapdb.MemDatabase, common.Hash, []*testAccount)
 		state.updateStateObject(obj)
 		accounts = append(accounts, acc)
 	}
-	root, _ := state.CommitTo(mem, false)
+	root, _ := state.Commit(false)
+	db.TrieDB().Commit(root, false)
 
 	// Return the generated state
 	return db, mem, root, accounts
@@ -96,7 +97,7 @@ func checkTrieConsistency(db vapdb.Database, root common.Hash) error {
 	if v, _ := db.Get(root[:]); v == nil {
 		return nil // Consider a non existent state consistent.
 	}
-	trie, err := trie.New(root, db)
+	trie, err := trie.New(root, trie.NewDatabase(db))
 	if err != nil {
 		return err
 	}
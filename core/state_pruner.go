@@ -0,0 +1,235 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/state"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// pruningCheckpoint is the progress marker of a state pruning run, persisted so
+// that an interrupted run can be resumed with the exact same retention set.
+type pruningCheckpoint struct {
+	Roots  []common.Hash // State roots whose tries are retained
+	Marker []byte        // Database key the deletion sweep last reached
+}
+
+// stateBloom is a bloom filter over the hashes of the retained trie nodes and
+// contract codes. Holding an exact set of them is not feasible for archive sized
+// databases; a false positive only leaves a few stale entries in the database.
+//
+// The keys are hashes, so the bit indexes are taken straight from their bytes.
+type stateBloom []uint64
+
+// newStateBloom creates a bloom filter of the given size in bytes.
+func newStateBloom(size uint64) stateBloom {
+	if size < 8 {
+		size = 8
+	}
+	return make(stateBloom, size/8)
+}
+
+// add inserts a hash into the filter, reporting whether it was (possibly)
+// contained already.
+func (b stateBloom) add(hash common.Hash) bool {
+	contained := true
+	for i := 0; i < common.HashLength; i += 8 {
+		bit := binary.BigEndian.Uint64(hash[i:]) % (uint64(len(b)) * 64)
+		if b[bit/64]&(1<<(bit%64)) == 0 {
+			b[bit/64] |= 1 << (bit % 64)
+			contained = false
+		}
+	}
+	return contained
+}
+
+// contains reports whether a hash may have been inserted into the filter.
+func (b stateBloom) contains(hash common.Hash) bool {
+	for i := 0; i < common.HashLength; i += 8 {
+		bit := binary.BigEndian.Uint64(hash[i:]) % (uint64(len(b)) * 64)
+		if b[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// PruneStats contains the outcome of a state pruning run.
+type PruneStats struct {
+	Retained common.StorageSize // Total size of the trie nodes and code retained
+	Marked   uint64             // Number of trie nodes and contract codes retained (approximate)
+	Pruned   uint64             // Number of trie nodes and contract codes deleted (or deletable)
+	Size     common.StorageSize // Storage reclaimed (or reclaimable) by pruning
+}
+
+// getPruningCheckpoint retrieves the progress of an interrupted pruning run, or
+// nil if no pruning was in progress.
+func getPruningCheckpoint(db DatabaseReader) *pruningCheckpoint {
+	data, _ := db.Get(pruningCheckpointKey)
+	if len(data) == 0 {
+		return nil
+	}
+	checkpoint := new(pruningCheckpoint)
+	if err := rlp.DecodeBytes(data, checkpoint); err != nil {
+		log.Error("Invalid pruning checkpoint", "err", err)
+		return nil
+	}
+	return checkpoint
+}
+
+// writePruningCheckpoint stores the progress of a pruning run.
+func writePruningCheckpoint(db vapdb.Putter, checkpoint *pruningCheckpoint) error {
+	data, err := rlp.EncodeToBytes(checkpoint)
+	if err != nil {
+		return err
+	}
+	return db.Put(pruningCheckpointKey, data)
+}
+
+// PruneState deletes every trie node and contract code from the database which
+// is not reachable from any of the given state roots or from the genesis state.
+// The first root is required to reference a complete state, the others are
+// retained on a best effort basis, skipping any that are already missing.
+//
+// The retained entries are tracked in a bloom filter of bloomSize bytes, so a
+// small fraction of the stale entries may survive, depending on its size.
+//
+// If dryRun is set, nothing is deleted, only the reclaimable storage reported.
+// Otherwise progress is checkpointed into the database, and a subsequent call
+// after an interruption resumes the previous run, ignoring the roots given.
+func PruneState(db vapdb.Database, roots []common.Hash, bloomSize uint64, dryRun bool) (*PruneStats, error) {
+	var marker []byte
+	if checkpoint := getPruningCheckpoint(db); checkpoint != nil {
+		log.Info("Resuming interrupted state pruning", "roots", len(checkpoint.Roots), "marker", common.ToHex(checkpoint.Marker))
+		roots, marker = checkpoint.Roots, checkpoint.Marker
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no state roots to retain")
+	}
+	// Mark all the trie nodes and contract codes reachable from the retained states
+	var (
+		stats  = new(PruneStats)
+		start  = time.Now()
+		logged = time.Now()
+		marked = newStateBloom(bloomSize)
+		seen   = make(map[common.Hash]bool)
+		sdb    = state.NewDatabase(db)
+	)
+	retain := roots
+	if genesis := GetCanonicalHash(db, 0); genesis != (common.Hash{}) {
+		if header := GetHeader(db, genesis, 0); header != nil {
+			retain = append(append([]common.Hash{}, roots...), header.Root)
+		}
+	}
+	for i, root := range retain {
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+
+		statedb, err := state.New(root, sdb)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("head state %x unavailable: %v", root, err)
+			}
+			log.Warn("Retained state missing, skipping", "root", root)
+			continue
+		}
+		it := state.NewNodeIterator(statedb)
+		for it.Next() {
+			if it.Hash == (common.Hash{}) {
+				continue
+			}
+			if !marked.add(it.Hash) {
+				stats.Marked++
+			}
+			if time.Since(logged) > 8*time.Second {
+				log.Info("Marking retained state", "roots", i, "nodes", stats.Marked, "elapsed", common.PrettyDuration(time.Since(start)))
+				logged = time.Now()
+			}
+		}
+		if it.Error != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("head state %x incomplete: %v", root, it.Error)
+			}
+			log.Warn("Retained state incomplete", "root", root, "err", it.Error)
+		}
+	}
+	log.Info("Marked retained state", "roots", len(retain), "nodes", stats.Marked, "elapsed", common.PrettyDuration(time.Since(start)))
+
+	// Sweep the database, deleting (or counting) all unmarked trie nodes and codes
+	if !dryRun {
+		if err := writePruningCheckpoint(db, &pruningCheckpoint{Roots: roots, Marker: marker}); err != nil {
+			return nil, err
+		}
+	}
+	it := db.NewIteratorWithStart(marker)
+	defer it.Release()
+
+	batch := db.NewBatch() // Deletions since the last checkpoint, flushed together with it
+	for it.Next() {
+		key := it.Key()
+		if len(key) != common.HashLength {
+			continue
+		}
+		size := common.StorageSize(len(key) + len(it.Value()))
+		if marked.contains(common.BytesToHash(key)) {
+			stats.Retained += size
+			continue
+		}
+		stats.Pruned++
+		stats.Size += size
+
+		if !dryRun {
+			if err := batch.Delete(key); err != nil {
+				return nil, err
+			}
+			if batch.ValueSize() > vapdb.IdealBatchSize {
+				if err := writePruningCheckpoint(batch, &pruningCheckpoint{Roots: roots, Marker: common.CopyBytes(key)}); err != nil {
+					return nil, err
+				}
+				if err := batch.Write(); err != nil {
+					return nil, err
+				}
+				batch.Reset()
+			}
+		}
+		if time.Since(logged) > 8*time.Second {
+			log.Info("Pruning state data", "nodes", stats.Pruned, "size", stats.Size, "elapsed", common.PrettyDuration(time.Since(start)))
+			logged = time.Now()
+		}
+	}
+	if err := it.Error(); err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		if err := batch.Delete(pruningCheckpointKey); err != nil {
+			return nil, err
+		}
+		if err := batch.Write(); err != nil {
+			return nil, err
+		}
+	}
+	return stats, nil
+}
@@ -0,0 +1,128 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"testing"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/consensus/vapash"
+	"github.com/vaporyco/go-vapory/core/state"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/core/vm"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
+
+// newPruningTestChain creates an archive database containing the state of every
+// block of a short chain, each block crediting a different coinbase.
+func newPruningTestChain(t *testing.T) (*vapdb.MemDatabase, []*types.Block) {
+	engine := vapash.NewFaker()
+
+	db, _ := vapdb.NewMemDatabase()
+	genesis := new(Genesis).MustCommit(db)
+	blocks, _ := GenerateChain(params.TestChainConfig, genesis, engine, db, 8, func(i int, b *BlockGen) { b.SetCoinbase(common.Address{byte(i + 1)}) })
+
+	diskdb, _ := vapdb.NewMemDatabase()
+	new(Genesis).MustCommit(diskdb)
+
+	chain, err := NewBlockChain(diskdb, &CacheConfig{Disabled: true}, params.TestChainConfig, engine, vm.Config{})
+	if err != nil {
+		t.Fatalf("failed to create tester chain: %v", err)
+	}
+	if _, err := chain.InsertChain(blocks); err != nil {
+		t.Fatalf("failed to insert into chain: %v", err)
+	}
+	chain.Stop()
+
+	return diskdb, blocks
+}
+
+// verifyState checks whether the state of the given root is complete in the db.
+func verifyState(db vapdb.Database, root common.Hash) error {
+	statedb, err := state.New(root, state.NewDatabase(db))
+	if err != nil {
+		return err
+	}
+	it := state.NewNodeIterator(statedb)
+	for it.Next() {
+	}
+	return it.Error
+}
+
+// Tests that state pruning retains the requested states and deletes the rest.
+func TestPruneState(t *testing.T) {
+	db, blocks := newPruningTestChain(t)
+	roots := []common.Hash{blocks[7].Root(), blocks[6].Root()}
+
+	// Run a dry-run first and ensure nothing is deleted
+	before := len(db.Keys())
+	dry, err := PruneState(db, roots, 1024*1024, true)
+	if err != nil {
+		t.Fatalf("failed to dry-run pruning: %v", err)
+	}
+	if dry.Pruned == 0 {
+		t.Fatalf("dry-run found nothing to prune")
+	}
+	if after := len(db.Keys()); after != before {
+		t.Fatalf("dry-run deleted data: have %d keys, want %d", after, before)
+	}
+	// Prune for real and check that exactly the retained states remain
+	stats, err := PruneState(db, roots, 1024*1024, false)
+	if err != nil {
+		t.Fatalf("failed to prune state: %v", err)
+	}
+	if stats.Pruned != dry.Pruned || stats.Size != dry.Size {
+		t.Errorf("pruned data mismatch: have %d/%v, want %d/%v", stats.Pruned, stats.Size, dry.Pruned, dry.Size)
+	}
+	for _, root := range append(roots, GetBlock(db, GetCanonicalHash(db, 0), 0).Root()) {
+		if err := verifyState(db, root); err != nil {
+			t.Errorf("retained state %x damaged: %v", root, err)
+		}
+	}
+	for i := 0; i < 6; i++ {
+		if _, err := state.New(blocks[i].Root(), state.NewDatabase(db)); err == nil {
+			t.Errorf("block %d: stale state not pruned", i)
+		}
+	}
+	if getPruningCheckpoint(db) != nil {
+		t.Errorf("pruning checkpoint not cleaned up")
+	}
+}
+
+// Tests that an interrupted state pruning is resumed with the original roots.
+func TestPruneStateResume(t *testing.T) {
+	db, blocks := newPruningTestChain(t)
+
+	// Simulate an interrupted run that was retaining the last two states
+	roots := []common.Hash{blocks[7].Root(), blocks[6].Root()}
+	if err := writePruningCheckpoint(db, &pruningCheckpoint{Roots: roots}); err != nil {
+		t.Fatalf("failed to write checkpoint: %v", err)
+	}
+	// Restart pruning with only the head state, the checkpoint should prevail
+	if _, err := PruneState(db, roots[:1], 1024*1024, false); err != nil {
+		t.Fatalf("failed to resume pruning: %v", err)
+	}
+	for _, root := range roots {
+		if err := verifyState(db, root); err != nil {
+			t.Errorf("retained state %x damaged: %v", root, err)
+		}
+	}
+	if getPruningCheckpoint(db) != nil {
+		t.Errorf("pruning checkpoint not cleaned up")
+	}
+}
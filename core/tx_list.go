@@ -363,6 +363,30 @@ func (l *txList) Flatten() types.Transactions {
 	return l.txs.Flatten()
 }
 
+// NonceGap is an inclusive range of nonces missing from an account's transactions,
+// preventing all subsequent ones from becoming executable.
+type NonceGap struct {
+	From uint64 // First missing nonce of the gap
+	To   uint64 // Last missing nonce of the gap
+}
+
+// Gaps returns the ranges of nonces missing from the list, starting at the given
+// nonce. Transactions with nonces lower than start are ignored.
+func (l *txList) Gaps(start uint64) []NonceGap {
+	var gaps []NonceGap
+	for _, tx := range l.Flatten() {
+		nonce := tx.Nonce()
+		if nonce < start {
+			continue
+		}
+		if nonce > start {
+			gaps = append(gaps, NonceGap{From: start, To: nonce - 1})
+		}
+		start = nonce + 1
+	}
+	return gaps
+}
+
 // priceHeap is a heap.Interface implementation over transactions for retrieving
 // price-sorted transactions to discard when the pool fills up.
 type priceHeap []*types.Transaction
@@ -456,9 +480,9 @@ func (l *txPricedList) Cap(threshold *big.Int, local *accountSet) types.Transact
 
 // Underpriced checks whether a transaction is cheaper than (or as cheap as) the
 // lowest priced transaction currently being tracked.
-func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) bool {
-	// Local transactions cannot be underpriced
-	if local.containsTx(tx) {
+func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet, priority *accountSet) bool {
+	// Local and priority transactions cannot be underpriced
+	if local.containsTx(tx) || priority.containsTx(tx) {
 		return false
 	}
 	// Discard stale price points if found at the heap start
@@ -481,10 +505,13 @@ func (l *txPricedList) Underpriced(tx *types.Transaction, local *accountSet) boo
 }
 
 // Discard finds a number of most underpriced transactions, removes them from the
-// priced list and returns them for further removal from the entire pool.
-func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions {
+// priced list and returns them for further removal from the entire pool. Remote
+// transactions are discarded first, priority ones only if there are not enough
+// remote ones, whereas local ones are never discarded.
+func (l *txPricedList) Discard(count int, local *accountSet, priority *accountSet) types.Transactions {
 	drop := make(types.Transactions, 0, count) // Remote underpriced transactions to drop
 	save := make(types.Transactions, 0, 64)    // Local underpriced transactions to keep
+	spare := make(types.Transactions, 0, 64)   // Priority underpriced transactions to drop only if needed
 
 	for len(*l.items) > 0 && count > 0 {
 		// Discard stale transactions if found during cleanup
@@ -493,15 +520,24 @@ func (l *txPricedList) Discard(count int, local *accountSet) types.Transactions
 			l.stales--
 			continue
 		}
-		// Non stale transaction found, discard unless local
-		if local.containsTx(tx) {
+		// Non stale transaction found, discard unless local or priority
+		switch {
+		case local.containsTx(tx):
 			save = append(save, tx)
-		} else {
+		case priority.containsTx(tx):
+			spare = append(spare, tx)
+		default:
 			drop = append(drop, tx)
 			count--
 		}
 	}
-	for _, tx := range save {
+	// If there weren't enough remote transactions, discard the cheapest priority ones
+	for len(spare) > 0 && count > 0 {
+		drop = append(drop, spare[0])
+		spare = spare[1:]
+		count--
+	}
+	for _, tx := range append(save, spare...) {
 		heap.Push(l.items, tx)
 	}
 	return drop
@@ -138,8 +138,39 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	Priority      []common.Address                       // Remote senders whose transactions are evicted only after all other remote ones
+	PrioritySlots uint64                                 // Executable transaction slots reserved for priority senders on top of the global ones
+	Accounts      map[common.Address]TxPoolAccountConfig `toml:",omitempty"` // Limits overriding the pool wide ones for specific accounts
+}
+
+// TxPoolAccountConfig are the transaction pool limits overriding the pool wide
+// ones for a specific account. Zero fields fall back to the pool wide limits.
+type TxPoolAccountConfig struct {
+	Slots     uint64 `json:"slots"`     // Minimum number of executable transaction slots guaranteed to the account
+	Queue     uint64 `json:"queue"`     // Maximum number of non-executable transaction slots permitted for the account
+	PriceBump uint64 `json:"priceBump"` // Minimum price bump percentage to replace a transaction of the account
 }
 
+// TxPoolLanes is the current priority lane configuration of the transaction pool.
+type TxPoolLanes struct {
+	Locals        []common.Address                       `json:"locals"`
+	Priority      []common.Address                       `json:"priority"`
+	PrioritySlots uint64                                 `json:"prioritySlots"`
+	Accounts      map[common.Address]TxPoolAccountConfig `json:"accounts"`
+}
+
+// txLane is a class of transaction senders subject to different eviction rules.
+// Under pressure remote transactions are evicted first, priority ones only if
+// that is not enough, whereas local ones are never evicted.
+type txLane int
+
+const (
+	remoteLane   txLane = iota // Transactions received from the network
+	priorityLane               // Transactions of whitelisted remote senders
+	localLane                  // Transactions submitted locally
+)
+
 // DefaultTxPoolConfig contains the default configurations for the transaction
 // pool.
 var DefaultTxPoolConfig = TxPoolConfig{
@@ -173,6 +204,12 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool price bump", "provided", conf.PriceBump, "updated", DefaultTxPoolConfig.PriceBump)
 		conf.PriceBump = DefaultTxPoolConfig.PriceBump
 	}
+	// Copy the account overrides to avoid runtime changes leaking out
+	accounts := make(map[common.Address]TxPoolAccountConfig, len(conf.Accounts))
+	for addr, limits := range conf.Accounts {
+		accounts[addr] = limits
+	}
+	conf.Accounts = accounts
 	return conf
 }
 
@@ -199,8 +236,9 @@ type TxPool struct {
 	pendingState  *state.ManagedState // Pending state tracking virtual nonces
 	currentMaxGas uint64              // Current gas limit for transaction caps
 
-	locals  *accountSet // Set of local transaction to exempt from eviction rules
-	journal *txJournal  // Journal of local transaction to back up to disk
+	locals   *accountSet // Set of local transaction to exempt from eviction rules
+	priority *accountSet // Set of remote senders to evict only after all other remote ones
+	journal  *txJournal  // Journal of local transaction to back up to disk
 
 	pending map[common.Address]*txList         // All currently processable transactions
 	queue   map[common.Address]*txList         // Queued but non-processable transactions
@@ -233,6 +271,10 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		gasPrice:    new(big.Int).SetUint64(config.PriceLimit),
 	}
 	pool.locals = newAccountSet(pool.signer)
+	pool.priority = newAccountSet(pool.signer)
+	for _, addr := range config.Priority {
+		pool.priority.add(addr)
+	}
 	pool.priced = newTxPricedList(&pool.all)
 	pool.reset(nil, chain.CurrentBlock().Header())
 
@@ -473,6 +515,106 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 	log.Info("Transaction pool price threshold updated", "price", price)
 }
 
+// Lanes retrieves the current priority lane configuration of the pool.
+func (pool *TxPool) Lanes() TxPoolLanes {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	lanes := TxPoolLanes{
+		Locals:        make([]common.Address, 0, len(pool.locals.accounts)),
+		Priority:      make([]common.Address, 0, len(pool.priority.accounts)),
+		PrioritySlots: pool.config.PrioritySlots,
+		Accounts:      make(map[common.Address]TxPoolAccountConfig, len(pool.config.Accounts)),
+	}
+	for addr := range pool.locals.accounts {
+		lanes.Locals = append(lanes.Locals, addr)
+	}
+	for addr := range pool.priority.accounts {
+		lanes.Priority = append(lanes.Priority, addr)
+	}
+	for addr, limits := range pool.config.Accounts {
+		lanes.Accounts[addr] = limits
+	}
+	return lanes
+}
+
+// SetPriority adds or removes a remote sender to or from the priority lane. The
+// change takes effect the next time the pool limits are enforced.
+func (pool *TxPool) SetPriority(addr common.Address, priority bool) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if priority {
+		pool.priority.add(addr)
+	} else {
+		delete(pool.priority.accounts, addr)
+	}
+	log.Info("Transaction pool priority lane updated", "address", addr, "priority", priority)
+}
+
+// SetPrioritySlots updates the number of executable transaction slots reserved
+// for the priority senders on top of the global ones.
+func (pool *TxPool) SetPrioritySlots(slots uint64) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.config.PrioritySlots = slots
+	log.Info("Transaction pool priority slots updated", "slots", slots)
+}
+
+// SetAccountLimits overrides the pool wide limits for a specific account. Zero
+// fields fall back to the pool wide limits, all zeroes removes the override.
+func (pool *TxPool) SetAccountLimits(addr common.Address, limits TxPoolAccountConfig) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if limits == (TxPoolAccountConfig{}) {
+		delete(pool.config.Accounts, addr)
+	} else {
+		pool.config.Accounts[addr] = limits
+	}
+	log.Info("Transaction pool account limits updated", "address", addr, "slots", limits.Slots, "queue", limits.Queue, "pricebump", limits.PriceBump)
+}
+
+// lane returns the class of transactions an account belongs to.
+func (pool *TxPool) lane(addr common.Address) txLane {
+	switch {
+	case pool.locals.contains(addr):
+		return localLane
+	case pool.priority.contains(addr):
+		return priorityLane
+	default:
+		return remoteLane
+	}
+}
+
+// accountSlots returns the number of executable transaction slots guaranteed to
+// an account.
+func (pool *TxPool) accountSlots(addr common.Address) uint64 {
+	if limits := pool.config.Accounts[addr]; limits.Slots > 0 {
+		return limits.Slots
+	}
+	return pool.config.AccountSlots
+}
+
+// accountQueue returns the number of non-executable transaction slots permitted
+// for an account.
+func (pool *TxPool) accountQueue(addr common.Address) uint64 {
+	if limits := pool.config.Accounts[addr]; limits.Queue > 0 {
+		return limits.Queue
+	}
+	return pool.config.AccountQueue
+}
+
+// priceBump returns the minimum price bump percentage required to replace a
+// transaction of an account.
+func (pool *TxPool) priceBump(addr common.Address) uint64 {
+	if limits := pool.config.Accounts[addr]; limits.PriceBump > 0 {
+		return limits.PriceBump
+	}
+	return pool.config.PriceBump
+}
+
 // State returns the virtual managed state of the transaction pool.
 func (pool *TxPool) State() *state.ManagedState {
 	pool.mu.RLock()
@@ -521,6 +663,37 @@ func (pool *TxPool) Content() (map[common.Address]types.Transactions, map[common
 	return pending, queued
 }
 
+// ContentFrom retrieves the data content of the transaction pool belonging to a
+// single account, returning its pending as well as queued transactions, sorted
+// by nonce.
+func (pool *TxPool) ContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	var pending, queued types.Transactions
+	if list, ok := pool.pending[addr]; ok {
+		pending = list.Flatten()
+	}
+	if list, ok := pool.queue[addr]; ok {
+		queued = list.Flatten()
+	}
+	return pending, queued
+}
+
+// NonceGaps retrieves the next nonce expected by the pool from an account (i.e.
+// the one following its last pending transaction) and the ranges of nonces that
+// are missing from its queued transactions, keeping them from being promoted.
+func (pool *TxPool) NonceGaps(addr common.Address) (uint64, []NonceGap) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	next := pool.pendingState.GetNonce(addr)
+	if list, ok := pool.queue[addr]; ok {
+		return next, list.Gaps(next)
+	}
+	return next, nil
+}
+
 // Pending retrieves all currently processable transactions, groupped by origin
 // account and sorted by nonce. The returned transaction set is a copy and can be
 // freely modified by calling code.
@@ -620,13 +793,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(len(pool.all)) >= pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
-		if pool.priced.Underpriced(tx, pool.locals) {
+		if pool.priced.Underpriced(tx, pool.locals, pool.priority) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
 			return false, ErrUnderpriced
 		}
 		// New transaction is better than our worse ones, make room for it
-		drop := pool.priced.Discard(len(pool.all)-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals)
+		drop := pool.priced.Discard(len(pool.all)-int(pool.config.GlobalSlots+pool.config.GlobalQueue-1), pool.locals, pool.priority)
 		for _, tx := range drop {
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "price", tx.GasPrice())
 			underpricedTxCounter.Inc(1)
@@ -637,7 +810,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (bool, error) {
 	from, _ := types.Sender(pool.signer, tx) // already validated
 	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
 		// Nonce already pending, check if required price bump is met
-		inserted, old := list.Add(tx, pool.config.PriceBump)
+		inserted, old := list.Add(tx, pool.priceBump(from))
 		if !inserted {
 			pendingDiscardCounter.Inc(1)
 			return false, ErrReplaceUnderpriced
@@ -683,7 +856,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction) (bool, er
 	if pool.queue[from] == nil {
 		pool.queue[from] = newTxList(false)
 	}
-	inserted, old := pool.queue[from].Add(tx, pool.config.PriceBump)
+	inserted, old := pool.queue[from].Add(tx, pool.priceBump(from))
 	if !inserted {
 		// An older transaction was better, discard this
 		queuedDiscardCounter.Inc(1)
@@ -722,7 +895,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 	}
 	list := pool.pending[addr]
 
-	inserted, old := list.Add(tx, pool.config.PriceBump)
+	inserted, old := list.Add(tx, pool.priceBump(addr))
 	if !inserted {
 		// An older transaction was better, discard this
 		delete(pool.all, hash)
@@ -944,7 +1117,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		}
 		// Drop all transactions over the allowed limit
 		if !pool.locals.contains(addr) {
-			for _, tx := range list.Cap(int(pool.config.AccountQueue)) {
+			for _, tx := range list.Cap(int(pool.accountQueue(addr))) {
 				hash := tx.Hash()
 				delete(pool.all, hash)
 				pool.priced.Removed()
@@ -957,74 +1130,25 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 			delete(pool.queue, addr)
 		}
 	}
-	// If the pending limit is overflown, start equalizing allowances
-	pending := uint64(0)
-	for _, list := range pool.pending {
+	// If the pending limit is overflown, start equalizing allowances. Priority
+	// senders may use their reserved slots on top of the global ones.
+	pending, reserved := uint64(0), uint64(0)
+	for addr, list := range pool.pending {
 		pending += uint64(list.Len())
+		if pool.lane(addr) == priorityLane {
+			reserved += uint64(list.Len())
+		}
+	}
+	if reserved > pool.config.PrioritySlots {
+		reserved = pool.config.PrioritySlots
 	}
-	if pending > pool.config.GlobalSlots {
+	if limit := pool.config.GlobalSlots + reserved; pending > limit {
 		pendingBeforeCap := pending
-		// Assemble a spam order to penalize large transactors first
-		spammers := prque.New()
-		for addr, list := range pool.pending {
-			// Only evict transactions from high rollers
-			if !pool.locals.contains(addr) && uint64(list.Len()) > pool.config.AccountSlots {
-				spammers.Push(addr, float32(list.Len()))
-			}
-		}
-		// Gradually drop transactions from offenders
-		offenders := []common.Address{}
-		for pending > pool.config.GlobalSlots && !spammers.Empty() {
-			// Retrieve the next offender if not local address
-			offender, _ := spammers.Pop()
-			offenders = append(offenders, offender.(common.Address))
-
-			// Equalize balances until all the same or below threshold
-			if len(offenders) > 1 {
-				// Calculate the equalization threshold for all current offenders
-				threshold := pool.pending[offender.(common.Address)].Len()
-
-				// Iteratively reduce all offenders until below limit or threshold reached
-				for pending > pool.config.GlobalSlots && pool.pending[offenders[len(offenders)-2]].Len() > threshold {
-					for i := 0; i < len(offenders)-1; i++ {
-						list := pool.pending[offenders[i]]
-						for _, tx := range list.Cap(list.Len() - 1) {
-							// Drop the transaction from the global pools too
-							hash := tx.Hash()
-							delete(pool.all, hash)
-							pool.priced.Removed()
-
-							// Update the account nonce to the dropped transaction
-							if nonce := tx.Nonce(); pool.pendingState.GetNonce(offenders[i]) > nonce {
-								pool.pendingState.SetNonce(offenders[i], nonce)
-							}
-							log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
-						}
-						pending--
-					}
-				}
-			}
-		}
-		// If still above threshold, reduce to limit or min allowance
-		if pending > pool.config.GlobalSlots && len(offenders) > 0 {
-			for pending > pool.config.GlobalSlots && uint64(pool.pending[offenders[len(offenders)-1]].Len()) > pool.config.AccountSlots {
-				for _, addr := range offenders {
-					list := pool.pending[addr]
-					for _, tx := range list.Cap(list.Len() - 1) {
-						// Drop the transaction from the global pools too
-						hash := tx.Hash()
-						delete(pool.all, hash)
-						pool.priced.Removed()
-
-						// Update the account nonce to the dropped transaction
-						if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
-							pool.pendingState.SetNonce(addr, nonce)
-						}
-						log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
-					}
-					pending--
-				}
-			}
+
+		// Evict the remote lane first, priority senders only if that's not enough
+		pending = pool.capPending(remoteLane, pending, limit)
+		if pending > limit {
+			pending = pool.capPending(priorityLane, pending, limit)
 		}
 		pendingRateLimitCounter.Inc(int64(pendingBeforeCap - pending))
 	}
@@ -1034,14 +1158,20 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 		queued += uint64(list.Len())
 	}
 	if queued > pool.config.GlobalQueue {
-		// Sort all accounts with queued transactions by heartbeat
-		addresses := make(addresssByHeartbeat, 0, len(pool.queue))
+		// Sort all accounts with queued transactions by heartbeat, remote lane last
+		remotes := make(addresssByHeartbeat, 0, len(pool.queue))
+		priorities := make(addresssByHeartbeat, 0)
 		for addr := range pool.queue {
-			if !pool.locals.contains(addr) { // don't drop locals
-				addresses = append(addresses, addressByHeartbeat{addr, pool.beats[addr]})
+			switch pool.lane(addr) {
+			case remoteLane:
+				remotes = append(remotes, addressByHeartbeat{addr, pool.beats[addr]})
+			case priorityLane:
+				priorities = append(priorities, addressByHeartbeat{addr, pool.beats[addr]})
 			}
 		}
-		sort.Sort(addresses)
+		sort.Sort(remotes)
+		sort.Sort(priorities)
+		addresses := append(priorities, remotes...)
 
 		// Drop transactions until the total is below the limit or only locals remain
 		for drop := queued - pool.config.GlobalQueue; drop > 0 && len(addresses) > 0; {
@@ -1070,6 +1200,89 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) {
 	}
 }
 
+// capPending drops pending transactions of the senders in the given lane that
+// exceed their guaranteed slots, penalizing large transactors first, until the
+// total number of pending transactions drops to the limit. The updated number of
+// pending transactions is returned.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) capPending(lane txLane, pending uint64, limit uint64) uint64 {
+	// Assemble a spam order to penalize large transactors first
+	spammers := prque.New()
+	for addr, list := range pool.pending {
+		// Only evict transactions from high rollers
+		if pool.lane(addr) == lane && uint64(list.Len()) > pool.accountSlots(addr) {
+			spammers.Push(addr, float32(list.Len()))
+		}
+	}
+	// Gradually drop transactions from offenders
+	offenders := []common.Address{}
+	for pending > limit && !spammers.Empty() {
+		// Retrieve the next offender
+		offender, _ := spammers.Pop()
+		offenders = append(offenders, offender.(common.Address))
+
+		// Equalize balances until all the same or below threshold
+		if len(offenders) > 1 {
+			// Calculate the equalization threshold for all current offenders
+			threshold := pool.pending[offender.(common.Address)].Len()
+
+			// Iteratively reduce all offenders until below limit or threshold reached,
+			// never cutting any of them below their own guaranteed slots
+			for pending > limit {
+				capped := false
+				for i := 0; i < len(offenders)-1; i++ {
+					size := uint64(pool.pending[offenders[i]].Len())
+					if size <= uint64(threshold) || size <= pool.accountSlots(offenders[i]) {
+						continue
+					}
+					pool.dropLastPending(offenders[i])
+					pending--
+					capped = true
+				}
+				if !capped {
+					break
+				}
+			}
+		}
+	}
+	// If still above threshold, reduce to limit or min allowance
+	for pending > limit && len(offenders) > 0 {
+		capped := false
+		for _, addr := range offenders {
+			if uint64(pool.pending[addr].Len()) <= pool.accountSlots(addr) {
+				continue
+			}
+			pool.dropLastPending(addr)
+			pending--
+			capped = true
+		}
+		if !capped {
+			break
+		}
+	}
+	return pending
+}
+
+// dropLastPending removes the highest nonce pending transaction of an account.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) dropLastPending(addr common.Address) {
+	list := pool.pending[addr]
+	for _, tx := range list.Cap(list.Len() - 1) {
+		// Drop the transaction from the global pools too
+		hash := tx.Hash()
+		delete(pool.all, hash)
+		pool.priced.Removed()
+
+		// Update the account nonce to the dropped transaction
+		if nonce := tx.Nonce(); pool.pendingState.GetNonce(addr) > nonce {
+			pool.pendingState.SetNonce(addr, nonce)
+		}
+		log.Trace("Removed fairness-exceeding pending transaction", "hash", hash)
+	}
+}
+
 // demoteUnexecutables removes invalid and processed transactions from the pools
 // executable/pending queue and any subsequent transactions that become unexecutable
 // are moved back into the future queue.
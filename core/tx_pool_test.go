@@ -23,6 +23,7 @@ import (
 	"math/big"
 	"math/rand"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 
@@ -685,6 +686,69 @@ func TestTransactionGapFilling(t *testing.T) {
 	}
 }
 
+// Tests that the content of a single account can be retrieved from the pool, and
+// that the nonce gaps keeping its queued transactions from being promoted are
+// correctly reported.
+func TestTransactionContentFromNonceGaps(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	account, _ := deriveSender(transaction(0, 0, key))
+	pool.currentState.AddBalance(account, big.NewInt(1000000))
+
+	other, _ := crypto.GenerateKey()
+	pool.currentState.AddBalance(crypto.PubkeyToAddress(other.PublicKey), big.NewInt(1000000))
+
+	// Add an executable run, followed by two gapped queued batches
+	for _, nonce := range []uint64{0, 1, 2, 5, 6, 9} {
+		if err := pool.AddRemote(transaction(nonce, 100000, key)); err != nil {
+			t.Fatalf("failed to add transaction %d: %v", nonce, err)
+		}
+	}
+	if err := pool.AddRemote(transaction(0, 100000, other)); err != nil {
+		t.Fatalf("failed to add foreign transaction: %v", err)
+	}
+	pending, queued := pool.ContentFrom(account)
+	if len(pending) != 3 {
+		t.Errorf("pending transactions mismatched: have %d, want %d", len(pending), 3)
+	}
+	if len(queued) != 3 {
+		t.Errorf("queued transactions mismatched: have %d, want %d", len(queued), 3)
+	}
+	for i, tx := range pending {
+		if tx.Nonce() != uint64(i) {
+			t.Errorf("pending transaction %d: nonce mismatch: have %d, want %d", i, tx.Nonce(), i)
+		}
+	}
+	next, gaps := pool.NonceGaps(account)
+	if next != 3 {
+		t.Errorf("next nonce mismatch: have %d, want %d", next, 3)
+	}
+	want := []NonceGap{{From: 3, To: 4}, {From: 7, To: 8}}
+	if !reflect.DeepEqual(gaps, want) {
+		t.Errorf("nonce gaps mismatch: have %v, want %v", gaps, want)
+	}
+	// Fill the first gap and ensure the queued transactions get promoted
+	pool.AddRemotes(types.Transactions{transaction(3, 100000, key), transaction(4, 100000, key)})
+
+	if next, gaps = pool.NonceGaps(account); next != 7 {
+		t.Errorf("next nonce mismatch after promotion: have %d, want %d", next, 7)
+	}
+	want = []NonceGap{{From: 7, To: 8}}
+	if !reflect.DeepEqual(gaps, want) {
+		t.Errorf("nonce gaps mismatch after promotion: have %v, want %v", gaps, want)
+	}
+	// An account without any transactions should have nothing reported
+	if pending, queued = pool.ContentFrom(common.Address{0xff}); len(pending) != 0 || len(queued) != 0 {
+		t.Errorf("unknown account content mismatch: have %d/%d, want 0/0", len(pending), len(queued))
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that if the transaction count belonging to a single account goes above
 // some threshold, the higher transactions are dropped to prevent DOS attacks.
 func TestTransactionQueueAccountLimiting(t *testing.T) {
@@ -1102,6 +1166,231 @@ func TestTransactionPendingMinimumAllowance(t *testing.T) {
 	}
 }
 
+// Tests that if the transaction count belonging to multiple accounts go above
+// some hard threshold, the remote lane is evicted first, keeping the transactions
+// of priority senders within their reserved slots.
+func TestTransactionPendingPriorityLane(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the limit enforcement with
+	db, _ := vapdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountSlots = 4
+	config.GlobalSlots = 20
+	config.PrioritySlots = 10
+
+	// Create a number of test accounts and fund them, the first being priority
+	keys := make([]*ecdsa.PrivateKey, 5)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+	}
+	priority := crypto.PubkeyToAddress(keys[0].PublicKey)
+	config.Priority = []common.Address{priority}
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	for _, key := range keys {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	}
+	// Generate and queue a batch of transactions
+	txs := types.Transactions{}
+	for _, key := range keys {
+		for j := uint64(0); j < 10; j++ {
+			txs = append(txs, transaction(j, 100000, key))
+		}
+	}
+	pool.AddRemotes(txs)
+
+	// Ensure the priority sender kept its transactions and the remote ones were capped
+	if have := pool.pending[priority].Len(); have != 10 {
+		t.Errorf("priority pending transactions mismatch: have %d, want %d", have, 10)
+	}
+	pending := 0
+	for addr, list := range pool.pending {
+		if addr != priority && list.Len() > 5 {
+			t.Errorf("addr %x: remote pending transactions not capped: have %d, want %d", addr, list.Len(), 5)
+		}
+		pending += list.Len()
+	}
+	if limit := int(config.GlobalSlots + config.PrioritySlots); pending > limit {
+		t.Fatalf("total pending transactions overflow allowance: %d > %d", pending, limit)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	// Demote the priority sender and ensure it's treated as any remote sender again
+	pool.SetPriority(priority, false)
+	if lanes := pool.Lanes(); len(lanes.Priority) != 0 {
+		t.Fatalf("priority sender not removed: %v", lanes.Priority)
+	}
+	pool.lockedReset(nil, nil)
+
+	for addr, list := range pool.pending {
+		if list.Len() > 4 {
+			t.Errorf("addr %x: pending transactions not capped: have %d, want %d", addr, list.Len(), 4)
+		}
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that account specific limits override the pool wide slot and price bump
+// limits for the given account only.
+func TestTransactionAccountLimits(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	addr := crypto.PubkeyToAddress(key.PublicKey)
+	pool.currentState.AddBalance(addr, big.NewInt(1000000000))
+
+	pool.SetAccountLimits(addr, TxPoolAccountConfig{Queue: 2, PriceBump: 50})
+	if limits := pool.Lanes().Accounts[addr]; limits.Queue != 2 || limits.PriceBump != 50 {
+		t.Fatalf("account limits mismatch: have %+v", limits)
+	}
+	// Ensure the price bump override is enforced for replacements
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(100), key)); err != nil {
+		t.Fatalf("failed to add original transaction: %v", err)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(120), key)); err != ErrReplaceUnderpriced {
+		t.Fatalf("replacement error mismatch: have %v, want %v", err, ErrReplaceUnderpriced)
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(150), key)); err != nil {
+		t.Fatalf("failed to replace with well priced transaction: %v", err)
+	}
+	// Ensure the queue override is enforced for future transactions
+	for i := uint64(2); i < 6; i++ {
+		pool.AddRemote(pricedTransaction(i, 100000, big.NewInt(100), key))
+	}
+	if queued := pool.queue[addr].Len(); queued != 2 {
+		t.Fatalf("queued transactions mismatch: have %d, want %d", queued, 2)
+	}
+	// Remove the override and ensure the pool wide limits apply again
+	pool.SetAccountLimits(addr, TxPoolAccountConfig{})
+	if _, ok := pool.Lanes().Accounts[addr]; ok {
+		t.Fatalf("account limits not removed")
+	}
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(165), key)); err != nil {
+		t.Fatalf("failed to replace with default price bump: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that capping the pending transactions never cuts an account below its
+// own guaranteed slots, even if they exceed the pool wide allowance.
+func TestTransactionPendingAccountSlots(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the limit enforcement with
+	db, _ := vapdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.AccountSlots = 2
+	config.GlobalSlots = 4
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Create two funded accounts, the first one with extra guaranteed slots
+	large, _ := crypto.GenerateKey()
+	small, _ := crypto.GenerateKey()
+	for _, key := range []*ecdsa.PrivateKey{large, small} {
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000))
+	}
+	pool.SetAccountLimits(crypto.PubkeyToAddress(large.PublicKey), TxPoolAccountConfig{Slots: 8})
+
+	// Overflow the pool with both accounts and ensure each is capped at its own slots
+	txs := types.Transactions{}
+	for i := uint64(0); i < 10; i++ {
+		txs = append(txs, transaction(i, 100000, large))
+	}
+	for i := uint64(0); i < 5; i++ {
+		txs = append(txs, transaction(i, 100000, small))
+	}
+	pool.AddRemotes(txs)
+
+	if have := pool.pending[crypto.PubkeyToAddress(large.PublicKey)].Len(); have != 8 {
+		t.Errorf("overridden account pending transactions mismatch: have %d, want %d", have, 8)
+	}
+	if have := pool.pending[crypto.PubkeyToAddress(small.PublicKey)].Len(); have != 2 {
+		t.Errorf("default account pending transactions mismatch: have %d, want %d", have, 2)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that when the pool is full, priority transactions are never considered
+// underpriced and are only discarded if there are no remote ones left to drop.
+func TestTransactionPoolUnderpricingPriority(t *testing.T) {
+	t.Parallel()
+
+	// Create the pool to test the pricing enforcement with
+	db, _ := vapdb.NewMemDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	// Create a number of test accounts and fund them, the first being priority
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		pool.currentState.AddBalance(crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1000000))
+	}
+	pool.SetPriority(crypto.PubkeyToAddress(keys[0].PublicKey), true)
+
+	// Fill the pool with cheap priority and slightly more expensive remote transactions
+	txs := types.Transactions{
+		pricedTransaction(0, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(1, 100000, big.NewInt(1), keys[0]),
+		pricedTransaction(0, 100000, big.NewInt(2), keys[1]),
+		pricedTransaction(1, 100000, big.NewInt(2), keys[1]),
+	}
+	pool.AddRemotes(txs)
+
+	// Ensure that priority transactions are never underpriced, and push out remotes
+	if err := pool.AddRemote(pricedTransaction(2, 100000, big.NewInt(1), keys[0])); err != nil {
+		t.Fatalf("failed to add underpriced priority transaction: %v", err)
+	}
+	if pool.Get(txs[2].Hash()) != nil && pool.Get(txs[3].Hash()) != nil {
+		t.Errorf("cheapest remote transaction not discarded")
+	}
+	for i := 0; i < 2; i++ {
+		if pool.Get(txs[i].Hash()) == nil {
+			t.Errorf("priority transaction #%d discarded", i)
+		}
+	}
+	// Ensure that even well priced remote transactions can only push out remotes
+	if err := pool.AddRemote(pricedTransaction(0, 100000, big.NewInt(5), keys[2])); err != nil {
+		t.Fatalf("failed to add well priced transaction: %v", err)
+	}
+	if pool.Get(txs[2].Hash()) != nil || pool.Get(txs[3].Hash()) != nil {
+		t.Errorf("remote transaction retained over priority ones")
+	}
+	if list := pool.pending[crypto.PubkeyToAddress(keys[0].PublicKey)]; list == nil || list.Len() != 3 {
+		t.Errorf("priority transactions discarded before remotes")
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
 // Tests that setting the transaction pool gas price to a higher value correctly
 // discards everything cheaper than that and moves any gapped transactions back
 // from the pending pool to the queue.
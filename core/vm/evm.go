@@ -312,9 +312,8 @@ func (vvm *VVM) StaticCall(caller ContractRef, addr common.Address, input []byte
 	return ret, contract.Gas, err
 }
 
-// Create creates a new contract using code as deployment code.
-func (vvm *VVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
-
+// create creates a new contract at the given address using code as deployment code.
+func (vvm *VVM) create(caller ContractRef, code []byte, gas uint64, value *big.Int, contractAddr common.Address) ([]byte, common.Address, uint64, error) {
 	// Depth check execution. Fail if we're trying to execute above the
 	// limit.
 	if vvm.depth > int(params.CallCreateDepth) {
@@ -323,11 +322,10 @@ func (vvm *VVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	if !vvm.CanTransfer(vvm.StateDB, caller.Address(), value) {
 		return nil, common.Address{}, gas, ErrInsufficientBalance
 	}
-	// Ensure there's no existing contract already at the designated address
 	nonce := vvm.StateDB.GetNonce(caller.Address())
 	vvm.StateDB.SetNonce(caller.Address(), nonce+1)
 
-	contractAddr = crypto.CreateAddress(caller.Address(), nonce)
+	// Ensure there's no existing contract already at the designated address
 	contractHash := vvm.StateDB.GetCodeHash(contractAddr)
 	if vvm.StateDB.GetNonce(contractAddr) != 0 || (contractHash != (common.Hash{}) && contractHash != emptyCodeHash) {
 		return nil, common.Address{}, 0, ErrContractAddressCollision
@@ -355,7 +353,7 @@ func (vvm *VVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	}
 	start := time.Now()
 
-	ret, err = run(vvm, contract, nil)
+	ret, err := run(vvm, contract, nil)
 
 	// check whether the max code size has been exceeded
 	maxCodeSizeExceeded := vvm.ChainConfig().IsEIP158(vvm.BlockNumber) && len(ret) > params.MaxCodeSize
@@ -391,6 +389,22 @@ func (vvm *VVM) Create(caller ContractRef, code []byte, gas uint64, value *big.I
 	return ret, contractAddr, contract.Gas, err
 }
 
+// Create creates a new contract using code as deployment code.
+func (vvm *VVM) Create(caller ContractRef, code []byte, gas uint64, value *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress(caller.Address(), vvm.StateDB.GetNonce(caller.Address()))
+	return vvm.create(caller, code, gas, value, contractAddr)
+}
+
+// Create2 creates a new contract using code as deployment code.
+//
+// The difference between Create2 and Create is that Create2 uses
+// sha3(0xff ++ msg.sender ++ salt ++ sha3(init_code))[12:] instead of the usual
+// sender-and-nonce-hash as the address where the contract is initialized at.
+func (vvm *VVM) Create2(caller ContractRef, code []byte, gas uint64, endowment *big.Int, salt *big.Int) (ret []byte, contractAddr common.Address, leftOverGas uint64, err error) {
+	contractAddr = crypto.CreateAddress2(caller.Address(), common.BigToHash(salt), crypto.Keccak256(code))
+	return vvm.create(caller, code, gas, endowment, contractAddr)
+}
+
 // ChainConfig returns the environment's chain configuration
 func (vvm *VVM) ChainConfig() *params.ChainConfig { return vvm.chainConfig }
 
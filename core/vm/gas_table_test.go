@@ -16,7 +16,17 @@
 
 package vm
 
-import "testing"
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/state"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vapdb"
+)
 
 func TestMemoryGasCost(t *testing.T) {
 	//size := uint64(math.MaxUint64 - 64)
@@ -34,3 +44,64 @@ func TestMemoryGasCost(t *testing.T) {
 		t.Error("expected error")
 	}
 }
+
+var eip1283Tests = []struct {
+	code     string
+	gasUsed  uint64
+	refund   uint64
+	original byte
+}{
+	// Testcases from https://github.com/ethereum/EIPs/blob/master/EIPS/eip-1283.md
+	{"0x60006000556000600055", 412, 0, 0},
+	{"0x60006000556001600055", 20212, 0, 0},
+	{"0x60016000556000600055", 20212, 19800, 0},
+	{"0x60016000556002600055", 20212, 0, 0},
+	{"0x60016000556001600055", 20212, 0, 0},
+	{"0x60006000556000600055", 5212, 15000, 1},
+	{"0x60006000556001600055", 5212, 4800, 1},
+	{"0x60006000556002600055", 5212, 0, 1},
+	{"0x60026000556000600055", 5212, 15000, 1},
+	{"0x60026000556003600055", 5212, 0, 1},
+	{"0x60026000556001600055", 5212, 4800, 1},
+	{"0x60026000556002600055", 5212, 0, 1},
+	{"0x60016000556000600055", 5212, 15000, 1},
+	{"0x60016000556002600055", 5212, 0, 1},
+	{"0x60016000556001600055", 412, 0, 1},
+	{"0x600160005560006000556001600055", 40218, 19800, 0},
+	{"0x600060005560016000556000600055", 10218, 19800, 1},
+}
+
+// TestEIP1283 checks the net gas metering of SSTORE on Constantinople.
+func TestEIP1283(t *testing.T) {
+	address := common.BytesToAddress([]byte("contract"))
+	config := *params.AllVapashProtocolChanges
+	config.ConstantinopleBlock = big.NewInt(0)
+
+	for i, tt := range eip1283Tests {
+		db, _ := vapdb.NewMemDatabase()
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(db))
+		statedb.CreateAccount(address)
+		statedb.SetCode(address, hexutil.MustDecode(tt.code))
+		statedb.SetState(address, common.Hash{}, common.BytesToHash([]byte{tt.original}))
+		statedb.Finalise(true) // Push the state into the "original" slot
+
+		vmctx := Context{
+			CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+			Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+			BlockNumber: big.NewInt(0),
+		}
+		vmenv := NewVVM(vmctx, statedb, &config, Config{})
+
+		_, gas, err := vmenv.Call(AccountRef(common.Address{}), address, nil, math.MaxUint64, new(big.Int))
+		if err != nil {
+			t.Errorf("test %d: failed to execute transaction: %v", i, err)
+			continue
+		}
+		if used := math.MaxUint64 - gas; used != tt.gasUsed {
+			t.Errorf("test %d: gas used mismatch: have %v, want %v", i, used, tt.gasUsed)
+		}
+		if refund := vmenv.StateDB.GetRefund(); refund != tt.refund {
+			t.Errorf("test %d: gas refund mismatch: have %v, want %v", i, refund, tt.refund)
+		}
+	}
+}
@@ -70,6 +70,8 @@ func NewInterpreter(vvm *VVM, cfg Config) *Interpreter {
 	// we'll set the default jump table.
 	if !cfg.JumpTable[STOP].valid {
 		switch {
+		case vvm.ChainConfig().IsConstantinople(vvm.BlockNumber):
+			cfg.JumpTable = constantinopleInstructionSet
 		case vvm.ChainConfig().IsByzantium(vvm.BlockNumber):
 			cfg.JumpTable = byzantiumInstructionSet
 		case vvm.ChainConfig().IsHomestead(vvm.BlockNumber):
@@ -0,0 +1,235 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/consensus/vapash"
+	"github.com/vaporyco/go-vapory/core"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/node"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vap"
+	"github.com/vaporyco/go-vapory/vap/filters"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = big.NewInt(2e18)
+
+	// logCode is the init code of a contract emitting an empty LOG0 event.
+	logCode = common.FromHex("0x60006000a0")
+)
+
+// newTestBackend creates a networkless full node with a short chain consisting
+// of a value transfer in block 1 and a log emitting contract creation in block 2.
+func newTestBackend(t *testing.T) (*node.Node, *vap.Vapory, []*types.Block) {
+	stack, err := node.New(&node.Config{Name: "graphql-tester", NoUSB: true})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	config := &vap.Config{
+		Genesis: &core.Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 4712388,
+			Alloc:    core.GenesisAlloc{testAddr: {Balance: testBalance}},
+		},
+		Vapash: vapash.Config{PowMode: vapash.ModeFake},
+	}
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) { return vap.New(ctx, config) }); err != nil {
+		t.Fatalf("failed to register Vapory protocol: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start test stack: %v", err)
+	}
+	var vapServ *vap.Vapory
+	stack.Service(&vapServ)
+
+	chain := vapServ.BlockChain()
+	signer := types.HomesteadSigner{}
+	blocks, _ := core.GenerateChain(params.TestChainConfig, chain.Genesis(), vapash.NewFaker(), vapServ.ChainDb(), 2, func(i int, b *core.BlockGen) {
+		var tx *types.Transaction
+		switch i {
+		case 0:
+			tx = types.NewTransaction(b.TxNonce(testAddr), common.Address{0x01}, big.NewInt(1000), 21000, big.NewInt(1), nil)
+		case 1:
+			tx = types.NewContractCreation(b.TxNonce(testAddr), new(big.Int), 100000, big.NewInt(1), logCode)
+		}
+		tx, _ = types.SignTx(tx, signer, testKey)
+		b.AddTx(tx)
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		stack.Stop()
+		t.Fatalf("failed to insert test chain: %v", err)
+	}
+	return stack, vapServ, blocks
+}
+
+// execute runs a GraphQL query against the handler, returning the raw data and
+// the errors of the response.
+func execute(t *testing.T, url, query string) (string, []interface{}) {
+	body, _ := json.Marshal(map[string]interface{}{"query": query})
+	res, err := http.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("query %q failed: %v", query, err)
+	}
+	defer res.Body.Close()
+
+	blob, _ := ioutil.ReadAll(res.Body)
+	var response struct {
+		Data   json.RawMessage
+		Errors []interface{}
+	}
+	if err := json.Unmarshal(blob, &response); err != nil {
+		t.Fatalf("query %q: invalid response %s: %v", query, blob, err)
+	}
+	return string(response.Data), response.Errors
+}
+
+// query executes a GraphQL query against the handler, returning the raw data
+// of the response or failing the test on errors.
+func query(t *testing.T, url, query string) string {
+	data, errs := execute(t, url, query)
+	if len(errs) > 0 {
+		t.Fatalf("query %q: returned errors: %v", query, errs)
+	}
+	return data
+}
+
+// Tests that blocks, transactions, receipts, logs and accounts are resolved by
+// the GraphQL schema.
+func TestGraphQLQueries(t *testing.T) {
+	stack, vapServ, blocks := newTestBackend(t)
+	defer stack.Stop()
+
+	handler, err := newHandler(vapServ.ApiBackend, filters.Limits{})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	var (
+		transfer = blocks[0].Transactions()[0]
+		creation = blocks[1].Transactions()[0]
+		contract = crypto.CreateAddress(testAddr, creation.Nonce())
+		balance  = new(big.Int).Sub(testBalance, big.NewInt(1000))
+	)
+	balance.Sub(balance, big.NewInt(21000))
+	receipt, _, _, _ := core.GetReceipt(vapServ.ChainDb(), creation.Hash())
+	balance.Sub(balance, new(big.Int).SetUint64(receipt.GasUsed))
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{
+			`{block{number hash}}`,
+			fmt.Sprintf(`{"block":{"number":"0x2","hash":"%s"}}`, blocks[1].Hash().Hex()),
+		},
+		{
+			`{block(number:1){transactionCount transactions{hash index from{address} to{address} value status gasUsed}}}`,
+			fmt.Sprintf(`{"block":{"transactionCount":1,"transactions":[{"hash":"%s","index":0,"from":{"address":"%s"},"to":{"address":"%s"},"value":"0x3e8","status":"0x1","gasUsed":"0x5208"}]}}`,
+				transfer.Hash().Hex(), hexAddr(testAddr), hexAddr(common.Address{0x01})),
+		},
+		{
+			fmt.Sprintf(`{block(hash:"%s"){number parent{number}}}`, blocks[1].Hash().Hex()),
+			`{"block":{"number":"0x2","parent":{"number":"0x1"}}}`,
+		},
+		{
+			`{block(number:3){number}}`,
+			`{"block":null}`,
+		},
+		{
+			`{blocks(from:0){number}}`,
+			`{"blocks":[{"number":"0x0"},{"number":"0x1"},{"number":"0x2"}]}`,
+		},
+		{
+			fmt.Sprintf(`{transaction(hash:"%s"){block{number} to{address} createdContract{address code} logs{index account{address}}}}`, creation.Hash().Hex()),
+			fmt.Sprintf(`{"transaction":{"block":{"number":"0x2"},"to":null,"createdContract":{"address":"%s","code":"0x"},"logs":[{"index":0,"account":{"address":"%s"}}]}}`,
+				hexAddr(contract), hexAddr(contract)),
+		},
+		{
+			`{logs(filter:{fromBlock:0}){index transaction{hash block{number}}}}`,
+			fmt.Sprintf(`{"logs":[{"index":0,"transaction":{"hash":"%s","block":{"number":"0x2"}}}]}`, creation.Hash().Hex()),
+		},
+		{
+			fmt.Sprintf(`{block(number:2){logs(filter:{addresses:["%s"]}){index}}}`, hexAddr(contract)),
+			`{"block":{"logs":[{"index":0}]}}`,
+		},
+		{
+			fmt.Sprintf(`{block(number:2){logs(filter:{addresses:["%s"]}){index}}}`, hexAddr(common.Address{0x01})),
+			`{"block":{"logs":[]}}`,
+		},
+		{
+			fmt.Sprintf(`{block(number:1){account(address:"%s"){transactionCount}} pending{transactionCount account(address:"%s"){balance transactionCount}}}`, hexAddr(testAddr), hexAddr(testAddr)),
+			fmt.Sprintf(`{"block":{"account":{"transactionCount":"0x1"}},"pending":{"transactionCount":0,"account":{"balance":"%#x","transactionCount":"0x2"}}}`, balance),
+		},
+	}
+	for i, tt := range tests {
+		if have := query(t, server.URL, tt.query); have != tt.want {
+			t.Errorf("test %d: result mismatch:\nhave %s\nwant %s", i, have, tt.want)
+		}
+	}
+}
+
+// Tests that log queries are capped by the limits of the handler.
+func TestGraphQLLogLimits(t *testing.T) {
+	stack, vapServ, _ := newTestBackend(t)
+	defer stack.Stop()
+
+	handler, err := newHandler(vapServ.ApiBackend, filters.Limits{MaxBlocks: 2})
+	if err != nil {
+		t.Fatalf("failed to create handler: %v", err)
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// Ensure a query within the block range succeeds
+	if have, want := query(t, server.URL, `{logs(filter:{fromBlock:1}){index}}`), `{"logs":[{"index":0}]}`; have != want {
+		t.Errorf("capped range result mismatch: have %s, want %s", have, want)
+	}
+	// Ensure a query spanning more blocks is rejected
+	if _, errs := execute(t, server.URL, `{logs(filter:{fromBlock:0}){index}}`); len(errs) != 1 || !strings.Contains(fmt.Sprint(errs[0]), "query exceeds max block range of 2") {
+		t.Errorf("oversized range errors mismatch: have %v", errs)
+	}
+	// Ensure block ranges are capped too
+	if have, want := query(t, server.URL, `{blocks(from:1, to:2){number}}`), `{"blocks":[{"number":"0x1"},{"number":"0x2"}]}`; have != want {
+		t.Errorf("capped blocks result mismatch: have %s, want %s", have, want)
+	}
+	if _, errs := execute(t, server.URL, `{blocks(from:0, to:"0x3fffffffffffffff"){number}}`); len(errs) != 1 || !strings.Contains(fmt.Sprint(errs[0]), "query exceeds max block range of 2") {
+		t.Errorf("oversized blocks range errors mismatch: have %v", errs)
+	}
+}
+
+// hexAddr returns the lowercase hex encoding of an address, as marshalled in
+// GraphQL responses.
+func hexAddr(addr common.Address) string {
+	return strings.ToLower(addr.Hex())
+}
@@ -0,0 +1,149 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/graph-gophers/graphql-go"
+	"github.com/vaporyco/go-vapory/internal/vapapi"
+	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/p2p"
+	"github.com/vaporyco/go-vapory/rpc"
+	"github.com/vaporyco/go-vapory/vap/filters"
+)
+
+// maxRequestContentLength is the maximum size of a GraphQL query accepted.
+const maxRequestContentLength = 1024 * 128
+
+// Service encapsulates a GraphQL service.
+type Service struct {
+	endpoint string         // The host:port endpoint for this service.
+	cors     []string       // Allowed CORS domains
+	vhosts   []string       // Recognised vhosts
+	backend  vapapi.Backend // The backend that queries will operate on.
+	handler  http.Handler   // The `http.Handler` used to answer queries.
+	listener net.Listener   // The listening socket.
+}
+
+// New constructs a new GraphQL service instance, capping the log queries with the
+// given limits.
+func New(backend vapapi.Backend, limits filters.Limits, endpoint string, cors, vhosts []string) (*Service, error) {
+	handler, err := newHandler(backend, limits)
+	if err != nil {
+		return nil, err
+	}
+	return &Service{
+		endpoint: endpoint,
+		cors:     cors,
+		vhosts:   vhosts,
+		backend:  backend,
+		handler:  handler,
+	}, nil
+}
+
+// Protocols returns the list of protocols exported by this service.
+func (s *Service) Protocols() []p2p.Protocol { return nil }
+
+// APIs returns the list of APIs exported by this service.
+func (s *Service) APIs() []rpc.API { return nil }
+
+// Start is called after all services have been constructed and the networking
+// layer was also initialized to spawn any goroutines required by the service.
+func (s *Service) Start(server *p2p.Server) error {
+	var err error
+	if s.listener, err = net.Listen("tcp", s.endpoint); err != nil {
+		return err
+	}
+	go rpc.NewHTTPServer(s.cors, s.vhosts, s.handler).Serve(s.listener)
+	log.Info("GraphQL endpoint opened", "url", fmt.Sprintf("http://%s", s.endpoint))
+	return nil
+}
+
+// Stop terminates all goroutines belonging to the service, blocking until they
+// are all terminated.
+func (s *Service) Stop() error {
+	if s.listener != nil {
+		s.listener.Close()
+		s.listener = nil
+		log.Info("GraphQL endpoint closed", "url", fmt.Sprintf("http://%s", s.endpoint))
+	}
+	return nil
+}
+
+// handler executes GraphQL queries against a parsed schema.
+type handler struct {
+	schema *graphql.Schema
+	limits filters.Limits // Caps on the blocks searched and logs returned by log queries
+}
+
+// newHandler creates an HTTP handler answering GraphQL queries over the given
+// backend, capping the log queries with the given limits.
+func newHandler(backend vapapi.Backend, limits filters.Limits) (http.Handler, error) {
+	schema, err := graphql.ParseSchema(schema, &Resolver{backend})
+	if err != nil {
+		return nil, err
+	}
+	return &handler{schema: schema, limits: limits}, nil
+}
+
+// ServeHTTP implements http.Handler, executing the query contained in a POST
+// body (or the query string of a GET request) and writing the JSON response.
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	switch r.Method {
+	case http.MethodGet:
+		params.Query = r.URL.Query().Get("query")
+		params.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &params.Variables); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		if r.ContentLength > maxRequestContentLength {
+			http.Error(w, fmt.Sprintf("content length too large (%d>%d)", r.ContentLength, maxRequestContentLength), http.StatusRequestEntityTooLarge)
+			return
+		}
+		body := http.MaxBytesReader(w, r.Body, maxRequestContentLength)
+		if err := json.NewDecoder(body).Decode(&params); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	ctx := context.WithValue(r.Context(), limitsKey{}, h.limits)
+	response := h.schema.Exec(ctx, params.Query, params.OperationName, params.Variables)
+	blob, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(blob)
+}
@@ -21,6 +21,7 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"time"
 
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/core/types"
@@ -103,6 +104,9 @@ type SyncProgress struct {
 	HighestBlock  uint64 // Highest alleged block number in the chain
 	PulledStates  uint64 // Number of state trie entries already downloaded
 	KnownStates   uint64 // Total number of state trie entries known about
+
+	Percentage float64       // Estimated percentage of the sync already completed
+	ETA        time.Duration // Estimated time remaining until the sync completes (0 if unknown)
 }
 
 // ChainSyncReader wraps access to the node's current sync status. If there's no
@@ -111,6 +115,13 @@ type ChainSyncReader interface {
 	SyncProgress(ctx context.Context) (*SyncProgress, error)
 }
 
+// NonceGap is an inclusive range of nonces missing from an account's transactions
+// in the pool, preventing all subsequent ones from becoming executable.
+type NonceGap struct {
+	From uint64 // First missing nonce of the gap
+	To   uint64 // Last missing nonce of the gap
+}
+
 // CallMsg contains parameters for contract calls.
 type CallMsg struct {
 	From     common.Address  // the sender of the 'transaction'
@@ -76,6 +76,8 @@ func (s *PublicVaporyAPI) ProtocolVersion() hexutil.Uint {
 // - highestBlock:  block number of the highest block header this node has received from peers
 // - pulledStates:  number of state entries processed until now
 // - knownStates:   number of known state entries that still need to be pulled
+// - percentage:    estimated percentage of the synchronisation already completed
+// - eta:           estimated number of seconds until the synchronisation completes
 func (s *PublicVaporyAPI) Syncing() (interface{}, error) {
 	progress := s.b.Downloader().Progress()
 
@@ -90,6 +92,8 @@ func (s *PublicVaporyAPI) Syncing() (interface{}, error) {
 		"highestBlock":  hexutil.Uint64(progress.HighestBlock),
 		"pulledStates":  hexutil.Uint64(progress.PulledStates),
 		"knownStates":   hexutil.Uint64(progress.KnownStates),
+		"percentage":    progress.Percentage,
+		"eta":           hexutil.Uint64(progress.ETA / time.Second),
 	}, nil
 }
 
@@ -148,18 +152,11 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	}
 	pending, queue := s.b.TxPoolContent()
 
-	// Define a formatter to flatten a transaction into a string
-	var format = func(tx *types.Transaction) string {
-		if to := tx.To(); to != nil {
-			return fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
-		}
-		return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
-	}
 	// Flatten the pending transactions
 	for account, txs := range pending {
 		dump := make(map[string]string)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
+			dump[fmt.Sprintf("%d", tx.Nonce())] = inspectPoolTransaction(tx)
 		}
 		content["pending"][account.Hex()] = dump
 	}
@@ -167,13 +164,78 @@ func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
 	for account, txs := range queue {
 		dump := make(map[string]string)
 		for _, tx := range txs {
-			dump[fmt.Sprintf("%d", tx.Nonce())] = format(tx)
+			dump[fmt.Sprintf("%d", tx.Nonce())] = inspectPoolTransaction(tx)
 		}
 		content["queued"][account.Hex()] = dump
 	}
 	return content
 }
 
+// ContentFrom returns the transactions contained within the transaction pool
+// originating from a single account.
+func (s *PublicTxPoolAPI) ContentFrom(addr common.Address) map[string]map[string]*RPCTransaction {
+	content := map[string]map[string]*RPCTransaction{
+		"pending": make(map[string]*RPCTransaction),
+		"queued":  make(map[string]*RPCTransaction),
+	}
+	pending, queue := s.b.TxPoolContentFrom(addr)
+
+	for _, tx := range pending {
+		content["pending"][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+	}
+	for _, tx := range queue {
+		content["queued"][fmt.Sprintf("%d", tx.Nonce())] = newRPCPendingTransaction(tx)
+	}
+	return content
+}
+
+// InspectFrom retrieves the content of the transaction pool originating from a
+// single account and flattens it into an easily inspectable list.
+func (s *PublicTxPoolAPI) InspectFrom(addr common.Address) map[string]map[string]string {
+	content := map[string]map[string]string{
+		"pending": make(map[string]string),
+		"queued":  make(map[string]string),
+	}
+	pending, queue := s.b.TxPoolContentFrom(addr)
+
+	for _, tx := range pending {
+		content["pending"][fmt.Sprintf("%d", tx.Nonce())] = inspectPoolTransaction(tx)
+	}
+	for _, tx := range queue {
+		content["queued"][fmt.Sprintf("%d", tx.Nonce())] = inspectPoolTransaction(tx)
+	}
+	return content
+}
+
+// NonceGaps returns the next nonce the transaction pool expects from an account,
+// along with the ranges of nonces missing from its queued transactions that keep
+// them from becoming executable.
+func (s *PublicTxPoolAPI) NonceGaps(ctx context.Context, addr common.Address) (map[string]interface{}, error) {
+	nonce, gaps, err := s.b.TxPoolNonceGaps(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]map[string]hexutil.Uint64, len(gaps))
+	for i, gap := range gaps {
+		ranges[i] = map[string]hexutil.Uint64{
+			"from": hexutil.Uint64(gap.From),
+			"to":   hexutil.Uint64(gap.To),
+		}
+	}
+	return map[string]interface{}{
+		"nonce": hexutil.Uint64(nonce),
+		"gaps":  ranges,
+	}, nil
+}
+
+// inspectPoolTransaction flattens a pool transaction into a human readable string.
+func inspectPoolTransaction(tx *types.Transaction) string {
+	if to := tx.To(); to != nil {
+		return fmt.Sprintf("%s: %v wei + %v gas × %v wei", tx.To().Hex(), tx.Value(), tx.Gas(), tx.GasPrice())
+	}
+	return fmt.Sprintf("contract creation: %v wei + %v gas × %v wei", tx.Value(), tx.Gas(), tx.GasPrice())
+}
+
 // PublicAccountAPI provides an API to access accounts managed by this node.
 // It offers only methods that can retrieve accounts.
 type PublicAccountAPI struct {
@@ -580,23 +642,21 @@ type CallArgs struct {
 	Data     hexutil.Bytes   `json:"data"`
 }
 
-func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, uint64, bool, error) {
-	defer func(start time.Time) { log.Debug("Executing VVM call finished", "runtime", time.Since(start)) }(time.Now())
-
-	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
-	if state == nil || err != nil {
-		return nil, 0, false, err
-	}
-	// Set sender address or use a default if none specified
-	addr := args.From
-	if addr == (common.Address{}) {
-		if wallets := s.b.AccountManager().Wallets(); len(wallets) > 0 {
+// SetDefaultFrom sets the sender of the call to the first account of the first
+// wallet if none was specified.
+func (args *CallArgs) SetDefaultFrom(am *accounts.Manager) {
+	if args.From == (common.Address{}) {
+		if wallets := am.Wallets(); len(wallets) > 0 {
 			if accounts := wallets[0].Accounts(); len(accounts) > 0 {
-				addr = accounts[0].Address
+				args.From = accounts[0].Address
 			}
 		}
 	}
-	// Set default gas & gas price if none were set
+}
+
+// ToMessage converts the call arguments into a call message, setting the default
+// gas and gas price if none were specified.
+func (args *CallArgs) ToMessage() types.Message {
 	gas, gasPrice := uint64(args.Gas), args.GasPrice.ToInt()
 	if gas == 0 {
 		gas = 50000000
@@ -604,9 +664,21 @@ func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr
 	if gasPrice.Sign() == 0 {
 		gasPrice = new(big.Int).SetUint64(defaultGasPrice)
 	}
+	return types.NewMessage(args.From, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+}
+
+func (s *PublicBlockChainAPI) doCall(ctx context.Context, args CallArgs, blockNr rpc.BlockNumber, vmCfg vm.Config) ([]byte, uint64, bool, error) {
+	defer func(start time.Time) { log.Debug("Executing VVM call finished", "runtime", time.Since(start)) }(time.Now())
+
+	state, header, err := s.b.StateAndHeaderByNumber(ctx, blockNr)
+	if state == nil || err != nil {
+		return nil, 0, false, err
+	}
+	// Set sender address or use a default if none specified
+	args.SetDefaultFrom(s.b.AccountManager())
 
 	// Create new call message
-	msg := types.NewMessage(addr, args.To, 0, args.Value.ToInt(), gas, gasPrice, args.Data, false)
+	msg := args.ToMessage()
 
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -1367,7 +1439,7 @@ func (api *PrivateDebugAPI) ChaindbProperty(property string) (string, error) {
 	ldb, ok := api.b.ChainDb().(interface {
 		LDB() *leveldb.DB
 	})
-	if !ok {
+	if !ok || ldb.LDB() == nil {
 		return "", fmt.Errorf("chaindbProperty does not work for memory databases")
 	}
 	if property == "" {
@@ -1382,7 +1454,7 @@ func (api *PrivateDebugAPI) ChaindbCompact() error {
 	ldb, ok := api.b.ChainDb().(interface {
 		LDB() *leveldb.DB
 	})
-	if !ok {
+	if !ok || ldb.LDB() == nil {
 		return fmt.Errorf("chaindbCompact does not work for memory databases")
 	}
 	for b := byte(0); b < 255; b++ {
@@ -24,6 +24,7 @@ import (
 	"github.com/vaporyco/go-vapory/accounts"
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/core"
+	"github.com/vaporyco/go-vapory/core/bloombits"
 	"github.com/vaporyco/go-vapory/core/state"
 	"github.com/vaporyco/go-vapory/core/types"
 	"github.com/vaporyco/go-vapory/core/vm"
@@ -65,8 +66,16 @@ type Backend interface {
 	GetPoolNonce(ctx context.Context, addr common.Address) (uint64, error)
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
+	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
+	TxPoolNonceGaps(ctx context.Context, addr common.Address) (uint64, []core.NonceGap, error)
 	SubscribeTxPreEvent(chan<- core.TxPreEvent) event.Subscription
 
+	// Filter API
+	BloomStatus() (uint64, uint64)
+	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
+	SubscribeLogsEvent(ch chan<- []*types.Log) event.Subscription
+	SubscribeRemovedLogsEvent(ch chan<- core.RemovedLogsEvent) event.Subscription
+
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *types.Block
 }
@@ -18,18 +18,19 @@
 package web3ext
 
 var Modules = map[string]string{
-	"admin":      Admin_JS,
-	"chequebook": Chequebook_JS,
-	"clique":     Clique_JS,
-	"debug":      Debug_JS,
-	"vap":        Vap_JS,
-	"miner":      Miner_JS,
-	"net":        Net_JS,
-	"personal":   Personal_JS,
-	"rpc":        RPC_JS,
-	"shh":        Shh_JS,
-	"swarmfs":    SWARMFS_JS,
-	"txpool":     TxPool_JS,
+	"admin":       Admin_JS,
+	"chequebook":  Chequebook_JS,
+	"clique":      Clique_JS,
+	"debug":       Debug_JS,
+	"vap":         Vap_JS,
+	"miner":       Miner_JS,
+	"net":         Net_JS,
+	"personal":    Personal_JS,
+	"rpc":         RPC_JS,
+	"shh":         Shh_JS,
+	"swarmfs":     SWARMFS_JS,
+	"txpool":      TxPool_JS,
+	"txpooladmin": TxPoolAdmin_JS,
 }
 
 const Chequebook_JS = `
@@ -142,8 +143,8 @@ web3._extend({
 		new web3._extend.Method({
 			name: 'startRPC',
 			call: 'admin_startRPC',
-			params: 4,
-			inputFormatter: [null, null, null, null]
+			params: 5,
+			inputFormatter: [null, null, null, null, null]
 		}),
 		new web3._extend.Method({
 			name: 'stopRPC',
@@ -342,6 +343,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceCall',
+			call: 'debug_traceCall',
+			params: 3,
+			inputFormatter: [null, null, null]
+		}),
 		new web3._extend.Method({
 			name: 'preimage',
 			call: 'debug_preimage',
@@ -587,7 +594,34 @@ web3._extend({
 const TxPool_JS = `
 web3._extend({
 	property: 'txpool',
-	methods: [],
+	methods: [
+		new web3._extend.Method({
+			name: 'contentFrom',
+			call: 'txpool_contentFrom',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'inspectFrom',
+			call: 'txpool_inspectFrom',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'nonceGaps',
+			call: 'txpool_nonceGaps',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter],
+			outputFormatter: function(status) {
+				status.nonce = web3._extend.utils.toDecimal(status.nonce);
+				for (var i = 0; i < status.gaps.length; i++) {
+					status.gaps[i].from = web3._extend.utils.toDecimal(status.gaps[i].from);
+					status.gaps[i].to = web3._extend.utils.toDecimal(status.gaps[i].to);
+				}
+				return status;
+			}
+		}),
+	],
 	properties:
 	[
 		new web3._extend.Property({
@@ -610,3 +644,42 @@ web3._extend({
 	]
 });
 `
+
+const TxPoolAdmin_JS = `
+web3._extend({
+	property: 'txpooladmin',
+	methods: [
+		new web3._extend.Method({
+			name: 'addPriority',
+			call: 'txpooladmin_addPriority',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'removePriority',
+			call: 'txpooladmin_removePriority',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'setPrioritySlots',
+			call: 'txpooladmin_setPrioritySlots',
+			params: 1,
+			inputFormatter: [web3._extend.utils.fromDecimal]
+		}),
+		new web3._extend.Method({
+			name: 'setAccountLimits',
+			call: 'txpooladmin_setAccountLimits',
+			params: 2,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter, null]
+		}),
+	],
+	properties:
+	[
+		new web3._extend.Property({
+			name: 'lanes',
+			getter: 'txpooladmin_lanes'
+		}),
+	]
+});
+`
@@ -125,6 +125,16 @@ func (b *LesApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.vap.txPool.Content()
 }
 
+func (b *LesApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.vap.txPool.ContentFrom(addr)
+}
+
+func (b *LesApiBackend) TxPoolNonceGaps(ctx context.Context, addr common.Address) (uint64, []core.NonceGap, error) {
+	// There are no queued transactions in a light pool, so there can't be any gaps
+	nonce, err := b.vap.txPool.GetNonce(ctx, addr)
+	return nonce, nil, err
+}
+
 func (b *LesApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.vap.txPool.SubscribeTxPreEvent(ch)
 }
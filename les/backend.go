@@ -73,6 +73,7 @@ type LightVapory struct {
 
 	networkId     uint64
 	netRPCService *vapapi.PublicNetAPI
+	filterLimits  filters.Limits
 
 	wg sync.WaitGroup
 }
@@ -101,6 +102,7 @@ func New(ctx *node.ServiceContext, config *vap.Config) (*LightVapory, error) {
 		engine:           vap.CreateConsensusEngine(ctx, &config.Vapash, chainConfig, chainDb),
 		shutdownChan:     make(chan bool),
 		networkId:        config.NetworkId,
+		filterLimits:     filters.Limits{MaxBlocks: config.FilterMaxBlocks, MaxLogs: config.FilterMaxLogs},
 		bloomRequests:    make(chan chan *bloombits.Retrieval),
 		bloomIndexer:     vap.NewBloomIndexer(chainDb, light.BloomTrieFrequency),
 		chtIndexer:       light.NewChtIndexer(chainDb, true),
@@ -187,7 +189,7 @@ func (s *LightVapory) APIs() []rpc.API {
 		}, {
 			Namespace: "vap",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true),
+			Service:   filters.NewPublicFilterAPI(s.ApiBackend, true, s.filterLimits),
 			Public:    true,
 		}, {
 			Namespace: "net",
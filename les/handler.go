@@ -85,6 +85,7 @@ type BlockChain interface {
 	GetBlockHashesFromHash(hash common.Hash, max uint64) []common.Hash
 	LastBlockHash() common.Hash
 	Genesis() *types.Block
+	State() (*state.StateDB, error)
 	SubscribeChainHeadEvent(ch chan<- core.ChainHeadEvent) event.Subscription
 }
 
@@ -569,11 +570,16 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		for _, req := range req.Reqs {
 			// Retrieve the requested state entry, stopping if enough was found
 			if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-				if trie, _ := trie.New(header.Root, pm.chainDb); trie != nil {
+				statedb, err := pm.blockchain.State()
+				if err != nil {
+					continue
+				}
+				triedb := statedb.Database().TrieDB()
+				if trie, _ := trie.New(header.Root, triedb); trie != nil {
 					sdata := trie.Get(req.AccKey)
 					var acc state.Account
 					if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-						entry, _ := pm.chainDb.Get(acc.CodeHash)
+						entry, _ := triedb.Node(common.BytesToHash(acc.CodeHash))
 						if bytes+len(entry) >= softResponseLimit {
 							break
 						}
@@ -696,13 +702,18 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			}
 			// Retrieve the requested state entry, stopping if enough was found
 			if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-				if tr, _ := trie.New(header.Root, pm.chainDb); tr != nil {
+				statedb, err := pm.blockchain.State()
+				if err != nil {
+					continue
+				}
+				triedb := statedb.Database().TrieDB()
+				if tr, _ := trie.New(header.Root, triedb); tr != nil {
 					if len(req.AccKey) > 0 {
 						sdata := tr.Get(req.AccKey)
 						tr = nil
 						var acc state.Account
 						if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-							tr, _ = trie.New(acc.Root, pm.chainDb)
+							tr, _ = trie.New(acc.Root, triedb)
 						}
 					}
 					if tr != nil {
@@ -733,6 +744,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 			lastBHash  common.Hash
 			lastAccKey []byte
 			tr, str    *trie.Trie
+			triedb     *trie.Database
 		)
 		reqCnt := len(req.Reqs)
 		if reject(uint64(reqCnt), MaxProofsFetch) {
@@ -746,10 +758,12 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				break
 			}
 			if tr == nil || req.BHash != lastBHash {
+				tr = nil
 				if header := core.GetHeader(pm.chainDb, req.BHash, core.GetBlockNumber(pm.chainDb, req.BHash)); header != nil {
-					tr, _ = trie.New(header.Root, pm.chainDb)
-				} else {
-					tr = nil
+					if statedb, err := pm.blockchain.State(); err == nil {
+						triedb = statedb.Database().TrieDB()
+						tr, _ = trie.New(header.Root, triedb)
+					}
 				}
 				lastBHash = req.BHash
 				str = nil
@@ -761,7 +775,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 						str = nil
 						var acc state.Account
 						if err := rlp.DecodeBytes(sdata, &acc); err == nil {
-							str, _ = trie.New(acc.Root, pm.chainDb)
+							str, _ = trie.New(acc.Root, triedb)
 						}
 						lastAccKey = common.CopyBytes(req.AccKey)
 					}
@@ -839,7 +853,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 		if reject(uint64(reqCnt), MaxHelperTrieProofsFetch) {
 			return errResp(ErrRequestRejected, "")
 		}
-		trieDb := vapdb.NewTable(pm.chainDb, light.ChtTablePrefix)
+		trieDb := trie.NewDatabase(vapdb.NewTable(pm.chainDb, light.ChtTablePrefix))
 		for _, req := range req.Reqs {
 			if bytes >= softResponseLimit {
 				break
@@ -900,7 +914,7 @@ func (pm *ProtocolManager) handleMsg(p *peer) error {
 				var prefix string
 				root, prefix = pm.getHelperTrie(req.HelperTrieType, req.TrieIdx)
 				if root != (common.Hash{}) {
-					if t, err := trie.New(root, vapdb.NewTable(pm.chainDb, prefix)); err == nil {
+					if t, err := trie.New(root, trie.NewDatabase(vapdb.NewTable(pm.chainDb, prefix))); err == nil {
 						tr = t
 					}
 				}
@@ -23,11 +23,11 @@ import (
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/crypto"
 	"github.com/vaporyco/go-vapory/rlp"
-	"github.com/vaporyco/go-vapory/trie"
+	"github.com/vaporyco/go-vapory/vapdb"
 )
 
-// NodeSet stores a set of trie nodes. It implements trie.Database and can also
-// act as a cache for another trie.Database.
+// NodeSet stores a set of trie nodes. It implements trie.DatabaseReader and
+// vapdb.Putter, and can also act as a cache for another database.
 type NodeSet struct {
 	db       map[string][]byte
 	dataSize int
@@ -99,7 +99,7 @@ func (db *NodeSet) NodeList() NodeList {
 }
 
 // Store writes the contents of the set to the given database
-func (db *NodeSet) Store(target trie.Database) {
+func (db *NodeSet) Store(target vapdb.Putter) {
 	db.lock.RLock()
 	defer db.lock.RUnlock()
 
@@ -108,11 +108,11 @@ func (db *NodeSet) Store(target trie.Database) {
 	}
 }
 
-// NodeList stores an ordered list of trie nodes. It implements trie.DatabaseWriter.
+// NodeList stores an ordered list of trie nodes. It implements vapdb.Putter.
 type NodeList []rlp.RawValue
 
 // Store writes the contents of the list to the given database
-func (n NodeList) Store(db trie.Database) {
+func (n NodeList) Store(db vapdb.Putter) {
 	for _, node := range n {
 		db.Put(crypto.Keccak256(node), node)
 	}
@@ -114,6 +114,7 @@ func StoreChtRoot(db vapdb.Database, sectionIdx uint64, sectionHead, root common
 // ChtIndexerBackend implements core.ChainIndexerBackend
 type ChtIndexerBackend struct {
 	db, cdb              vapdb.Database
+	triedb               *trie.Database
 	section, sectionSize uint64
 	lastHash             common.Hash
 	trie                 *trie.Trie
@@ -131,7 +132,13 @@ func NewChtIndexer(db vapdb.Database, clientMode bool) *core.ChainIndexer {
 		sectionSize = ChtV1Frequency
 		confirmReq = HelperTrieProcessConfirmations
 	}
-	return core.NewChainIndexer(db, idb, &ChtIndexerBackend{db: db, cdb: cdb, sectionSize: sectionSize}, sectionSize, confirmReq, time.Millisecond*100, "cht")
+	backend := &ChtIndexerBackend{
+		db:          db,
+		cdb:         cdb,
+		triedb:      trie.NewDatabase(cdb),
+		sectionSize: sectionSize,
+	}
+	return core.NewChainIndexer(db, idb, backend, sectionSize, confirmReq, time.Millisecond*100, "cht")
 }
 
 // Reset implements core.ChainIndexerBackend
@@ -141,7 +148,7 @@ func (c *ChtIndexerBackend) Reset(section uint64, lastSectionHead common.Hash) e
 		root = GetChtRoot(c.db, section-1, lastSectionHead)
 	}
 	var err error
-	c.trie, err = trie.New(root, c.cdb)
+	c.trie, err = trie.New(root, c.triedb)
 	c.section = section
 	return err
 }
@@ -163,12 +170,12 @@ func (c *ChtIndexerBackend) Process(header *types.Header) {
 
 // Commit implements core.ChainIndexerBackend
 func (c *ChtIndexerBackend) Commit() error {
-	batch := c.cdb.NewBatch()
-	root, err := c.trie.CommitTo(batch)
+	root, err := c.trie.Commit(nil)
 	if err != nil {
 		return err
 	} else {
-		batch.Write()
+		c.triedb.Commit(root, false)
+
 		if ((c.section+1)*c.sectionSize)%ChtFrequency == 0 {
 			log.Info("Storing CHT", "idx", c.section*c.sectionSize/ChtFrequency, "sectionHead", fmt.Sprintf("%064x", c.lastHash), "root", fmt.Sprintf("%064x", root))
 		}
@@ -206,6 +213,7 @@ func StoreBloomTrieRoot(db vapdb.Database, sectionIdx uint64, sectionHead, root
 // BloomTrieIndexerBackend implements core.ChainIndexerBackend
 type BloomTrieIndexerBackend struct {
 	db, cdb                                    vapdb.Database
+	triedb                                     *trie.Database
 	section, parentSectionSize, bloomTrieRatio uint64
 	trie                                       *trie.Trie
 	sectionHeads                               []common.Hash
@@ -215,7 +223,11 @@ type BloomTrieIndexerBackend struct {
 func NewBloomTrieIndexer(db vapdb.Database, clientMode bool) *core.ChainIndexer {
 	cdb := vapdb.NewTable(db, BloomTrieTablePrefix)
 	idb := vapdb.NewTable(db, "bltIndex-")
-	backend := &BloomTrieIndexerBackend{db: db, cdb: cdb}
+	backend := &BloomTrieIndexerBackend{
+		db:     db,
+		cdb:    cdb,
+		triedb: trie.NewDatabase(cdb),
+	}
 	var confirmReq uint64
 	if clientMode {
 		backend.parentSectionSize = BloomTrieFrequency
@@ -236,7 +248,7 @@ func (b *BloomTrieIndexerBackend) Reset(section uint64, lastSectionHead common.H
 		root = GetBloomTrieRoot(b.db, section-1, lastSectionHead)
 	}
 	var err error
-	b.trie, err = trie.New(root, b.cdb)
+	b.trie, err = trie.New(root, b.triedb)
 	b.section = section
 	return err
 }
@@ -280,12 +292,12 @@ func (b *BloomTrieIndexerBackend) Commit() error {
 		}
 	}
 
-	batch := b.cdb.NewBatch()
-	root, err := b.trie.CommitTo(batch)
+	root, err := b.trie.Commit(nil)
 	if err != nil {
 		return err
 	} else {
-		batch.Write()
+		b.triedb.Commit(root, false)
+
 		sectionHead := b.sectionHeads[b.bloomTrieRatio-1]
 		log.Info("Storing BloomTrie", "section", b.section, "sectionHead", fmt.Sprintf("%064x", sectionHead), "root", fmt.Sprintf("%064x", root), "compression ratio", float64(compSize)/float64(decompSize))
 		StoreBloomTrieRoot(b.db, b.section, sectionHead, root)
@@ -20,6 +20,7 @@ package gvap
 
 import (
 	"errors"
+	"time"
 
 	vapory "github.com/vaporyco/go-vapory"
 	"github.com/vaporyco/go-vapory/common"
@@ -82,6 +83,8 @@ func (p *SyncProgress) GetCurrentBlock() int64  { return int64(p.progress.Curren
 func (p *SyncProgress) GetHighestBlock() int64  { return int64(p.progress.HighestBlock) }
 func (p *SyncProgress) GetPulledStates() int64  { return int64(p.progress.PulledStates) }
 func (p *SyncProgress) GetKnownStates() int64   { return int64(p.progress.KnownStates) }
+func (p *SyncProgress) GetPercentage() float64  { return p.progress.Percentage }
+func (p *SyncProgress) GetETA() int64           { return int64(p.progress.ETA / time.Second) }
 
 // Topics is a set of topic lists to filter events with.
 type Topics struct{ topics [][]common.Hash }
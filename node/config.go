@@ -24,8 +24,10 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/vaporyco/go-vapory/accounts"
+	"github.com/vaporyco/go-vapory/accounts/external"
 	"github.com/vaporyco/go-vapory/accounts/keystore"
 	"github.com/vaporyco/go-vapory/accounts/usbwallet"
 	"github.com/vaporyco/go-vapory/common"
@@ -33,6 +35,7 @@ import (
 	"github.com/vaporyco/go-vapory/log"
 	"github.com/vaporyco/go-vapory/p2p"
 	"github.com/vaporyco/go-vapory/p2p/discover"
+	"github.com/vaporyco/go-vapory/rpc"
 )
 
 const (
@@ -85,6 +88,10 @@ type Config struct {
 	// NoUSB disables hardware wallet monitoring and connectivity.
 	NoUSB bool `toml:",omitempty"`
 
+	// ExternalSigner is the IPC path or HTTP URL of an external signer process to
+	// forward signing requests to, in addition to the local keystore.
+	ExternalSigner string `toml:",omitempty"`
+
 	// IPCPath is the requested location to place the IPC endpoint. If the path is
 	// a simple file name, it is placed inside the data directory (or on the root
 	// pipe path on Windows), whereas if it's a resolvable path name (absolute or
@@ -105,6 +112,15 @@ type Config struct {
 	// useless for custom HTTP clients.
 	HTTPCors []string `toml:",omitempty"`
 
+	// HTTPVirtualHosts is the list of virtual hostnames which are allowed on incoming
+	// requests. This is by default {'localhost'}. Using this prevents attacks like
+	// DNS rebinding, which bypasses SOP by simply masquerading as being within the
+	// same origin. These attacks do not utilize CORS, since they are not cross-domain.
+	// By explicitly checking the Host-header, the server will not allow requests
+	// made against the server with a malicious host domain. Requests using an IP
+	// address directly are not affected.
+	HTTPVirtualHosts []string `toml:",omitempty"`
+
 	// HTTPModules is a list of API modules to expose via the HTTP RPC interface.
 	// If the module list is empty, all RPC API endpoints designated public will be
 	// exposed.
@@ -136,6 +152,47 @@ type Config struct {
 	// private APIs to untrusted users is a major security risk.
 	WSExposeAll bool `toml:",omitempty"`
 
+	// GraphQLHost is the host interface on which to start the GraphQL server. If
+	// this field is empty, no GraphQL endpoint will be started.
+	GraphQLHost string `toml:",omitempty"`
+
+	// GraphQLPort is the TCP port number on which to start the GraphQL server. The
+	// default zero value is/ valid and will pick a port number randomly (useful
+	// for ephemeral nodes).
+	GraphQLPort int `toml:",omitempty"`
+
+	// GraphQLCors is the Cross-Origin Resource Sharing header to send to requesting
+	// clients of the GraphQL server.
+	GraphQLCors []string `toml:",omitempty"`
+
+	// GraphQLVirtualHosts is the list of virtual hostnames which are allowed on
+	// incoming requests to the GraphQL server, see HTTPVirtualHosts.
+	GraphQLVirtualHosts []string `toml:",omitempty"`
+
+	// RPCAuth is the list of credentials accepted from callers of the HTTP and
+	// websocket RPC interfaces, each restricted to a set of API namespaces and
+	// methods. If the list is empty, no authentication is required.
+	RPCAuth []rpc.Credential `toml:",omitempty"`
+
+	// RPCBatchLimit is the maximum number of requests accepted in a single batch
+	// by the HTTP and websocket RPC interfaces. Zero means no limit.
+	RPCBatchLimit int `toml:",omitempty"`
+
+	// RPCResponseLimit is the maximum size in bytes of a response (or a batch of
+	// responses) written by the HTTP and websocket RPC interfaces. Zero means no
+	// limit.
+	RPCResponseLimit int `toml:",omitempty"`
+
+	// RPCCallTimeout is the maximum execution time of a single method call over
+	// the HTTP and websocket RPC interfaces, after which the call is cancelled and
+	// an error returned. Zero means no limit.
+	RPCCallTimeout time.Duration `toml:",omitempty"`
+
+	// RPCRateLimits configures the per client token bucket limiting the rate of
+	// requests served by the HTTP and websocket RPC interfaces, along with the
+	// number of tokens consumed by expensive methods. A zero rate means no limit.
+	RPCRateLimits rpc.RateLimits `toml:",omitempty"`
+
 	// Logger is a custom logger to use with the p2p.Server.
 	Logger log.Logger
 }
@@ -215,6 +272,15 @@ func DefaultWSEndpoint() string {
 	return config.WSEndpoint()
 }
 
+// GraphQLEndpoint resolves a GraphQL endpoint based on the configured host
+// interface and port parameters.
+func (c *Config) GraphQLEndpoint() string {
+	if c.GraphQLHost == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", c.GraphQLHost, c.GraphQLPort)
+}
+
 // NodeName returns the devp2p node identifier.
 func (c *Config) NodeName() string {
 	name := c.name()
@@ -424,5 +490,13 @@ func makeAccountManager(conf *Config) (*accounts.Manager, string, error) {
 			backends = append(backends, trezorhub)
 		}
 	}
+	if conf.ExternalSigner != "" {
+		// Connect to the external signer holding the keys out of process
+		extapi, err := external.NewExternalBackend(conf.ExternalSigner)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to connect to external signer: %v", err)
+		}
+		backends = append(backends, extapi)
+	}
 	return accounts.NewManager(backends...), ephemeral, nil
 }
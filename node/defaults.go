@@ -24,6 +24,7 @@ import (
 
 	"github.com/vaporyco/go-vapory/p2p"
 	"github.com/vaporyco/go-vapory/p2p/nat"
+	"github.com/vaporyco/go-vapory/rpc"
 )
 
 const (
@@ -31,15 +32,36 @@ const (
 	DefaultHTTPPort = 8575        // Default TCP port for the HTTP RPC server
 	DefaultWSHost   = "localhost" // Default host interface for the websocket RPC server
 	DefaultWSPort   = 8576        // Default TCP port for the websocket RPC server
+
+	DefaultGraphQLHost = "localhost" // Default host interface for the GraphQL server
+	DefaultGraphQLPort = 8577        // Default TCP port for the GraphQL server
 )
 
 // DefaultConfig contains reasonable default settings.
 var DefaultConfig = Config{
-	DataDir:     DefaultDataDir(),
-	HTTPPort:    DefaultHTTPPort,
-	HTTPModules: []string{"net", "web3"},
-	WSPort:      DefaultWSPort,
-	WSModules:   []string{"net", "web3"},
+	DataDir:             DefaultDataDir(),
+	HTTPPort:            DefaultHTTPPort,
+	HTTPModules:         []string{"net", "web3"},
+	HTTPVirtualHosts:    []string{"localhost"},
+	WSPort:              DefaultWSPort,
+	WSModules:           []string{"net", "web3"},
+	GraphQLPort:         DefaultGraphQLPort,
+	GraphQLVirtualHosts: []string{"localhost"},
+	RPCBatchLimit:       1000,
+	RPCResponseLimit:    25 * 1024 * 1024,
+	RPCRateLimits: rpc.RateLimits{
+		Burst: 100,
+		Costs: map[string]int{
+			"vap_getLogs":              10,
+			"vap_getFilterLogs":        10,
+			"vap_call":                 5,
+			"vap_estimateGas":          5,
+			"debug_traceBlock":         50,
+			"debug_traceBlockByNumber": 50,
+			"debug_traceBlockByHash":   50,
+			"debug_traceTransaction":   20,
+		},
+	},
 	P2P: p2p.Config{
 		ListenAddr: ":10801",
 		MaxPeers:   25,
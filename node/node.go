@@ -67,6 +67,8 @@ type Node struct {
 	wsListener net.Listener // Websocket RPC listener socket to server API requests
 	wsHandler  *rpc.Server  // Websocket RPC request handler to process the API requests
 
+	rpcAuth rpc.Authenticator // Authenticator for HTTP and websocket callers (nil = no authentication)
+
 	stop chan struct{} // Channel to wait for termination notifications
 	lock sync.RWMutex
 
@@ -97,6 +99,14 @@ func New(conf *Config) (*Node, error) {
 	if strings.HasSuffix(conf.Name, ".ipc") {
 		return nil, errors.New(`Config.Name cannot end in ".ipc"`)
 	}
+	// Ensure the RPC credentials are valid before touching anything on disk
+	var auth rpc.Authenticator
+	if len(conf.RPCAuth) > 0 {
+		var err error
+		if auth, err = rpc.NewAuthenticator(conf.RPCAuth); err != nil {
+			return nil, err
+		}
+	}
 	// Ensure that the AccountManager method works before the node has started.
 	// We rely on this in cmd/gvap.
 	am, ephemeralKeystore, err := makeAccountManager(conf)
@@ -116,6 +126,7 @@ func New(conf *Config) (*Node, error) {
 		ipcEndpoint:       conf.IPCEndpoint(),
 		httpEndpoint:      conf.HTTPEndpoint(),
 		wsEndpoint:        conf.WSEndpoint(),
+		rpcAuth:           auth,
 		eventmux:          new(event.TypeMux),
 		log:               conf.Logger,
 	}, nil
@@ -263,7 +274,7 @@ func (n *Node) startRPC(services map[reflect.Type]Service) error {
 		n.stopInProc()
 		return err
 	}
-	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors); err != nil {
+	if err := n.startHTTP(n.httpEndpoint, apis, n.config.HTTPModules, n.config.HTTPCors, n.config.HTTPVirtualHosts); err != nil {
 		n.stopIPC()
 		n.stopInProc()
 		return err
@@ -365,7 +376,7 @@ func (n *Node) stopIPC() {
 }
 
 // startHTTP initializes and starts the HTTP RPC endpoint.
-func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string) error {
+func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors []string, vhosts []string) error {
 	// Short circuit if the HTTP endpoint isn't being exposed
 	if endpoint == "" {
 		return nil
@@ -377,6 +388,11 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	if n.rpcAuth != nil {
+		handler.SetAuthenticator(n.rpcAuth)
+	}
+	handler.SetRequestLimits(n.config.RPCBatchLimit, n.config.RPCResponseLimit, n.config.RPCCallTimeout)
+	handler.SetRateLimits(n.config.RPCRateLimits)
 	for _, api := range apis {
 		if whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
@@ -393,7 +409,7 @@ func (n *Node) startHTTP(endpoint string, apis []rpc.API, modules []string, cors
 	if listener, err = net.Listen("tcp", endpoint); err != nil {
 		return err
 	}
-	go rpc.NewHTTPServer(cors, handler).Serve(listener)
+	go rpc.NewHTTPServer(cors, vhosts, handler).Serve(listener)
 	n.log.Info(fmt.Sprintf("HTTP endpoint opened: http://%s", endpoint))
 
 	// All listeners booted successfully
@@ -431,6 +447,11 @@ func (n *Node) startWS(endpoint string, apis []rpc.API, modules []string, wsOrig
 	}
 	// Register all the APIs exposed by the services
 	handler := rpc.NewServer()
+	if n.rpcAuth != nil {
+		handler.SetAuthenticator(n.rpcAuth)
+	}
+	handler.SetRequestLimits(n.config.RPCBatchLimit, n.config.RPCResponseLimit, n.config.RPCCallTimeout)
+	handler.SetRateLimits(n.config.RPCRateLimits)
 	for _, api := range apis {
 		if exposeAll || whitelist[api.Namespace] || (len(whitelist) == 0 && api.Public) {
 			if err := handler.RegisterName(api.Namespace, api.Service); err != nil {
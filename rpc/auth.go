@@ -0,0 +1,257 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+const (
+	// apiKeyHeader is the HTTP header carrying static API keys.
+	apiKeyHeader = "X-API-Key"
+
+	// bearerPrefix is the authorization scheme prefix of HMAC signed tokens.
+	bearerPrefix = "Bearer "
+
+	// minJWTSecretLength is the minimum number of bytes a token signing secret
+	// must have to be accepted.
+	minJWTSecretLength = 32
+)
+
+var (
+	// errNoCredentials is returned if a request to an authenticated endpoint
+	// does not carry any credentials.
+	errNoCredentials = errors.New("missing credentials")
+
+	// errInvalidCredentials is returned if a request to an authenticated endpoint
+	// carries credentials not matching any of the configured ones.
+	errInvalidCredentials = errors.New("invalid credentials")
+
+	// errTokenNoExpiry is returned if a bearer token does not carry an expiry
+	// time, which would make it valid forever.
+	errTokenNoExpiry = errors.New("token without expiry")
+)
+
+// Credential is a named secret granting access to a set of API namespaces and
+// methods over the HTTP and websocket transports. Exactly one of the API key or
+// the token signing secret must be set. If neither namespaces nor methods are
+// listed, all the APIs exposed on the transport are accessible.
+type Credential struct {
+	Name       string   // Identifier of the credential, used in logs
+	APIKey     string   `toml:",omitempty"` // Static key expected in the X-API-Key header
+	JWTSecret  string   `toml:",omitempty"` // Hex encoded HMAC secret signing bearer tokens
+	Namespaces []string `toml:",omitempty"` // API namespaces with all methods accessible (e.g. vap)
+	Methods    []string `toml:",omitempty"` // Individual methods accessible (e.g. admin_peers)
+}
+
+// Identity is an authenticated caller of an RPC endpoint along with the API
+// namespaces and methods it is permitted to invoke.
+type Identity struct {
+	Name string // Name of the credential the caller authenticated with
+
+	namespaces map[string]bool // API namespaces with all methods accessible
+	methods    map[string]bool // Individual methods accessible
+	expiry     time.Time       // Expiry of the bearer token authenticated with (zero for API keys)
+}
+
+// newIdentity creates an identity from the permissions listed in a credential.
+func newIdentity(cred *Credential) *Identity {
+	id := &Identity{
+		Name:       cred.Name,
+		namespaces: make(map[string]bool),
+		methods:    make(map[string]bool),
+	}
+	for _, namespace := range cred.Namespaces {
+		id.namespaces[namespace] = true
+	}
+	for _, method := range cred.Methods {
+		id.methods[method] = true
+	}
+	return id
+}
+
+// Allowed checks whether the identity is permitted to invoke the given method of
+// an API namespace. The RPC metadata API is always accessible.
+func (id *Identity) Allowed(namespace, method string) bool {
+	if namespace == MetadataApi {
+		return true
+	}
+	if len(id.namespaces) == 0 && len(id.methods) == 0 {
+		return true
+	}
+	return id.namespaces[namespace] || id.methods[namespace+serviceMethodSeparator+method]
+}
+
+// expired checks whether the bearer token the identity authenticated with has
+// expired since. Identities authenticated with an API key never expire.
+func (id *Identity) expired() bool {
+	return !id.expiry.IsZero() && !time.Now().Before(id.expiry)
+}
+
+// Authenticator verifies the credentials attached to an HTTP request (either a
+// plain RPC call or a websocket handshake), returning the identity of the caller.
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Identity, error)
+}
+
+// jwtSecret is an HMAC secret for verifying bearer tokens, along with the
+// identity tokens signed by it authenticate as.
+type jwtSecret struct {
+	secret []byte
+	id     *Identity
+}
+
+// credentialAuth is an authenticator accepting static API keys and HMAC signed
+// bearer tokens (JWT).
+type credentialAuth struct {
+	keys    map[string]*Identity // Static API keys mapped to their identities
+	secrets []*jwtSecret         // Secrets to verify bearer tokens with
+}
+
+// NewAuthenticator creates an authenticator accepting the given credentials.
+func NewAuthenticator(creds []Credential) (Authenticator, error) {
+	auth := &credentialAuth{
+		keys: make(map[string]*Identity),
+	}
+	names := make(map[string]bool)
+	for i := range creds {
+		cred := &creds[i]
+		if cred.Name == "" {
+			return nil, fmt.Errorf("credential #%d: missing name", i)
+		}
+		if names[cred.Name] {
+			return nil, fmt.Errorf("credential %q: duplicate name", cred.Name)
+		}
+		names[cred.Name] = true
+
+		switch {
+		case cred.APIKey != "" && cred.JWTSecret != "":
+			return nil, fmt.Errorf("credential %q: both API key and JWT secret set", cred.Name)
+
+		case cred.APIKey != "":
+			if _, ok := auth.keys[cred.APIKey]; ok {
+				return nil, fmt.Errorf("credential %q: duplicate API key", cred.Name)
+			}
+			auth.keys[cred.APIKey] = newIdentity(cred)
+
+		case cred.JWTSecret != "":
+			secret, err := hex.DecodeString(strings.TrimPrefix(cred.JWTSecret, "0x"))
+			if err != nil {
+				return nil, fmt.Errorf("credential %q: invalid JWT secret: %v", cred.Name, err)
+			}
+			if len(secret) < minJWTSecretLength {
+				return nil, fmt.Errorf("credential %q: JWT secret too short (%d<%d bytes)", cred.Name, len(secret), minJWTSecretLength)
+			}
+			auth.secrets = append(auth.secrets, &jwtSecret{secret: secret, id: newIdentity(cred)})
+
+		default:
+			return nil, fmt.Errorf("credential %q: neither API key nor JWT secret set", cred.Name)
+		}
+	}
+	return auth, nil
+}
+
+// Authenticate implements Authenticator, verifying the API key or bearer token
+// attached to the request.
+func (auth *credentialAuth) Authenticate(r *http.Request) (*Identity, error) {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		// Compare against all keys to avoid leaking anything through timing
+		var match *Identity
+		for known, id := range auth.keys {
+			if subtle.ConstantTimeCompare([]byte(known), []byte(key)) == 1 {
+				match = id
+			}
+		}
+		if match == nil {
+			return nil, errInvalidCredentials
+		}
+		return match, nil
+	}
+	if header := r.Header.Get("Authorization"); strings.HasPrefix(header, bearerPrefix) {
+		token := strings.TrimPrefix(header, bearerPrefix)
+		for _, secret := range auth.secrets {
+			if expiry, err := verifyBearerToken(token, secret.secret); err == nil {
+				// The identity is shared by all tokens of the secret, copy it
+				id := *secret.id
+				id.expiry = expiry
+				return &id, nil
+			}
+		}
+		return nil, errInvalidCredentials
+	}
+	return nil, errNoCredentials
+}
+
+// verifyBearerToken checks that a token was signed by the given secret with an
+// HMAC signing method, that it expires and that its time based claims are valid,
+// returning its expiry time.
+func verifyBearerToken(token string, secret []byte) (time.Time, error) {
+	claims := new(jwt.StandardClaims)
+	_, err := jwt.ParseWithClaims(token, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return secret, nil
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+	if claims.ExpiresAt == 0 {
+		return time.Time{}, errTokenNoExpiry
+	}
+	return time.Unix(claims.ExpiresAt, 0), nil
+}
+
+// NewBearerToken creates an HMAC signed bearer token (JWT) with the given secret,
+// valid for the given duration. Tokens without expiry are rejected by the server,
+// so the duration must be positive.
+func NewBearerToken(secret []byte, expiry time.Duration) (string, error) {
+	if expiry <= 0 {
+		return "", errTokenNoExpiry
+	}
+	now := time.Now()
+	claims := &jwt.StandardClaims{IssuedAt: now.Unix(), ExpiresAt: now.Add(expiry).Unix()}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+}
+
+// APIKeyHeader returns the HTTP header to authenticate with using a static API key.
+func APIKeyHeader(key string) http.Header {
+	return http.Header{apiKeyHeader: []string{key}}
+}
+
+// BearerHeader returns the HTTP header to authenticate with using a bearer token.
+func BearerHeader(token string) http.Header {
+	return http.Header{"Authorization": []string{bearerPrefix + token}}
+}
+
+type identityKey struct{}
+
+// identityFromContext retrieves the authenticated caller from the context, if the
+// request was received over an authenticated transport.
+func identityFromContext(ctx context.Context) *Identity {
+	id, _ := ctx.Value(identityKey{}).(*Identity)
+	return id
+}
@@ -0,0 +1,209 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+var (
+	testAPIKey    = "0123456789abcdef"
+	testJWTSecret = bytes.Repeat([]byte{0x42}, minJWTSecretLength)
+)
+
+// newAuthTestServer creates an RPC server requiring a full access API key, a
+// restricted API key or a bearer token restricted to a single method.
+func newAuthTestServer(t *testing.T) *Server {
+	server := newTestServer("service", new(Service))
+	auth, err := NewAuthenticator([]Credential{
+		{Name: "admin", APIKey: testAPIKey},
+		{Name: "restricted", APIKey: "restricted", Namespaces: []string{"other"}},
+		{Name: "token", JWTSecret: hex.EncodeToString(testJWTSecret), Methods: []string{"service_echo"}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server.SetAuthenticator(auth)
+	return server
+}
+
+// Tests that invalid credential configurations are rejected.
+func TestAuthenticatorConfig(t *testing.T) {
+	secret := hex.EncodeToString(testJWTSecret)
+	tests := []struct {
+		creds []Credential
+		fail  bool
+	}{
+		{[]Credential{{Name: "a", APIKey: "key"}, {Name: "b", JWTSecret: secret}}, false},
+		{[]Credential{{APIKey: "key"}}, true},
+		{[]Credential{{Name: "a"}}, true},
+		{[]Credential{{Name: "a", APIKey: "key", JWTSecret: secret}}, true},
+		{[]Credential{{Name: "a", APIKey: "key"}, {Name: "a", APIKey: "other"}}, true},
+		{[]Credential{{Name: "a", APIKey: "key"}, {Name: "b", APIKey: "key"}}, true},
+		{[]Credential{{Name: "a", JWTSecret: "0xzz"}}, true},
+		{[]Credential{{Name: "a", JWTSecret: "0x0102"}}, true},
+	}
+	for i, tt := range tests {
+		if _, err := NewAuthenticator(tt.creds); (err != nil) != tt.fail {
+			t.Errorf("test %d: failure mismatch: have %v, want failure %v", i, err, tt.fail)
+		}
+	}
+}
+
+// Tests that HTTP requests without valid credentials are rejected, and that
+// authenticated ones are restricted to the permitted methods.
+func TestHTTPAuthentication(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	hs := httptest.NewServer(server)
+	defer hs.Close()
+
+	token, err := NewBearerToken(testJWTSecret, time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create bearer token: %v", err)
+	}
+	claims := &jwt.StandardClaims{ExpiresAt: time.Now().Add(-time.Minute).Unix()}
+	expired, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testJWTSecret)
+	if err != nil {
+		t.Fatalf("failed to create bearer token: %v", err)
+	}
+	claims = &jwt.StandardClaims{IssuedAt: time.Now().Unix()}
+	eternal, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(testJWTSecret)
+	if err != nil {
+		t.Fatalf("failed to create bearer token: %v", err)
+	}
+	forged, err := NewBearerToken(bytes.Repeat([]byte{0x24}, minJWTSecretLength), time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create bearer token: %v", err)
+	}
+	tests := []struct {
+		header  http.Header
+		method  string
+		success bool
+	}{
+		{nil, "service_echo", false},
+		{APIKeyHeader("invalid"), "service_echo", false},
+		{APIKeyHeader(testAPIKey), "service_echo", true},
+		{APIKeyHeader(testAPIKey), "service_noArgsRets", true},
+		{APIKeyHeader("restricted"), "service_echo", false},
+		{APIKeyHeader("restricted"), "rpc_modules", true},
+		{BearerHeader(token), "service_echo", true},
+		{BearerHeader(token), "service_noArgsRets", false},
+		{BearerHeader(expired), "service_echo", false},
+		{BearerHeader(eternal), "service_echo", false},
+		{BearerHeader(forged), "service_echo", false},
+	}
+	for i, tt := range tests {
+		client, err := DialHTTPWithHeader(hs.URL, tt.header)
+		if err != nil {
+			t.Fatalf("test %d: failed to dial: %v", i, err)
+		}
+		switch tt.method {
+		case "service_echo":
+			var result Result
+			err = client.Call(&result, tt.method, "hello", 10, &Args{"world"})
+		case "service_noArgsRets":
+			err = client.Call(nil, tt.method)
+		default:
+			var result map[string]string
+			err = client.Call(&result, tt.method)
+		}
+		if (err == nil) != tt.success {
+			t.Errorf("test %d: result mismatch: have %v, want success %v", i, err, tt.success)
+		}
+		client.Close()
+	}
+}
+
+// Tests that websocket connections are authenticated during the handshake, and
+// that subscriptions are subject to the method restrictions.
+func TestWebsocketAuthentication(t *testing.T) {
+	server := newAuthTestServer(t)
+	defer server.Stop()
+
+	hs := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer hs.Close()
+
+	endpoint := "ws://" + hs.Listener.Addr().String()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := DialWebsocketWithHeader(ctx, endpoint, "", nil); err == nil {
+		t.Fatalf("unauthenticated websocket connection accepted")
+	}
+	token, _ := NewBearerToken(testJWTSecret, time.Minute)
+	client, err := DialWebsocketWithHeader(ctx, endpoint, "", BearerHeader(token))
+	if err != nil {
+		t.Fatalf("failed to dial with bearer token: %v", err)
+	}
+	defer client.Close()
+
+	var result Result
+	if err := client.Call(&result, "service_echo", "hello", 10, &Args{"world"}); err != nil {
+		t.Errorf("permitted call failed: %v", err)
+	}
+	if _, err := client.Subscribe(ctx, "service", make(chan int), "subscription"); err == nil {
+		t.Errorf("forbidden subscription succeeded")
+	}
+}
+
+// Tests that websocket connections authenticated with a bearer token are closed
+// once the token expires, ending their subscriptions.
+func TestWebsocketTokenExpiry(t *testing.T) {
+	server := newTestServer("vap", new(NotificationTestService))
+	defer server.Stop()
+	auth, err := NewAuthenticator([]Credential{{Name: "token", JWTSecret: hex.EncodeToString(testJWTSecret)}})
+	if err != nil {
+		t.Fatalf("failed to create authenticator: %v", err)
+	}
+	server.SetAuthenticator(auth)
+
+	hs := httptest.NewServer(server.WebsocketHandler([]string{"*"}))
+	defer hs.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, _ := NewBearerToken(testJWTSecret, 2*time.Second)
+	client, err := DialWebsocketWithHeader(ctx, "ws://"+hs.Listener.Addr().String(), "", BearerHeader(token))
+	if err != nil {
+		t.Fatalf("failed to dial with bearer token: %v", err)
+	}
+	defer client.Close()
+
+	sub, err := client.VapSubscribe(ctx, make(chan int), "someSubscription", 1, 0)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	select {
+	case <-sub.Err():
+	case <-ctx.Done():
+		t.Fatalf("subscription not ended after token expiry")
+	}
+	if err := client.Call(nil, "vap_echo", 1); err == nil {
+		t.Errorf("call succeeded after token expiry")
+	}
+}
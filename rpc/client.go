@@ -24,6 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"reflect"
 	"strconv"
@@ -162,15 +163,23 @@ func Dial(rawurl string) (*Client, error) {
 // The context is used to cancel or time out the initial connection establishment. It does
 // not affect subsequent interactions with the client.
 func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	return DialContextWithHeader(ctx, rawurl, nil)
+}
+
+// DialContextWithHeader creates a new RPC client just like DialContext, sending
+// the given headers (e.g. authentication credentials, see APIKeyHeader and
+// BearerHeader) with every HTTP request or in the websocket handshake. The
+// headers are ignored for IPC connections.
+func DialContextWithHeader(ctx context.Context, rawurl string, header http.Header) (*Client, error) {
 	u, err := url.Parse(rawurl)
 	if err != nil {
 		return nil, err
 	}
 	switch u.Scheme {
 	case "http", "https":
-		return DialHTTP(rawurl)
+		return DialHTTPWithHeader(rawurl, header)
 	case "ws", "wss":
-		return DialWebsocket(ctx, rawurl, "")
+		return DialWebsocketWithHeader(ctx, rawurl, "", header)
 	case "":
 		return DialIPC(ctx, rawurl)
 	default:
@@ -58,6 +58,48 @@ func (e *callbackError) ErrorCode() int { return -32000 }
 
 func (e *callbackError) Error() string { return e.message }
 
+// issued when an authenticated caller is not permitted to invoke a method
+type unauthorizedError struct {
+	service string
+	method  string
+}
+
+func (e *unauthorizedError) ErrorCode() int { return -32001 }
+
+func (e *unauthorizedError) Error() string {
+	return fmt.Sprintf("The method %s%s%s is not permitted", e.service, serviceMethodSeparator, e.method)
+}
+
+// issued when the bearer token a connection authenticated with has expired
+type credentialsExpiredError struct{}
+
+func (e *credentialsExpiredError) ErrorCode() int { return -32001 }
+
+func (e *credentialsExpiredError) Error() string { return "credentials expired" }
+
+// issued when a method call does not complete within the configured timeout
+type timeoutError struct{}
+
+func (e *timeoutError) ErrorCode() int { return -32002 }
+
+func (e *timeoutError) Error() string { return "request timed out" }
+
+// issued when a response exceeds the configured size limit
+type responseTooLargeError struct{ limit int }
+
+func (e *responseTooLargeError) ErrorCode() int { return -32003 }
+
+func (e *responseTooLargeError) Error() string {
+	return fmt.Sprintf("response too large (limit %d bytes)", e.limit)
+}
+
+// issued when a client exceeds its request rate limit
+type rateLimitedError struct{}
+
+func (e *rateLimitedError) ErrorCode() int { return -32004 }
+
+func (e *rateLimitedError) Error() string { return "request rate limit exceeded" }
+
 // issued when a request is received after the server is issued to stop.
 type shutdownError struct{}
 
@@ -52,3 +52,31 @@ func testHTTPErrorResponse(t *testing.T, method, contentType, body string, expec
 		t.Fatalf("response code should be %d not %d", expected, code)
 	}
 }
+
+func TestHTTPVirtualHosts(t *testing.T) {
+	tests := []struct {
+		vhosts []string
+		host   string
+		code   int
+	}{
+		{[]string{"localhost"}, "localhost:8575", http.StatusOK},
+		{[]string{"localhost"}, "LOCALHOST", http.StatusOK},
+		{[]string{"localhost"}, "127.0.0.1:8575", http.StatusOK},
+		{[]string{"localhost"}, "[::1]:8575", http.StatusOK},
+		{[]string{"localhost"}, "evil.example.com", http.StatusForbidden},
+		{[]string{"localhost"}, "evil.example.com:8575", http.StatusForbidden},
+		{nil, "localhost", http.StatusForbidden},
+		{[]string{"*"}, "evil.example.com", http.StatusOK},
+	}
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for i, tt := range tests {
+		request := httptest.NewRequest(http.MethodPost, "http://url.com", nil)
+		request.Host = tt.host
+
+		recorder := httptest.NewRecorder()
+		newVHostHandler(tt.vhosts, next).ServeHTTP(recorder, request)
+		if recorder.Code != tt.code {
+			t.Errorf("test %d: response code mismatch: have %d, want %d", i, recorder.Code, tt.code)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+// Contains the meters and timers used by the RPC server.
+
+package rpc
+
+import (
+	"sync"
+	"time"
+
+	gometrics "github.com/rcrowley/go-metrics"
+	"github.com/vaporyco/go-vapory/metrics"
+)
+
+var (
+	rpcRequestMeter      = metrics.NewMeter("rpc/requests")
+	rpcRateLimitedMeter  = metrics.NewMeter("rpc/ratelimited")
+	rpcUnauthorizedMeter = metrics.NewMeter("rpc/unauthorized")
+)
+
+// methodMetrics are the call and error counters and the latency histogram of a
+// single RPC method.
+type methodMetrics struct {
+	calls    gometrics.Counter
+	errors   gometrics.Counter
+	duration gometrics.Timer
+}
+
+var (
+	methodMetricsSet  = make(map[string]*methodMetrics)
+	methodMetricsLock sync.Mutex
+)
+
+// metricsForMethod returns the metrics of an RPC method, registering them upon
+// first use. Only methods of registered services are metered, which keeps the
+// number of metrics bounded regardless of what clients request.
+func metricsForMethod(namespace, method string) *methodMetrics {
+	name := namespace + serviceMethodSeparator + method
+
+	methodMetricsLock.Lock()
+	defer methodMetricsLock.Unlock()
+
+	m, ok := methodMetricsSet[name]
+	if !ok {
+		m = &methodMetrics{
+			calls:    metrics.NewCounter("rpc/calls/" + name),
+			errors:   metrics.NewCounter("rpc/errors/" + name),
+			duration: metrics.NewTimer("rpc/duration/" + name),
+		}
+		methodMetricsSet[name] = m
+	}
+	return m
+}
+
+// update records a completed call of the method.
+func (m *methodMetrics) update(start time.Time, failed bool) {
+	m.calls.Inc(1)
+	if failed {
+		m.errors.Inc(1)
+	}
+	m.duration.UpdateSince(start)
+}
@@ -0,0 +1,150 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bucketPruneInterval is the time interval after which client buckets that have
+// been refilled completely are dropped from the limiter.
+const bucketPruneInterval = time.Minute
+
+// RateLimits configures the per client token bucket limiting the rate at which
+// requests are served over the HTTP and websocket transports. Clients are keyed
+// by the name of their credential if authenticated, or their remote IP address
+// otherwise.
+type RateLimits struct {
+	Rate  float64        // Tokens refilled per second for each client (0 = unlimited)
+	Burst int            // Maximum number of tokens a client can accumulate
+	Costs map[string]int `toml:",omitempty"` // Tokens consumed per method (default 1), "namespace_*" matches a whole namespace
+}
+
+// cost returns the number of tokens a call to the given method consumes.
+func (l *RateLimits) cost(namespace, method string) float64 {
+	if cost, ok := l.Costs[namespace+serviceMethodSeparator+method]; ok {
+		return float64(cost)
+	}
+	if cost, ok := l.Costs[namespace+serviceMethodSeparator+"*"]; ok {
+		return float64(cost)
+	}
+	return 1
+}
+
+// bucket is the token bucket of a single client.
+type bucket struct {
+	tokens float64   // Tokens available at the time of the last update
+	update time.Time // Time of the last token update
+}
+
+// rateLimiter tracks the token buckets of all the clients of a server.
+type rateLimiter struct {
+	limits RateLimits
+	now    func() time.Time // Clock to use, overridable for tests
+
+	buckets map[string]*bucket
+	pruned  time.Time
+	lock    sync.Mutex
+}
+
+// newRateLimiter creates a limiter enforcing the given limits.
+func newRateLimiter(limits RateLimits) *rateLimiter {
+	if limits.Burst < 1 {
+		limits.Burst = 1
+	}
+	return &rateLimiter{
+		limits:  limits,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// allow refills the bucket of a client and consumes the tokens required for a
+// method call, returning whether there were enough tokens available. Calls that
+// cost more than the burst size are permitted on a full bucket.
+func (l *rateLimiter) allow(client, namespace, method string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if now.Sub(l.pruned) > bucketPruneInterval {
+		l.prune(now)
+	}
+	burst := float64(l.limits.Burst)
+
+	b, ok := l.buckets[client]
+	if !ok {
+		b = &bucket{tokens: burst, update: now}
+		l.buckets[client] = b
+	}
+	b.tokens += now.Sub(b.update).Seconds() * l.limits.Rate
+	if b.tokens > burst {
+		b.tokens = burst
+	}
+	b.update = now
+
+	cost := l.limits.cost(namespace, method)
+	if cost > burst {
+		cost = burst
+	}
+	if b.tokens < cost {
+		return false
+	}
+	b.tokens -= cost
+	return true
+}
+
+// prune drops the buckets that would be full by now, as they are equivalent to
+// the fresh bucket of an unknown client.
+func (l *rateLimiter) prune(now time.Time) {
+	burst := float64(l.limits.Burst)
+	for client, b := range l.buckets {
+		if b.tokens+now.Sub(b.update).Seconds()*l.limits.Rate >= burst {
+			delete(l.buckets, client)
+		}
+	}
+	l.pruned = now
+}
+
+type remoteAddrKey struct{}
+
+// withRemoteAddr returns a copy of the context carrying the IP address of the
+// remote end of a connection.
+func withRemoteAddr(ctx context.Context, addr string) context.Context {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return context.WithValue(ctx, remoteAddrKey{}, addr)
+}
+
+// clientFromContext returns the key identifying the caller of a request for rate
+// limiting. Authenticated callers are identified by their credential, others by
+// their remote address. Requests received over local transports (IPC, in-process)
+// carry neither and are not limited.
+func clientFromContext(ctx context.Context) (string, bool) {
+	if id := identityFromContext(ctx); id != nil {
+		return "id:" + id.Name, true
+	}
+	if addr, ok := ctx.Value(remoteAddrKey{}).(string); ok && addr != "" {
+		return "ip:" + strings.ToLower(addr), true
+	}
+	return "", false
+}
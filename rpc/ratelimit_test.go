@@ -0,0 +1,103 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package rpc
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// Tests that the token buckets of clients are drained by weighted method calls
+// and refilled over time independently of each other.
+func TestRateLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	limiter := newRateLimiter(RateLimits{
+		Rate:  1,
+		Burst: 4,
+		Costs: map[string]int{"vap_getLogs": 3, "debug_*": 10},
+	})
+	limiter.now = func() time.Time { return now }
+
+	tests := []struct {
+		advance   time.Duration
+		client    string
+		namespace string
+		method    string
+		allowed   bool
+	}{
+		{0, "a", "vap", "getLogs", true},     // 4 -> 1 tokens
+		{0, "a", "vap", "blockNumber", true}, // 1 -> 0 tokens
+		{0, "a", "vap", "blockNumber", false},
+		{0, "b", "vap", "getLogs", true}, // separate bucket
+		{2 * time.Second, "a", "vap", "getLogs", false},
+		{time.Second, "a", "vap", "getLogs", true},
+		{0, "c", "debug", "traceTransaction", true}, // capped to the burst size
+		{0, "c", "vap", "blockNumber", false},
+		{time.Hour, "c", "vap", "blockNumber", true}, // refilled up to the burst size
+	}
+	for i, tt := range tests {
+		now = now.Add(tt.advance)
+		if allowed := limiter.allow(tt.client, tt.namespace, tt.method); allowed != tt.allowed {
+			t.Errorf("test %d: allowance mismatch: have %v, want %v", i, allowed, tt.allowed)
+		}
+	}
+	// Buckets refilled completely should be pruned eventually
+	now = now.Add(bucketPruneInterval + time.Second)
+	limiter.allow("d", "vap", "blockNumber")
+	if len(limiter.buckets) != 1 {
+		t.Errorf("bucket count mismatch after pruning: have %d, want 1", len(limiter.buckets))
+	}
+}
+
+// Tests that remote clients are rate limited by the server, while local ones are
+// left unrestricted.
+func TestServerRateLimits(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	server.SetRateLimits(RateLimits{Rate: 0.001, Burst: 3, Costs: map[string]int{"service_echo": 2}})
+	defer server.Stop()
+
+	hs := httptest.NewServer(server)
+	defer hs.Close()
+
+	client, err := DialHTTP(hs.URL)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	var result Result
+	if err := client.Call(&result, "service_echo", "hello", 1, &Args{"world"}); err != nil {
+		t.Fatalf("first call failed: %v", err)
+	}
+	err = client.Call(&result, "service_echo", "hello", 1, &Args{"world"})
+	if err == nil || err.Error() != (&rateLimitedError{}).Error() {
+		t.Fatalf("expensive call error mismatch: have %v, want %v", err, &rateLimitedError{})
+	}
+	if err := client.Call(nil, "service_noArgsRets"); err != nil {
+		t.Fatalf("cheap call failed: %v", err)
+	}
+	// In-process clients should not be limited
+	local := DialInProc(server)
+	defer local.Close()
+
+	for i := 0; i < 5; i++ {
+		if err := local.Call(&result, "service_echo", "hello", 1, &Args{"world"}); err != nil {
+			t.Fatalf("local call %d failed: %v", i, err)
+		}
+	}
+}
@@ -18,12 +18,15 @@ package rpc
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/vaporyco/go-vapory/log"
 	"gopkg.in/fatih/set.v0"
@@ -119,13 +122,58 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// SetAuthenticator configures the server to require credentials from callers
+// connecting over HTTP or websockets, verified by the given authenticator. It
+// must be called before the server starts serving requests.
+func (s *Server) SetAuthenticator(auth Authenticator) {
+	s.auth = auth
+}
+
+// SetRequestLimits configures the maximum number of requests accepted in a single
+// batch, the maximum size of the responses written (cumulative for batches) and
+// the maximum execution time of a single method call, after which its context is
+// cancelled and an error returned to the caller. Zero values disable the limits.
+// It must be called before the server starts serving requests.
+func (s *Server) SetRequestLimits(batchLimit, responseLimit int, callTimeout time.Duration) {
+	s.batchLimit = batchLimit
+	s.responseLimit = responseLimit
+	s.callTimeout = callTimeout
+}
+
+// SetRateLimits configures a token bucket limiting the rate at which each client
+// connected over HTTP or websockets may issue requests, weighted by the cost of
+// the methods called. A zero rate disables rate limiting. It must be called
+// before the server starts serving requests.
+func (s *Server) SetRateLimits(limits RateLimits) {
+	if limits.Rate <= 0 {
+		s.limiter = nil
+		return
+	}
+	s.limiter = newRateLimiter(limits)
+}
+
+// authenticate verifies the credentials of an HTTP request if the server was
+// configured to require them, returning a context carrying the caller identity
+// and remote address.
+func (s *Server) authenticate(r *http.Request) (context.Context, error) {
+	ctx := withRemoteAddr(context.Background(), r.RemoteAddr)
+	if s.auth == nil {
+		return ctx, nil
+	}
+	id, err := s.auth.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, identityKey{}, id), nil
+}
+
 // serveRequest will reads requests from the codec, calls the RPC callback and
 // writes the response to the given codec.
 //
 // If singleShot is true it will process a single request, otherwise it will handle
 // requests until the codec returns an error when reading a request (in most cases
 // an EOF). It executes requests in parallel when singleShot is false.
-func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecOption) error {
+func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot bool, options CodecOption) error {
 	var pend sync.WaitGroup
 
 	defer func() {
@@ -140,7 +188,7 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 		s.codecsMu.Unlock()
 	}()
 
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
 	// if the codec supports notification include a notifier that callbacks can use
@@ -186,6 +234,15 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 			}
 			return nil
 		}
+		// reject batches exceeding the configured size limit
+		if batch && s.batchLimit > 0 && len(reqs) > s.batchLimit {
+			err := &invalidRequestError{fmt.Sprintf("batch too large (%d>%d)", len(reqs), s.batchLimit)}
+			codec.Write(codec.CreateErrorResponse(nil, err))
+			if singleShot {
+				return nil
+			}
+			continue
+		}
 		// If a single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -215,14 +272,14 @@ func (s *Server) serveRequest(codec ServerCodec, singleShot bool, options CodecO
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) {
 	defer codec.Close()
-	s.serveRequest(codec, false, options)
+	s.serveRequest(context.Background(), codec, false, options)
 }
 
 // ServeSingleRequest reads and processes a single RPC request from the given codec. It will not
 // close the codec unless a non-recoverable error has occurred. Note, this method will return after
 // a single request has been processed!
 func (s *Server) ServeSingleRequest(codec ServerCodec, options CodecOption) {
-	s.serveRequest(codec, true, options)
+	s.serveRequest(context.Background(), codec, true, options)
 }
 
 // Stop will stop reading new requests, wait for stopPendingRequestTimeout to allow pending requests to finish,
@@ -253,8 +310,10 @@ func (s *Server) createSubscription(ctx context.Context, c ServerCodec, req *ser
 	return reply[0].Interface().(*Subscription).ID, nil
 }
 
-// handle executes a request and returns the response from the callback.
-func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverRequest) (interface{}, func()) {
+// handle executes a request and returns the response from the callback. For
+// subscribe requests it also returns a function to be called once it's known
+// whether the subscription ID reached the client, activating or discarding it.
+func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverRequest) (interface{}, func(bool)) {
 	if req.err != nil {
 		return codec.CreateErrorResponse(&req.id, req.err), nil
 	}
@@ -276,15 +335,47 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, &invalidParamsError{"Expected subscription id as first argument"}), nil
 	}
 
+	method := formatName(req.callb.method.Name)
+	if req.callb.isSubscribe {
+		method = "subscribe"
+	}
+	rpcRequestMeter.Mark(1)
+
+	// ensure an authenticated caller is still permitted to invoke the method
+	if id := identityFromContext(ctx); id != nil {
+		if id.expired() {
+			rpcUnauthorizedMeter.Mark(1)
+			return codec.CreateErrorResponse(&req.id, &credentialsExpiredError{}), nil
+		}
+		if !id.Allowed(req.svcname, method) {
+			rpcUnauthorizedMeter.Mark(1)
+			return codec.CreateErrorResponse(&req.id, &unauthorizedError{req.svcname, method}), nil
+		}
+	}
+	// ensure the caller has not exceeded its request rate
+	if s.limiter != nil {
+		if client, ok := clientFromContext(ctx); ok && !s.limiter.allow(client, req.svcname, method) {
+			rpcRateLimitedMeter.Mark(1)
+			return codec.CreateErrorResponse(&req.id, &rateLimitedError{}), nil
+		}
+	}
+	stats := metricsForMethod(req.svcname, method)
+	start := time.Now()
+
 	if req.callb.isSubscribe {
 		subid, err := s.createSubscription(ctx, codec, req)
+		stats.update(start, err != nil)
 		if err != nil {
 			return codec.CreateErrorResponse(&req.id, &callbackError{err.Error()}), nil
 		}
 
 		// active the subscription after the sub id was successfully sent to the client
-		activateSub := func() {
+		activateSub := func(delivered bool) {
 			notifier, _ := NotifierFromContext(ctx)
+			if !delivered {
+				notifier.discard(subid)
+				return
+			}
 			notifier.activate(subid, req.svcname)
 		}
 
@@ -296,9 +387,16 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		rpcErr := &invalidParamsError{fmt.Sprintf("%s%s%s expects %d parameters, got %d",
 			req.svcname, serviceMethodSeparator, req.callb.method.Name,
 			len(req.callb.argTypes), len(req.args))}
+		stats.update(start, true)
 		return codec.CreateErrorResponse(&req.id, rpcErr), nil
 	}
 
+	// limit the execution time of the call if requested
+	if s.callTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.callTimeout)
+		defer cancel()
+	}
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
 		arguments = append(arguments, reflect.ValueOf(ctx))
@@ -308,7 +406,11 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	}
 
 	// execute RPC method and return result
-	reply := req.callb.method.Func.Call(arguments)
+	reply, err := s.call(ctx, req.callb, arguments)
+	stats.update(start, err != nil || (req.callb.errPos >= 0 && !reply[req.callb.errPos].IsNil()))
+	if err != nil {
+		return codec.CreateErrorResponse(&req.id, err), nil
+	}
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
@@ -316,31 +418,94 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 	if req.callb.errPos >= 0 { // test if method returned an error
 		if !reply[req.callb.errPos].IsNil() {
 			e := reply[req.callb.errPos].Interface().(error)
-			res := codec.CreateErrorResponse(&req.id, &callbackError{e.Error()})
-			return res, nil
+			return createCallbackErrorResponse(codec, &req.id, e), nil
 		}
 	}
 	return codec.CreateResponse(req.id, reply[0].Interface()), nil
 }
 
+// createCallbackErrorResponse creates the response for an error returned by a
+// method callback, retaining its error code and data if it provides them.
+func createCallbackErrorResponse(codec ServerCodec, id interface{}, err error) interface{} {
+	rpcErr, ok := err.(Error)
+	if !ok {
+		rpcErr = &callbackError{err.Error()}
+	}
+	if de, ok := err.(DataError); ok {
+		return codec.CreateErrorResponseWithInfo(id, rpcErr, de.ErrorData())
+	}
+	return codec.CreateErrorResponse(id, rpcErr)
+}
+
+// call invokes a method callback. If a call timeout is configured, the callback
+// is abandoned when its context expires. It keeps running in the background, but
+// it is expected to abort on the cancellation of its context.
+func (s *Server) call(ctx context.Context, callb *callback, args []reflect.Value) ([]reflect.Value, Error) {
+	if s.callTimeout == 0 {
+		return callb.method.Func.Call(args), nil
+	}
+	done := make(chan []reflect.Value, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				const size = 64 << 10
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+				log.Error(string(buf))
+				close(done)
+			}
+		}()
+		done <- callb.method.Func.Call(args)
+	}()
+	select {
+	case reply, ok := <-done:
+		if !ok {
+			return nil, &callbackError{"method handler crashed"}
+		}
+		return reply, nil
+	case <-ctx.Done():
+		return nil, &timeoutError{}
+	}
+}
+
+// limitResponse replaces a response exceeding the given size limit with an error.
+// If a limit is configured, it returns the already encoded response so that it's
+// not marshalled twice, along with its size. The flag reports whether the
+// response was kept.
+func (s *Server) limitResponse(codec ServerCodec, id interface{}, response interface{}, limit int) (interface{}, int, bool) {
+	if s.responseLimit == 0 {
+		return response, 0, true
+	}
+	blob, err := json.Marshal(response)
+	if err != nil {
+		return response, 0, true // let the codec report the failure
+	}
+	if len(blob) > limit {
+		return codec.CreateErrorResponse(id, &responseTooLargeError{s.responseLimit}), len(blob), false
+	}
+	return json.RawMessage(blob), len(blob), true
+}
+
 // exec executes the given request and writes the result back using the codec.
 func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest) {
 	var response interface{}
-	var callback func()
+	var callback func(bool)
 	if req.err != nil {
 		response = codec.CreateErrorResponse(&req.id, req.err)
 	} else {
 		response, callback = s.handle(ctx, codec, req)
 	}
+	response, _, kept := s.limitResponse(codec, &req.id, response, s.responseLimit)
 
-	if err := codec.Write(response); err != nil {
+	err := codec.Write(response)
+	if err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
 		codec.Close()
 	}
 
 	// when request was a subscribe request this allows these subscriptions to be actived
 	if callback != nil {
-		callback()
+		callback(kept && err == nil)
 	}
 }
 
@@ -348,26 +513,46 @@ func (s *Server) exec(ctx context.Context, codec ServerCodec, req *serverRequest
 // It will only write the response back when the last request is processed.
 func (s *Server) execBatch(ctx context.Context, codec ServerCodec, requests []*serverRequest) {
 	responses := make([]interface{}, len(requests))
-	var callbacks []func()
+	var callbacks []func(bool)
+	var size int
 	for i, req := range requests {
+		// Skip executing anything if the response size limit was already reached
+		if s.responseLimit > 0 && size >= s.responseLimit {
+			responses[i] = codec.CreateErrorResponse(&req.id, &responseTooLargeError{s.responseLimit})
+			continue
+		}
+		var callback func(bool)
 		if req.err != nil {
 			responses[i] = codec.CreateErrorResponse(&req.id, req.err)
 		} else {
-			var callback func()
-			if responses[i], callback = s.handle(ctx, codec, req); callback != nil {
+			responses[i], callback = s.handle(ctx, codec, req)
+		}
+		var (
+			n    int
+			kept bool
+		)
+		responses[i], n, kept = s.limitResponse(codec, &req.id, responses[i], s.responseLimit-size)
+		size += n
+
+		// Subscriptions whose ID was replaced by an error never reach the client
+		if callback != nil {
+			if !kept {
+				callback(false)
+			} else {
 				callbacks = append(callbacks, callback)
 			}
 		}
 	}
 
-	if err := codec.Write(responses); err != nil {
+	err := codec.Write(responses)
+	if err != nil {
 		log.Error(fmt.Sprintf("%v\n", err))
 		codec.Close()
 	}
 
 	// when request holds one of more subscribe requests this allows these subscriptions to be activated
 	for _, c := range callbacks {
-		c()
+		c(err == nil)
 	}
 }
 
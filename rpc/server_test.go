@@ -19,8 +19,12 @@ package rpc
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net"
+	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -160,3 +164,188 @@ func TestServerMethodExecution(t *testing.T) {
 func TestServerMethodWithCtx(t *testing.T) {
 	testServerMethodExecution(t, "echoWithCtx")
 }
+
+// Tests that method calls exceeding the configured timeout are aborted and an
+// error returned to the caller.
+func TestServerCallTimeout(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	server.SetRequestLimits(0, 0, 50*time.Millisecond)
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	if err := client.Call(nil, "service_sleep", 10*time.Millisecond); err != nil {
+		t.Fatalf("short call failed: %v", err)
+	}
+	start := time.Now()
+	err := client.Call(nil, "service_sleep", 5*time.Second)
+	if err == nil || err.Error() != (&timeoutError{}).Error() {
+		t.Fatalf("long call error mismatch: have %v, want %v", err, &timeoutError{})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("long call not aborted in time: %v", elapsed)
+	}
+}
+
+// Tests that batches and responses exceeding the configured limits are rejected.
+func TestServerRequestLimits(t *testing.T) {
+	server := newTestServer("service", new(Service))
+	server.SetRequestLimits(2, 256, 0)
+	defer server.Stop()
+
+	hs := httptest.NewServer(server)
+	defer hs.Close()
+
+	client, err := DialHTTP(hs.URL)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer client.Close()
+
+	// Responses within the limit should be served, larger ones rejected
+	var result Result
+	if err := client.Call(&result, "service_echo", "hello", 1, &Args{"world"}); err != nil {
+		t.Fatalf("small response failed: %v", err)
+	}
+	large := strings.Repeat("x", 256)
+	err = client.Call(&result, "service_echo", large, 1, &Args{"world"})
+	if err == nil || err.Error() != (&responseTooLargeError{256}).Error() {
+		t.Fatalf("large response error mismatch: have %v, want %v", err, &responseTooLargeError{256})
+	}
+	// Batches within the limit should be served, with the response limit applied
+	// to the cumulative size of the responses
+	batch := []BatchElem{
+		{Method: "service_echo", Args: []interface{}{strings.Repeat("x", 100), 1, &Args{"world"}}, Result: new(Result)},
+		{Method: "service_echo", Args: []interface{}{strings.Repeat("x", 100), 2, &Args{"world"}}, Result: new(Result)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if batch[0].Error != nil {
+		t.Errorf("first batch response failed: %v", batch[0].Error)
+	}
+	if batch[1].Error == nil {
+		t.Errorf("second batch response exceeding the limit succeeded")
+	}
+	// Batches exceeding the limit should be rejected altogether
+	batch = append(batch, BatchElem{Method: "service_echo", Args: []interface{}{"hello", 3, &Args{"world"}}, Result: new(Result)})
+	if err := client.BatchCall(batch); err == nil {
+		t.Errorf("oversized batch succeeded")
+	}
+}
+
+// DiscardService signals when the subscriptions it created are torn down.
+type DiscardService struct {
+	closed chan struct{}
+}
+
+func (s *DiscardService) Sub(ctx context.Context) (*Subscription, error) {
+	notifier, _ := NotifierFromContext(ctx)
+	sub := notifier.CreateSubscription()
+	go func() {
+		<-sub.Err()
+		close(s.closed)
+	}()
+	return sub, nil
+}
+
+// Tests that a subscription whose ID was replaced by a response size error is
+// discarded instead of being activated.
+func TestServerLimitedSubscription(t *testing.T) {
+	service := &DiscardService{closed: make(chan struct{})}
+
+	server := newTestServer("service", new(Service))
+	if err := server.RegisterName("discard", service); err != nil {
+		t.Fatalf("failed to register service: %v", err)
+	}
+	server.SetRequestLimits(0, 256, 0)
+	defer server.Stop()
+
+	client := DialInProc(server)
+	defer client.Close()
+
+	batch := []BatchElem{
+		{Method: "service_echo", Args: []interface{}{strings.Repeat("x", 150), 1, &Args{"world"}}, Result: new(Result)},
+		{Method: "discard_subscribe", Args: []interface{}{"sub"}, Result: new(string)},
+	}
+	if err := client.BatchCall(batch); err != nil {
+		t.Fatalf("batch failed: %v", err)
+	}
+	if batch[0].Error != nil {
+		t.Fatalf("echo within the response limit failed: %v", batch[0].Error)
+	}
+	if batch[1].Error == nil || batch[1].Error.Error() != (&responseTooLargeError{256}).Error() {
+		t.Fatalf("subscription error mismatch: have %v, want %v", batch[1].Error, &responseTooLargeError{256})
+	}
+	select {
+	case <-service.closed:
+	case <-time.After(time.Second):
+		t.Fatalf("undelivered subscription not discarded")
+	}
+}
+
+// failingWriter serves a fixed request stream, failing to write any response.
+type failingWriter struct {
+	io.Reader
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) { return 0, errors.New("write failed") }
+func (w *failingWriter) Close() error                { return nil }
+
+// Tests that a subscription whose ID could not be written to the client is
+// discarded instead of being activated.
+func TestServerUnwrittenSubscription(t *testing.T) {
+	for _, request := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"discard_subscribe","params":["sub"]}`,
+		`[{"jsonrpc":"2.0","id":1,"method":"discard_subscribe","params":["sub"]}]`,
+	} {
+		service := &DiscardService{closed: make(chan struct{})}
+
+		server := NewServer()
+		if err := server.RegisterName("discard", service); err != nil {
+			t.Fatalf("failed to register service: %v", err)
+		}
+		codec := NewJSONCodec(&failingWriter{strings.NewReader(request)})
+		server.ServeCodec(codec, OptionMethodInvocation|OptionSubscriptions)
+
+		select {
+		case <-service.closed:
+		case <-time.After(time.Second):
+			t.Errorf("request %s: unwritten subscription not discarded", request)
+		}
+		server.Stop()
+	}
+}
+
+// testDataError is an error with a custom code and attached data.
+type testDataError struct{}
+
+func (e *testDataError) Error() string          { return "data error" }
+func (e *testDataError) ErrorCode() int         { return -32099 }
+func (e *testDataError) ErrorData() interface{} { return "data" }
+
+type DataErrorService struct{}
+
+func (s *DataErrorService) Fail() (string, error) {
+	return "", &testDataError{}
+}
+
+// Tests that the code and data of errors returned by callbacks reach the caller.
+func TestServerErrorData(t *testing.T) {
+	server := newTestServer("test", new(DataErrorService))
+	defer server.Stop()
+	client := DialInProc(server)
+	defer client.Close()
+
+	err := client.Call(nil, "test_fail")
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if rpcErr, ok := err.(Error); !ok || rpcErr.ErrorCode() != -32099 {
+		t.Errorf("error code mismatch: have %v, want %d", err, -32099)
+	}
+	if dataErr, ok := err.(DataError); !ok || dataErr.ErrorData() != "data" {
+		t.Errorf("error data mismatch: have %v, want %q", err, "data")
+	}
+}
@@ -27,8 +27,15 @@ var (
 	ErrNotificationsUnsupported = errors.New("notifications not supported")
 	// ErrNotificationNotFound is returned when the notification for the given id is not found
 	ErrSubscriptionNotFound = errors.New("subscription not found")
+	// ErrNotificationBufferFull is returned when a buffered subscription exceeds
+	// MaxPendingNotifications before it is activated
+	ErrNotificationBufferFull = errors.New("too many notifications before subscription activation")
 )
 
+// MaxPendingNotifications is the maximum number of notifications buffered for a
+// subscription created by CreateBufferedSubscription until it is activated.
+const MaxPendingNotifications = 10000
+
 // ID defines a pseudo random number that is used to identify RPC subscriptions.
 type ID string
 
@@ -37,7 +44,9 @@ type ID string
 type Subscription struct {
 	ID        ID
 	namespace string
-	err       chan error // closed on unsubscribe
+	err       chan error    // closed on unsubscribe
+	buffered  bool          // whether notifications before activation are kept
+	buffer    []interface{} // notifications issued before activation
 }
 
 // Err returns a channel that is closed when the client send an unsubscribe request.
@@ -78,7 +87,20 @@ func NotifierFromContext(ctx context.Context) (*Notifier, bool) {
 // are dropped until the subscription is marked as active. This is done
 // by the RPC server after the subscription ID is send to the client.
 func (n *Notifier) CreateSubscription() *Subscription {
-	s := &Subscription{ID: NewID(), err: make(chan error)}
+	return n.createSubscription(false)
+}
+
+// CreateBufferedSubscription returns a new inactive subscription like
+// CreateSubscription, but its notifications are buffered until activation
+// instead of dropped. Exceeding MaxPendingNotifications fails the subscription
+// by closing the RPC connection, so the client doesn't miss any silently.
+func (n *Notifier) CreateBufferedSubscription() *Subscription {
+	return n.createSubscription(true)
+}
+
+// createSubscription registers a new inactive subscription.
+func (n *Notifier) createSubscription(buffered bool) *Subscription {
+	s := &Subscription{ID: NewID(), err: make(chan error), buffered: buffered}
 	n.subMu.Lock()
 	n.inactive[s.ID] = s
 	n.subMu.Unlock()
@@ -86,18 +108,45 @@ func (n *Notifier) CreateSubscription() *Subscription {
 }
 
 // Notify sends a notification to the client with the given data as payload.
+// Notifications for buffered subscriptions not yet activated are delivered on
+// activation.
 // If an error occurs the RPC connection is closed and the error is returned.
 func (n *Notifier) Notify(id ID, data interface{}) error {
 	n.subMu.RLock()
-	defer n.subMu.RUnlock()
+	if sub, active := n.active[id]; active {
+		defer n.subMu.RUnlock()
+		return n.send(sub, data)
+	}
+	n.subMu.RUnlock()
 
-	sub, active := n.active[id]
-	if active {
-		notification := n.codec.CreateNotification(string(id), sub.namespace, data)
-		if err := n.codec.Write(notification); err != nil {
+	// Not active yet, buffer the notification if requested. The subscription
+	// may have been activated in the meantime, so check again under the lock.
+	n.subMu.Lock()
+	defer n.subMu.Unlock()
+
+	if sub, inactive := n.inactive[id]; inactive && sub.buffered {
+		if len(sub.buffer) >= MaxPendingNotifications {
+			delete(n.inactive, id)
+			close(sub.err)
 			n.codec.Close()
-			return err
+			return ErrNotificationBufferFull
 		}
+		sub.buffer = append(sub.buffer, data)
+		return nil
+	}
+	if sub, active := n.active[id]; active {
+		return n.send(sub, data)
+	}
+	return nil
+}
+
+// send writes a notification of the given subscription to the client, closing
+// the RPC connection on failure.
+func (n *Notifier) send(sub *Subscription, data interface{}) error {
+	notification := n.codec.CreateNotification(string(sub.ID), sub.namespace, data)
+	if err := n.codec.Write(notification); err != nil {
+		n.codec.Close()
+		return err
 	}
 	return nil
 }
@@ -121,7 +170,7 @@ func (n *Notifier) unsubscribe(id ID) error {
 }
 
 // activate enables a subscription. Until a subscription is enabled all
-// notifications are dropped. This method is called by the RPC server after
+// notifications are dropped, or buffered if it was created that way. This method is called by the RPC server after
 // the subscription ID was sent to client. This prevents notifications being
 // send to the client before the subscription ID is send to the client.
 func (n *Notifier) activate(id ID, namespace string) {
@@ -131,5 +180,25 @@ func (n *Notifier) activate(id ID, namespace string) {
 		sub.namespace = namespace
 		n.active[id] = sub
 		delete(n.inactive, id)
+
+		buffer := sub.buffer
+		sub.buffer = nil
+		for _, data := range buffer {
+			if err := n.send(sub, data); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// discard drops a subscription which was never activated, because its ID could
+// not be delivered to the client. Its error channel is closed as if the client
+// had unsubscribed, so the server callback releases its resources.
+func (n *Notifier) discard(id ID) {
+	n.subMu.Lock()
+	defer n.subMu.Unlock()
+	if sub, found := n.inactive[id]; found {
+		close(sub.err)
+		delete(n.inactive, id)
 	}
 }
@@ -87,6 +87,22 @@ func (s *NotificationTestService) SomeSubscription(ctx context.Context, n, val i
 	return subscription, nil
 }
 
+// ImmediateSubscription sends n events before the subscription ID is returned
+// to the client, relying on the notifier to buffer them until activation.
+func (s *NotificationTestService) ImmediateSubscription(ctx context.Context, n, val int) (*Subscription, error) {
+	notifier, supported := NotifierFromContext(ctx)
+	if !supported {
+		return nil, ErrNotificationsUnsupported
+	}
+	subscription := notifier.CreateBufferedSubscription()
+	for i := 0; i < n; i++ {
+		if err := notifier.Notify(subscription.ID, val+i); err != nil {
+			return nil, err
+		}
+	}
+	return subscription, nil
+}
+
 // HangSubscription blocks on s.unblockHangSubscription before
 // sending anything.
 func (s *NotificationTestService) HangSubscription(ctx context.Context, val int) (*Subscription, error) {
@@ -328,3 +344,71 @@ func TestSubscriptionMultipleNamespaces(t *testing.T) {
 		}
 	}
 }
+
+// Tests that notifications sent before the subscription ID reached the client
+// are delivered once the subscription is activated.
+func TestNotificationsBeforeActivation(t *testing.T) {
+	server := NewServer()
+	if err := server.RegisterName("vap", new(NotificationTestService)); err != nil {
+		t.Fatalf("unable to register test service %v", err)
+	}
+	client := DialInProc(server)
+	defer client.Close()
+
+	n, val := 5, 12345
+	ch := make(chan int, n)
+	sub, err := client.VapSubscribe(context.Background(), ch, "immediateSubscription", n, val)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	for i := 0; i < n; i++ {
+		select {
+		case have := <-ch:
+			if have != val+i {
+				t.Errorf("notification %d: have %d, want %d", i, have, val+i)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(time.Second):
+			t.Fatalf("notification %d not delivered", i)
+		}
+	}
+}
+
+// Tests that the notifications buffered before activation are capped, failing
+// the subscription and the connection once exceeded, and that unbuffered
+// subscriptions drop them.
+func TestNotificationBufferLimit(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	notifier := newNotifier(NewJSONCodec(server))
+
+	dropped := notifier.CreateSubscription()
+	if err := notifier.Notify(dropped.ID, 0); err != nil {
+		t.Fatalf("notification failed: %v", err)
+	}
+	if len(dropped.buffer) != 0 {
+		t.Errorf("unbuffered subscription kept %d notifications", len(dropped.buffer))
+	}
+	sub := notifier.CreateBufferedSubscription()
+	for i := 0; i < MaxPendingNotifications; i++ {
+		if err := notifier.Notify(sub.ID, i); err != nil {
+			t.Fatalf("notification %d failed: %v", i, err)
+		}
+	}
+	if err := notifier.Notify(sub.ID, MaxPendingNotifications); err != ErrNotificationBufferFull {
+		t.Fatalf("overflow error mismatch: have %v, want %v", err, ErrNotificationBufferFull)
+	}
+	select {
+	case <-sub.Err():
+	default:
+		t.Error("overflowed subscription not closed")
+	}
+	select {
+	case <-notifier.Closed():
+	default:
+		t.Error("connection not closed on overflow")
+	}
+}
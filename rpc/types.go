@@ -17,12 +17,15 @@
 package rpc
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
 	"strings"
 	"sync"
+	"time"
 
+	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/hexutil"
 	"gopkg.in/fatih/set.v0"
 )
@@ -70,6 +73,12 @@ type subscriptions map[string]*callback  // collection of subscription callbacks
 // Server represents a RPC server
 type Server struct {
 	services serviceRegistry
+	auth     Authenticator
+
+	batchLimit    int           // Maximum number of requests in a batch (0 = unlimited)
+	responseLimit int           // Maximum size of the responses in bytes (0 = unlimited)
+	callTimeout   time.Duration // Maximum execution time of a method call (0 = unlimited)
+	limiter       *rateLimiter  // Per client request rate limiter (nil = unlimited)
 
 	run      int32
 	codecsMu sync.Mutex
@@ -92,6 +101,13 @@ type Error interface {
 	ErrorCode() int // returns the code
 }
 
+// DataError wraps RPC errors carrying additional data, which is returned to the
+// caller in the data field of the error response.
+type DataError interface {
+	Error() string          // returns the message
+	ErrorData() interface{} // returns the error data
+}
+
 // ServerCodec implements reading, parsing and writing RPC messages for the server side of
 // a RPC session. Implementations must be go-routine safe since the codec can be called in
 // multiple go-routines concurrently.
@@ -163,3 +179,59 @@ func (bn *BlockNumber) UnmarshalJSON(data []byte) error {
 func (bn BlockNumber) Int64() int64 {
 	return (int64)(bn)
 }
+
+// BlockNumberOrHash identifies a block either by its number (or one of the
+// special block tags) or by its hash.
+type BlockNumberOrHash struct {
+	BlockNumber *BlockNumber `json:"blockNumber,omitempty"`
+	BlockHash   *common.Hash `json:"blockHash,omitempty"`
+}
+
+// UnmarshalJSON parses the given JSON fragment into a BlockNumberOrHash. It
+// supports everything accepted by BlockNumber, a 32 byte hex encoded block hash
+// or an object with exactly one of the blockNumber and blockHash fields.
+func (bnh *BlockNumberOrHash) UnmarshalJSON(data []byte) error {
+	input := strings.TrimSpace(string(data))
+	if len(input) > 0 && input[0] == '{' {
+		type plain BlockNumberOrHash // avoid recursing into this method
+		var obj plain
+		if err := json.Unmarshal(data, &obj); err != nil {
+			return err
+		}
+		if (obj.BlockNumber == nil) == (obj.BlockHash == nil) {
+			return fmt.Errorf("exactly one of blockNumber and blockHash must be specified")
+		}
+		*bnh = BlockNumberOrHash(obj)
+		return nil
+	}
+	if len(input) == 2+2*common.HashLength+2 {
+		var hash common.Hash
+		if err := hash.UnmarshalJSON(data); err != nil {
+			return err
+		}
+		*bnh = BlockNumberOrHash{BlockHash: &hash}
+		return nil
+	}
+	var number BlockNumber
+	if err := number.UnmarshalJSON(data); err != nil {
+		return err
+	}
+	*bnh = BlockNumberOrHash{BlockNumber: &number}
+	return nil
+}
+
+// Number returns the block number if the block is identified by number.
+func (bnh BlockNumberOrHash) Number() (BlockNumber, bool) {
+	if bnh.BlockNumber != nil {
+		return *bnh.BlockNumber, true
+	}
+	return BlockNumber(0), false
+}
+
+// Hash returns the block hash if the block is identified by hash.
+func (bnh BlockNumberOrHash) Hash() (common.Hash, bool) {
+	if bnh.BlockHash != nil {
+		return *bnh.BlockHash, true
+	}
+	return common.Hash{}, false
+}
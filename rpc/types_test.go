@@ -18,8 +18,10 @@ package rpc
 
 import (
 	"encoding/json"
+	"reflect"
 	"testing"
 
+	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/math"
 )
 
@@ -64,3 +66,42 @@ func TestBlockNumberJSONUnmarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestBlockNumberOrHashJSONUnmarshal(t *testing.T) {
+	var (
+		hash   = common.HexToHash("0x1122334455667788990011223344556677889900112233445566778899001122")
+		number = func(n BlockNumber) BlockNumberOrHash { return BlockNumberOrHash{BlockNumber: &n} }
+	)
+	tests := []struct {
+		input    string
+		mustFail bool
+		expected BlockNumberOrHash
+	}{
+		0:  {`"0x1"`, false, number(1)},
+		1:  {`"latest"`, false, number(LatestBlockNumber)},
+		2:  {`"pending"`, false, number(PendingBlockNumber)},
+		3:  {`"` + hash.Hex() + `"`, false, BlockNumberOrHash{BlockHash: &hash}},
+		4:  {`{"blockNumber":"0x12"}`, false, number(18)},
+		5:  {`{"blockNumber":"earliest"}`, false, number(EarliestBlockNumber)},
+		6:  {`{"blockHash":"` + hash.Hex() + `"}`, false, BlockNumberOrHash{BlockHash: &hash}},
+		7:  {`{"blockNumber":"0x1","blockHash":"` + hash.Hex() + `"}`, true, BlockNumberOrHash{}},
+		8:  {`{}`, true, BlockNumberOrHash{}},
+		9:  {`"0x11223344556677889900112233445566778899001122334455667788990011"`, true, BlockNumberOrHash{}},
+		10: {`"ff"`, true, BlockNumberOrHash{}},
+	}
+	for i, test := range tests {
+		var bnh BlockNumberOrHash
+		err := json.Unmarshal([]byte(test.input), &bnh)
+		if test.mustFail && err == nil {
+			t.Errorf("Test %d should fail", i)
+			continue
+		}
+		if !test.mustFail && err != nil {
+			t.Errorf("Test %d should pass but got err: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(bnh, test.expected) {
+			t.Errorf("Test %d got unexpected value, want %+v, got %+v", i, test.expected, bnh)
+		}
+	}
+}
@@ -37,10 +37,35 @@ import (
 // allowedOrigins should be a comma-separated list of allowed origin URLs.
 // To allow connections with any origin, pass "*".
 func (srv *Server) WebsocketHandler(allowedOrigins []string) http.Handler {
+	validator := wsHandshakeValidator(allowedOrigins)
 	return websocket.Server{
-		Handshake: wsHandshakeValidator(allowedOrigins),
+		Handshake: func(cfg *websocket.Config, req *http.Request) error {
+			if err := validator(cfg, req); err != nil {
+				return err
+			}
+			if _, err := srv.authenticate(req); err != nil {
+				log.Warn("Rejected unauthenticated WS-RPC connection", "addr", req.RemoteAddr, "err", err)
+				return err
+			}
+			return nil
+		},
 		Handler: func(conn *websocket.Conn) {
-			srv.ServeCodec(NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
+			// The handshake already verified the credentials, this cannot fail
+			ctx, err := srv.authenticate(conn.Request())
+			if err != nil {
+				conn.Close()
+				return
+			}
+			codec := NewJSONCodec(conn)
+			defer codec.Close()
+
+			// Bearer tokens are only verified once, drop the connection when it expires
+			if id := identityFromContext(ctx); id != nil && !id.expiry.IsZero() {
+				expiry := time.AfterFunc(time.Until(id.expiry), func() { codec.Close() })
+				defer expiry.Stop()
+			}
+
+			srv.serveRequest(ctx, codec, false, OptionMethodInvocation|OptionSubscriptions)
 		},
 	}
 }
@@ -96,6 +121,12 @@ func wsHandshakeValidator(allowedOrigins []string) func(*websocket.Config, *http
 // The context is used for the initial connection establishment. It does not
 // affect subsequent interactions with the client.
 func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error) {
+	return DialWebsocketWithHeader(ctx, endpoint, origin, nil)
+}
+
+// DialWebsocketWithHeader creates a new RPC client just like DialWebsocket, also
+// sending the given headers (e.g. authentication credentials) in the handshake.
+func DialWebsocketWithHeader(ctx context.Context, endpoint, origin string, header http.Header) (*Client, error) {
 	if origin == "" {
 		var err error
 		if origin, err = os.Hostname(); err != nil {
@@ -111,6 +142,9 @@ func DialWebsocket(ctx context.Context, endpoint, origin string) (*Client, error
 	if err != nil {
 		return nil, err
 	}
+	for key, values := range header {
+		config.Header[key] = values
+	}
 
 	return newClient(ctx, func(ctx context.Context) (net.Conn, error) {
 		return wsDialContext(ctx, config)
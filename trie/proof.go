@@ -24,6 +24,7 @@ import (
 	"github.com/vaporyco/go-vapory/crypto"
 	"github.com/vaporyco/go-vapory/log"
 	"github.com/vaporyco/go-vapory/rlp"
+	"github.com/vaporyco/go-vapory/vapdb"
 )
 
 // Prove constructs a merkle proof for key. The result contains all
@@ -35,7 +36,7 @@ import (
 // contains all nodes of the longest existing prefix of the key
 // (at least the root node), ending with the node that proves the
 // absence of the key.
-func (t *Trie) Prove(key []byte, fromLevel uint, proofDb DatabaseWriter) error {
+func (t *Trie) Prove(key []byte, fromLevel uint, proofDb vapdb.Putter) error {
 	// Collect all nodes on the path to key.
 	key = keybytesToHex(key)
 	nodes := []node{}
@@ -66,7 +67,7 @@ func (t *Trie) Prove(key []byte, fromLevel uint, proofDb DatabaseWriter) error {
 			panic(fmt.Sprintf("%T: invalid node: %v", tn, tn))
 		}
 	}
-	hasher := newHasher(0, 0)
+	hasher := newHasher(0, 0, nil)
 	for i, n := range nodes {
 		// Don't bother checking for errors here since hasher panics
 		// if encoding doesn't work and we're not writing to any database.
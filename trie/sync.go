@@ -19,8 +19,10 @@ package trie
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/vapdb"
 	"gopkg.in/karalabe/cookiejar.v2/collections/prque"
 )
 
@@ -45,6 +47,13 @@ type request struct {
 	callback TrieSyncLeafCallback // Callback to invoke if a leaf node it reached on this branch
 }
 
+// requestsByDepth implements sort.Interface to order requests by trie depth.
+type requestsByDepth []*request
+
+func (r requestsByDepth) Len() int           { return len(r) }
+func (r requestsByDepth) Less(i, j int) bool { return r[i].depth < r[j].depth }
+func (r requestsByDepth) Swap(i, j int)      { r[i], r[j] = r[j], r[i] }
+
 // SyncResult is a simple list to return missing nodes along with their request
 // hashes.
 type SyncResult struct {
@@ -163,11 +172,37 @@ func (s *TrieSync) AddRawEntry(hash common.Hash, depth int, parent common.Hash)
 func (s *TrieSync) Missing(max int) []common.Hash {
 	requests := []common.Hash{}
 	for !s.queue.Empty() && (max == 0 || len(requests) < max) {
-		requests = append(requests, s.queue.PopItem().(common.Hash))
+		hash := s.queue.PopItem().(common.Hash)
+
+		// Skip any nodes that were already injected without being retrieved
+		if req := s.requests[hash]; req == nil || req.data != nil {
+			continue
+		}
+		requests = append(requests, hash)
 	}
 	return requests
 }
 
+// Fetched retrieves all the trie nodes that were already processed, but cannot
+// be committed yet as some of their children are still missing. The results are
+// ordered parents first, so injecting them via Process into a new scheduler for
+// the same root restores the progress made by this one.
+func (s *TrieSync) Fetched() []SyncResult {
+	fetched := make(requestsByDepth, 0, len(s.requests))
+	for _, req := range s.requests {
+		if req.data != nil {
+			fetched = append(fetched, req)
+		}
+	}
+	sort.Sort(fetched)
+
+	results := make([]SyncResult, len(fetched))
+	for i, req := range fetched {
+		results[i] = SyncResult{Hash: req.hash, Data: req.data}
+	}
+	return results
+}
+
 // Process injects a batch of retrieved trie nodes data, returning if something
 // was committed to the database and also the index of an entry if processing of
 // it failed.
@@ -217,7 +252,7 @@ func (s *TrieSync) Process(results []SyncResult) (bool, int, error) {
 
 // Commit flushes the data stored in the internal membatch out to persistent
 // storage, returning th enumber of items written and any occurred error.
-func (s *TrieSync) Commit(dbw DatabaseWriter) (int, error) {
+func (s *TrieSync) Commit(dbw vapdb.Putter) (int, error) {
 	// Dump the membatch into a database dbw
 	for i, key := range s.membatch.order {
 		if err := dbw.Put(key[:], s.membatch.batch[key]); err != nil {
@@ -198,6 +198,52 @@ func (api *PrivateMinerAPI) GetHashrate() uint64 {
 	return uint64(api.e.miner.HashRate())
 }
 
+// PrivateTxPoolAPI provides private RPC methods to control the priority lanes and
+// account limits of the transaction pool at runtime. It is served in its own
+// txpooladmin namespace, keeping it out of the read only txpool one.
+type PrivateTxPoolAPI struct {
+	e *Vapory
+}
+
+// NewPrivateTxPoolAPI creates a new RPC service which controls the transaction
+// pool of this node.
+func NewPrivateTxPoolAPI(e *Vapory) *PrivateTxPoolAPI {
+	return &PrivateTxPoolAPI{e: e}
+}
+
+// Lanes retrieves the local and priority senders of the transaction pool along
+// with the account specific limits.
+func (api *PrivateTxPoolAPI) Lanes() core.TxPoolLanes {
+	return api.e.txPool.Lanes()
+}
+
+// AddPriority adds a remote sender to the priority lane, evicting its transactions
+// only after all other remote ones.
+func (api *PrivateTxPoolAPI) AddPriority(addr common.Address) bool {
+	api.e.txPool.SetPriority(addr, true)
+	return true
+}
+
+// RemovePriority removes a sender from the priority lane.
+func (api *PrivateTxPoolAPI) RemovePriority(addr common.Address) bool {
+	api.e.txPool.SetPriority(addr, false)
+	return true
+}
+
+// SetPrioritySlots sets the number of executable transaction slots reserved for
+// the priority senders on top of the global ones.
+func (api *PrivateTxPoolAPI) SetPrioritySlots(slots hexutil.Uint64) bool {
+	api.e.txPool.SetPrioritySlots(uint64(slots))
+	return true
+}
+
+// SetAccountLimits overrides the pool wide transaction slots and price bump for a
+// specific account. Zero fields fall back to the pool wide limits.
+func (api *PrivateTxPoolAPI) SetAccountLimits(addr common.Address, limits core.TxPoolAccountConfig) bool {
+	api.e.txPool.SetAccountLimits(addr, limits)
+	return true
+}
+
 // PrivateAdminAPI is the collection of Vapory full node-related APIs
 // exposed over the private admin endpoint.
 type PrivateAdminAPI struct {
@@ -462,11 +508,11 @@ func (api *PrivateDebugAPI) getModifiedAccounts(startBlock, endBlock *types.Bloc
 		return nil, fmt.Errorf("start block height (%d) must be less than end block height (%d)", startBlock.Number().Uint64(), endBlock.Number().Uint64())
 	}
 
-	oldTrie, err := trie.NewSecure(startBlock.Root(), api.vap.chainDb, 0)
+	oldTrie, err := trie.NewSecure(startBlock.Root(), trie.NewDatabase(api.vap.chainDb), 0)
 	if err != nil {
 		return nil, err
 	}
-	newTrie, err := trie.NewSecure(endBlock.Root(), api.vap.chainDb, 0)
+	newTrie, err := trie.NewSecure(endBlock.Root(), trie.NewDatabase(api.vap.chainDb), 0)
 	if err != nil {
 		return nil, err
 	}
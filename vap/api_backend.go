@@ -168,6 +168,15 @@ func (b *VapApiBackend) TxPoolContent() (map[common.Address]types.Transactions,
 	return b.vap.TxPool().Content()
 }
 
+func (b *VapApiBackend) TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions) {
+	return b.vap.TxPool().ContentFrom(addr)
+}
+
+func (b *VapApiBackend) TxPoolNonceGaps(ctx context.Context, addr common.Address) (uint64, []core.NonceGap, error) {
+	nonce, gaps := b.vap.TxPool().NonceGaps(addr)
+	return nonce, gaps, nil
+}
+
 func (b *VapApiBackend) SubscribeTxPreEvent(ch chan<- core.TxPreEvent) event.Subscription {
 	return b.vap.TxPool().SubscribeTxPreEvent(ch)
 }
@@ -17,6 +17,9 @@
 package vap
 
 import (
+	"bytes"
+	"encoding/json"
+	"math/big"
 	"reflect"
 	"testing"
 
@@ -89,3 +92,42 @@ func TestStorageRangeAt(t *testing.T) {
 		}
 	}
 }
+
+// Tests that state overrides replace only the specified fields of an account.
+func TestStateOverride(t *testing.T) {
+	var (
+		db, _      = vapdb.NewMemDatabase()
+		statedb, _ = state.New(common.Hash{}, state.NewDatabase(db))
+		addr       = common.Address{0x01}
+	)
+	statedb.SetBalance(addr, big.NewInt(1))
+	statedb.SetNonce(addr, 2)
+	statedb.SetCode(addr, []byte{0x03})
+	statedb.SetState(addr, common.Hash{0x01}, common.Hash{0x01})
+	statedb.SetState(addr, common.Hash{0x02}, common.Hash{0x02})
+
+	var override StateOverride
+	if err := json.Unmarshal([]byte(`{"0x0100000000000000000000000000000000000000": {"balance": "0x10", "storage": {"0x0200000000000000000000000000000000000000000000000000000000000000": "0x0000000000000000000000000000000000000000000000000000000000000004"}}}`), &override); err != nil {
+		t.Fatalf("failed to parse override: %v", err)
+	}
+	override.Apply(statedb)
+
+	if balance := statedb.GetBalance(addr); balance.Cmp(big.NewInt(16)) != 0 {
+		t.Errorf("balance mismatch: have %v, want %v", balance, 16)
+	}
+	if nonce := statedb.GetNonce(addr); nonce != 2 {
+		t.Errorf("nonce mismatch: have %v, want %v", nonce, 2)
+	}
+	if code := statedb.GetCode(addr); !bytes.Equal(code, []byte{0x03}) {
+		t.Errorf("code mismatch: have %x, want %x", code, []byte{0x03})
+	}
+	if value := statedb.GetState(addr, common.Hash{0x01}); value != (common.Hash{0x01}) {
+		t.Errorf("untouched slot mismatch: have %x, want %x", value, common.Hash{0x01})
+	}
+	if value := statedb.GetState(addr, common.Hash{0x02}); value != common.BigToHash(big.NewInt(4)) {
+		t.Errorf("overridden slot mismatch: have %x, want %x", value, common.BigToHash(big.NewInt(4)))
+	}
+	if diff := statedb.Diff(true); len(diff) != 0 {
+		t.Errorf("overrides left in the journal: %v", diff)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"path/filepath"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -145,8 +146,20 @@ func New(ctx *node.ServiceContext, config *Config) (*Vapory, error) {
 		core.WriteBlockChainVersion(chainDb, core.BlockChainVersion)
 	}
 
-	vmConfig := vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
-	vap.blockchain, err = core.NewBlockChain(chainDb, vap.chainConfig, vap.engine, vmConfig)
+	var (
+		vmConfig    = vm.Config{EnablePreimageRecording: config.EnablePreimageRecording}
+		cacheConfig = &core.CacheConfig{
+			Disabled:       config.NoPruning,
+			TrieNodeLimit:  config.TrieCache,
+			TrieTimeLimit:  config.TrieTimeout,
+			TrieBlockLimit: config.TrieBlockInterval,
+			TriesInMemory:  config.TriesInMemory,
+			Snapshot:       config.Snapshot,
+
+			FreezerThreshold: config.FreezerThreshold,
+		}
+	)
+	vap.blockchain, err = core.NewBlockChain(chainDb, cacheConfig, vap.chainConfig, vap.engine, vmConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -205,6 +218,19 @@ func CreateDB(ctx *node.ServiceContext, config *Config, name string) (vapdb.Data
 	if db, ok := db.(*vapdb.LDBDatabase); ok {
 		db.Meter("vap/db/chaindata/")
 	}
+	// Back the chain with the ancient store if freezing is enabled, or if any
+	// blocks were already frozen in the past
+	if dir := ctx.ResolvePath(name); dir != "" && config.SyncMode != downloader.LightSync {
+		ancient := filepath.Join(dir, "ancient")
+		if config.FreezerThreshold > 0 || common.FileExist(ancient) {
+			frdb, err := core.NewDatabaseWithFreezer(db, ancient)
+			if err != nil {
+				db.Close()
+				return nil, err
+			}
+			return frdb, nil
+		}
+	}
 	return db, nil
 }
 
@@ -269,10 +295,15 @@ func (s *Vapory) APIs() []rpc.API {
 			Version:   "1.0",
 			Service:   NewPrivateMinerAPI(s),
 			Public:    false,
+		}, {
+			Namespace: "txpooladmin",
+			Version:   "1.0",
+			Service:   NewPrivateTxPoolAPI(s),
+			Public:    false,
 		}, {
 			Namespace: "vap",
 			Version:   "1.0",
-			Service:   filters.NewPublicFilterAPI(s.ApiBackend, false),
+			Service:   filters.NewPublicFilterAPI(s.ApiBackend, false, filters.Limits{MaxBlocks: s.config.FilterMaxBlocks, MaxLogs: s.config.FilterMaxLogs}),
 			Public:    true,
 		}, {
 			Namespace: "admin",
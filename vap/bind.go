@@ -18,17 +18,25 @@ package vap
 
 import (
 	"context"
+	"errors"
 	"math/big"
+	"sync"
 
 	"github.com/vaporyco/go-vapory"
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/hexutil"
 	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/event"
 	"github.com/vaporyco/go-vapory/internal/vapapi"
 	"github.com/vaporyco/go-vapory/rlp"
 	"github.com/vaporyco/go-vapory/rpc"
+	"github.com/vaporyco/go-vapory/vap/filters"
 )
 
+// errNoLogFiltering is returned if logs are requested from a backend which does
+// not support filtering them.
+var errNoLogFiltering = errors.New("backend does not support log filtering")
+
 // ContractBackend implements bind.ContractBackend with direct calls to Vapory
 // internals to support operating on contracts within subprotocols like vap and
 // swarm.
@@ -37,19 +45,29 @@ import (
 // object. These should be rewritten to internal Go method calls when the Go API
 // is refactored to support a clean library use.
 type ContractBackend struct {
-	eapi  *vapapi.PublicVaporyAPI        // Wrapper around the Vapory object to access metadata
+	eapi  *vapapi.PublicVaporyAPI          // Wrapper around the Vapory object to access metadata
 	bcapi *vapapi.PublicBlockChainAPI      // Wrapper around the blockchain to access chain data
 	txapi *vapapi.PublicTransactionPoolAPI // Wrapper around the transaction pool to access transaction data
+
+	fbackend   filters.Backend      // Backend to filter logs with (nil if unsupported)
+	lightMode  bool                 // Whether the backend is a light client one
+	events     *filters.EventSystem // Event system for log subscriptions, created on demand
+	eventsOnce sync.Once            // Ensures the event system is only created once
 }
 
 // NewContractBackend creates a new native contract backend using an existing
-// Vapory object.
+// Vapory object. Log filtering is only available if the API backend supports it.
 func NewContractBackend(apiBackend vapapi.Backend) *ContractBackend {
-	return &ContractBackend{
+	b := &ContractBackend{
 		eapi:  vapapi.NewPublicVaporyAPI(apiBackend),
 		bcapi: vapapi.NewPublicBlockChainAPI(apiBackend),
 		txapi: vapapi.NewPublicTransactionPoolAPI(apiBackend, new(vapapi.AddrLocker)),
 	}
+	if fbackend, ok := apiBackend.(filters.Backend); ok {
+		_, full := apiBackend.(*VapApiBackend)
+		b.fbackend, b.lightMode = fbackend, !full
+	}
+	return b
 }
 
 // CodeAt retrieves any code associated with the contract from the local API.
@@ -134,3 +152,74 @@ func (b *ContractBackend) SendTransaction(ctx context.Context, tx *types.Transac
 	_, err := b.txapi.SendRawTransaction(ctx, raw)
 	return err
 }
+
+// FilterLogs implements bind.ContractFilterer executing a log filtering query
+// against the local chain.
+func (b *ContractBackend) FilterLogs(ctx context.Context, query vapory.FilterQuery) ([]types.Log, error) {
+	if b.fbackend == nil {
+		return nil, errNoLogFiltering
+	}
+	from := int64(rpc.LatestBlockNumber)
+	if query.FromBlock != nil {
+		from = query.FromBlock.Int64()
+	}
+	to := int64(rpc.LatestBlockNumber)
+	if query.ToBlock != nil {
+		to = query.ToBlock.Int64()
+	}
+	logs, err := filters.New(b.fbackend, from, to, query.Addresses, query.Topics).Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]types.Log, len(logs))
+	for i, log := range logs {
+		res[i] = *log
+	}
+	return res, nil
+}
+
+// SubscribeFilterLogs implements bind.ContractFilterer creating a background
+// log filtering operation, returning a subscription immediately, which can be
+// used to stream the found events.
+func (b *ContractBackend) SubscribeFilterLogs(ctx context.Context, query vapory.FilterQuery, ch chan<- types.Log) (vapory.Subscription, error) {
+	if b.fbackend == nil {
+		return nil, errNoLogFiltering
+	}
+	b.eventsOnce.Do(func() {
+		b.events = filters.NewEventSystem(b.fbackend.EventMux(), b.fbackend, b.lightMode)
+	})
+	// Subscribe to contract events
+	sink := make(chan []*types.Log)
+
+	sub, err := b.events.SubscribeLogs(filters.FilterCriteria{
+		FromBlock: query.FromBlock,
+		ToBlock:   query.ToBlock,
+		Addresses: query.Addresses,
+		Topics:    query.Topics,
+	}, sink)
+	if err != nil {
+		return nil, err
+	}
+	// Since we're getting logs in batches, we need to flatten them into a plain stream
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case logs := <-sink:
+				for _, log := range logs {
+					select {
+					case ch <- *log:
+					case err := <-sub.Err():
+						return err
+					case <-quit:
+						return nil
+					}
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
@@ -22,6 +22,7 @@ import (
 	"os/user"
 	"path/filepath"
 	"runtime"
+	"time"
 
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/hexutil"
@@ -45,6 +46,9 @@ var DefaultConfig = Config{
 	NetworkId:     1,
 	LightPeers:    20,
 	DatabaseCache: 128,
+	TrieCache:     256,
+	TrieTimeout:   5 * time.Minute,
+	TriesInMemory: 128,
 	GasPrice:      big.NewInt(18 * params.Shannon),
 
 	TxPool: core.DefaultTxPoolConfig,
@@ -88,6 +92,17 @@ type Config struct {
 	DatabaseHandles    int  `toml:"-"`
 	DatabaseCache      int
 
+	// Ancient store options
+	FreezerThreshold uint64 `toml:",omitempty"` // Number of recent blocks to keep in the key-value store before freezing them (0 = disabled)
+
+	// State pruning options
+	NoPruning         bool          // Whether to disable pruning and flush all state to disk (archive node)
+	TrieCache         int           // Memory allowance (MB) for the in-memory trie node cache
+	TrieTimeout       time.Duration // Block processing time after which the trie cache is flushed
+	TrieBlockInterval uint64        // Number of blocks after which the trie cache is flushed (0 = disabled)
+	TriesInMemory     uint64        // Number of recent state tries to retain in memory
+	Snapshot          bool          // Whether to maintain a flat snapshot of the state for fast state reads
+
 	// Mining-related options
 	Vapbase    common.Address `toml:",omitempty"`
 	MinerThreads int            `toml:",omitempty"`
@@ -103,6 +118,10 @@ type Config struct {
 	// Gas Price Oracle options
 	GPO gasprice.Config
 
+	// Log filtering options
+	FilterMaxBlocks uint64 `toml:",omitempty"` // Maximum number of blocks searched by a single log query (0 = unlimited)
+	FilterMaxLogs   uint64 `toml:",omitempty"` // Maximum number of logs returned by a single log query (0 = unlimited)
+
 	// Enables tracking of SHA3 preimages in the VM
 	EnablePreimageRecording bool
 
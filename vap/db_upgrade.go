@@ -50,7 +50,7 @@ func upgradeDeduplicateData(db vapdb.Database) func() error {
 
 	go func() {
 		// Create an iterator to read the entire database and covert old lookup entires
-		it := db.(*vapdb.LDBDatabase).NewIterator()
+		it := db.NewIteratorWithStart(nil)
 		defer func() {
 			if it != nil {
 				it.Release()
@@ -99,9 +99,9 @@ func upgradeDeduplicateData(db vapdb.Database) func() error {
 			// avoid too high memory consumption.
 			converted++
 			if converted%100000 == 0 {
+				start := common.CopyBytes(key)
 				it.Release()
-				it = db.(*vapdb.LDBDatabase).NewIterator()
-				it.Seek(key)
+				it = db.NewIteratorWithStart(start)
 
 				log.Info("Deduplicating database entries", "deduped", converted)
 			}
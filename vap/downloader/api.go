@@ -19,12 +19,17 @@ package downloader
 import (
 	"context"
 	"sync"
+	"time"
 
 	vapory "github.com/vaporyco/go-vapory"
 	"github.com/vaporyco/go-vapory/event"
 	"github.com/vaporyco/go-vapory/rpc"
 )
 
+// syncStatusInterval is the time between two progress updates being broadcast to
+// the syncing subscriptions while a sync is running.
+const syncStatusInterval = 8 * time.Second
+
 // PublicDownloaderAPI provides an API which gives information about the current synchronisation status.
 // It offers only methods that operates on data that can be available to anyone without security risks.
 type PublicDownloaderAPI struct {
@@ -53,11 +58,15 @@ func NewPublicDownloaderAPI(d *Downloader, m *event.TypeMux) *PublicDownloaderAP
 
 // eventLoop runs an loop until the event mux closes. It will install and uninstall new
 // sync subscriptions and broadcasts sync status updates to the installed sync subscriptions.
+// While a sync is running, its progress is also broadcast periodically.
 func (api *PublicDownloaderAPI) eventLoop() {
 	var (
 		sub               = api.mux.Subscribe(StartEvent{}, DoneEvent{}, FailedEvent{})
 		syncSubscriptions = make(map[chan interface{}]struct{})
+		ticker            = time.NewTicker(syncStatusInterval)
+		syncing           bool
 	)
+	defer ticker.Stop()
 
 	for {
 		select {
@@ -66,6 +75,17 @@ func (api *PublicDownloaderAPI) eventLoop() {
 		case u := <-api.uninstallSyncSubscription:
 			delete(syncSubscriptions, u.c)
 			close(u.uninstalled)
+		case <-ticker.C:
+			if !syncing {
+				continue
+			}
+			notification := &SyncingResult{
+				Syncing: true,
+				Status:  api.d.Progress(),
+			}
+			for c := range syncSubscriptions {
+				c <- notification
+			}
 		case event := <-sub.Chan():
 			if event == nil {
 				return
@@ -74,11 +94,13 @@ func (api *PublicDownloaderAPI) eventLoop() {
 			var notification interface{}
 			switch event.Data.(type) {
 			case StartEvent:
+				syncing = true
 				notification = &SyncingResult{
 					Syncing: true,
 					Status:  api.d.Progress(),
 				}
 			case DoneEvent, FailedEvent:
+				syncing = false
 				notification = false
 			}
 			// broadcast
@@ -109,8 +109,9 @@ type Downloader struct {
 	rttConfidence uint64 // Confidence in the estimated RTT (unit: millionths to allow atomic ops)
 
 	// Statistics
-	syncStatsChainOrigin uint64 // Origin block number where syncing started at
-	syncStatsChainHeight uint64 // Highest block number known when syncing started
+	syncStatsChainOrigin uint64    // Origin block number where syncing started at
+	syncStatsChainHeight uint64    // Highest block number known when syncing started
+	syncStatsChainStart  time.Time // Time when syncing started from the origin block
 	syncStatsState       stateSyncStats
 	syncStatsLock        sync.RWMutex // Lock protecting the sync stats fields
 
@@ -240,6 +241,9 @@ func New(mode SyncMode, stateDb vapdb.Database, mux *event.TypeMux, chain BlockC
 // In addition, during the state download phase of fast synchronisation the number
 // of processed and the total number of known states are also returned. Otherwise
 // these are zero.
+//
+// Lastly, the completion percentage and the remaining time of the sync are also
+// estimated based on the retrieval rates measured since the sync started.
 func (d *Downloader) Progress() vapory.SyncProgress {
 	// Lock the current stats and return the progress
 	d.syncStatsLock.RLock()
@@ -254,13 +258,55 @@ func (d *Downloader) Progress() vapory.SyncProgress {
 	case LightSync:
 		current = d.lightchain.CurrentHeader().Number.Uint64()
 	}
+	percentage, eta := d.estimate(current)
+
 	return vapory.SyncProgress{
 		StartingBlock: d.syncStatsChainOrigin,
 		CurrentBlock:  current,
 		HighestBlock:  d.syncStatsChainHeight,
 		PulledStates:  d.syncStatsState.processed,
 		KnownStates:   d.syncStatsState.processed + d.syncStatsState.pending,
+		Percentage:    percentage,
+		ETA:           eta,
+	}
+}
+
+// estimate approximates the completion percentage of the running sync and the
+// time remaining until it finishes. The block progress is measured relative to
+// the origin of the sync, whereas in fast sync mode the state download weighs
+// in equally, its remaining time extrapolated from the state retrieval rate.
+//
+// The method assumes the sync stats lock is held.
+func (d *Downloader) estimate(current uint64) (float64, time.Duration) {
+	var (
+		blocks = 1.0
+		eta    time.Duration
+	)
+	if origin, height := d.syncStatsChainOrigin, d.syncStatsChainHeight; current < height && height > origin {
+		blocks = 0
+		if current > origin {
+			done := float64(current - origin)
+			blocks = done / float64(height-origin)
+			eta = time.Duration(float64(time.Since(d.syncStatsChainStart)) * float64(height-current) / done)
+		}
+	}
+	if d.mode != FastSync {
+		return 100 * blocks, eta
+	}
+	var (
+		stats = d.syncStatsState
+		state float64
+	)
+	if known := stats.processed + stats.pending; known > 0 {
+		state = float64(stats.processed) / float64(known)
+	}
+	if stats.pending > 0 && !stats.started.IsZero() && stats.processed > stats.initial {
+		rate := float64(stats.processed-stats.initial) / float64(time.Since(stats.started))
+		if remaining := time.Duration(float64(stats.pending) / rate); remaining > eta {
+			eta = remaining
+		}
 	}
+	return 50 * (blocks + state), eta
 }
 
 // Synchronising returns whether the downloader is currently retrieving blocks.
@@ -432,6 +478,10 @@ func (d *Downloader) syncWithPeer(p *peerConnection, hash common.Hash, td *big.I
 	d.syncStatsLock.Lock()
 	if d.syncStatsChainHeight <= origin || d.syncStatsChainOrigin > origin {
 		d.syncStatsChainOrigin = origin
+		d.syncStatsChainStart = time.Now()
+
+		d.syncStatsState.started = time.Time{}
+		d.syncStatsState.initial = d.syncStatsState.processed
 	}
 	d.syncStatsChainHeight = height
 	d.syncStatsLock.Unlock()
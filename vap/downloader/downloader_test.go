@@ -293,7 +293,7 @@ func (dl *downloadTester) CurrentFastBlock() *types.Block {
 func (dl *downloadTester) FastSyncCommitHead(hash common.Hash) error {
 	// For now only check that the state trie is correct
 	if block := dl.GetBlockByHash(hash); block != nil {
-		_, err := trie.NewSecure(block.Root(), dl.stateDb, 0)
+		_, err := trie.NewSecure(block.Root(), trie.NewDatabase(dl.stateDb), 0)
 		return err
 	}
 	return fmt.Errorf("non existent block: %x", hash[:4])
@@ -1858,3 +1858,120 @@ func testFastCriticalRestarts(t *testing.T, protocol int, progress bool) {
 	// completed using a single mode of operation, whereas fast-then-slow can result
 	// in arbitrary intermediate state that's not cleanly verifiable.
 }
+
+// Tests that an interrupted state sync persists its progress and a subsequent
+// sync of the same state resumes without requesting any retrieved nodes again.
+func TestStateSyncResume(t *testing.T) {
+	tester := newTester()
+	defer tester.terminate()
+
+	// Create a source state with a few hundred accounts to sync
+	srcDb, _ := vapdb.NewMemDatabase()
+	srcState := state.NewDatabase(srcDb)
+
+	statedb, _ := state.New(common.Hash{}, srcState)
+	for i := 0; i < 256; i++ {
+		statedb.AddBalance(common.BytesToAddress([]byte{byte(i)}), big.NewInt(int64(i+1)))
+	}
+	root, _ := statedb.Commit(false)
+	if err := srcState.TrieDB().Commit(root, false); err != nil {
+		t.Fatalf("failed to commit source state: %v", err)
+	}
+	deliver := func(sync *stateSync, hashes []common.Hash) {
+		for _, hash := range hashes {
+			blob, err := srcDb.Get(hash[:])
+			if err != nil {
+				t.Fatalf("failed to retrieve node data for %x: %v", hash, err)
+			}
+			if _, _, err := sync.processNodeData(blob); err != nil {
+				t.Fatalf("failed to process node data for %x: %v", hash, err)
+			}
+			sync.numUncommitted++
+		}
+	}
+	// Retrieve the top of the state trie and interrupt the sync
+	sync := newStateSync(tester.downloader, root)
+	for i := 0; i < 2; i++ {
+		deliver(sync, sync.sched.Missing(0))
+	}
+	fetched := sync.sched.Fetched()
+	if len(fetched) == 0 {
+		t.Fatalf("no nodes waiting for their children")
+	}
+	if err := sync.commit(true); err != nil {
+		t.Fatalf("failed to checkpoint state sync: %v", err)
+	}
+	if progress := readStateSyncProgress(tester.stateDb); progress == nil || progress.Root != root || len(progress.Fetched) != len(fetched) {
+		t.Fatalf("state sync progress mismatch: have %v, want %d fetched nodes of %x", progress, len(fetched), root)
+	}
+	// Resume the sync and ensure none of the retrieved nodes are requested again
+	known := make(map[common.Hash]bool)
+	for _, result := range fetched {
+		known[result.Hash] = true
+	}
+	sync = newStateSync(tester.downloader, root)
+	sync.restore()
+
+	for hashes := sync.sched.Missing(0); len(hashes) > 0; hashes = sync.sched.Missing(0) {
+		for _, hash := range hashes {
+			if known[hash] {
+				t.Fatalf("retrieved node %x requested again", hash)
+			}
+		}
+		deliver(sync, hashes)
+	}
+	if err := sync.commit(true); err != nil {
+		t.Fatalf("failed to commit state sync: %v", err)
+	}
+	if progress := readStateSyncProgress(tester.stateDb); progress != nil {
+		t.Fatalf("state sync progress not cleaned up")
+	}
+	// Ensure the synced state is complete
+	synced, err := state.New(root, state.NewDatabase(tester.stateDb))
+	if err != nil {
+		t.Fatalf("failed to open synced state: %v", err)
+	}
+	it := state.NewNodeIterator(synced)
+	for it.Next() {
+	}
+	if it.Error != nil {
+		t.Fatalf("synced state incomplete: %v", it.Error)
+	}
+}
+
+// Tests that the sync progress estimates account for the state download during
+// fast sync and extrapolate the remaining time from the measured rates.
+func TestSyncProgressEstimate(t *testing.T) {
+	tester := newTester()
+	defer tester.terminate()
+
+	d := tester.downloader
+	d.syncStatsLock.Lock()
+	d.mode = FastSync
+	d.syncStatsChainOrigin, d.syncStatsChainHeight = 0, 100
+	d.syncStatsChainStart = time.Now().Add(-10 * time.Second)
+	d.syncStatsState = stateSyncStats{
+		processed: 60,
+		pending:   40,
+		started:   time.Now().Add(-10 * time.Second),
+		initial:   10,
+	}
+	d.syncStatsLock.Unlock()
+
+	// No blocks imported yet, 60% of the state downloaded at 5 entries per second
+	progress := d.Progress()
+	if progress.Percentage != 30 {
+		t.Errorf("percentage mismatch: have %v, want %v", progress.Percentage, 30)
+	}
+	if progress.ETA < 7*time.Second || progress.ETA > 9*time.Second {
+		t.Errorf("ETA mismatch: have %v, want ~%v", progress.ETA, 8*time.Second)
+	}
+	// Full sync disregards the state download
+	d.syncStatsLock.Lock()
+	d.mode = FullSync
+	d.syncStatsLock.Unlock()
+
+	if progress := d.Progress(); progress.Percentage != 0 || progress.ETA != 0 {
+		t.Errorf("full sync estimate mismatch: have %v/%v, want %v/%v", progress.Percentage, progress.ETA, 0, 0)
+	}
+}
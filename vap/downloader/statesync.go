@@ -24,13 +24,53 @@ import (
 	"time"
 
 	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core"
 	"github.com/vaporyco/go-vapory/core/state"
 	"github.com/vaporyco/go-vapory/crypto/sha3"
 	"github.com/vaporyco/go-vapory/vapdb"
 	"github.com/vaporyco/go-vapory/log"
+	"github.com/vaporyco/go-vapory/rlp"
 	"github.com/vaporyco/go-vapory/trie"
 )
 
+// stateSyncCheckpointInterval is the minimum time between two checkpoints of a
+// running state sync being persisted into the database.
+const stateSyncCheckpointInterval = time.Minute
+
+// stateSyncProgress is the progress marker of a state sync, persisted so that an
+// interrupted sync can be resumed without retrieving the same data again. Every
+// trie node committed into the database marks an already completed subtrie, so
+// only the retrieved nodes still waiting for their children need to be saved.
+type stateSyncProgress struct {
+	Root      common.Hash       // State root the sync was retrieving
+	Fetched   []trie.SyncResult // Retrieved trie nodes waiting for their children
+	Processed uint64            // Number of state entries processed until now
+}
+
+// readStateSyncProgress retrieves the progress of an interrupted state sync, or
+// nil if no state sync was in progress.
+func readStateSyncProgress(db vapdb.Database) *stateSyncProgress {
+	data, _ := db.Get(core.StateSyncProgressKey)
+	if len(data) == 0 {
+		return nil
+	}
+	progress := new(stateSyncProgress)
+	if err := rlp.DecodeBytes(data, progress); err != nil {
+		log.Error("Invalid state sync progress", "err", err)
+		return nil
+	}
+	return progress
+}
+
+// writeStateSyncProgress stores the progress of a state sync.
+func writeStateSyncProgress(db vapdb.Putter, progress *stateSyncProgress) error {
+	data, err := rlp.EncodeToBytes(progress)
+	if err != nil {
+		return err
+	}
+	return db.Put(core.StateSyncProgressKey, data)
+}
+
 // stateReq represents a batch of state fetch requests groupped together into
 // a single data retrieval network packet.
 type stateReq struct {
@@ -55,6 +95,9 @@ type stateSyncStats struct {
 	duplicate  uint64 // Number of state entries downloaded twice
 	unexpected uint64 // Number of non-requested state entries received
 	pending    uint64 // Number of still pending state entries
+
+	started time.Time // Time when the first state entries were processed in this sync cycle
+	initial uint64    // Number of state entries already processed when the sync cycle started
 }
 
 // syncState starts downloading state with the given root hash.
@@ -212,7 +255,8 @@ func (d *Downloader) runStateSync(s *stateSync) *stateSync {
 // stateSync schedules requests for downloading a particular state trie defined
 // by a given state root.
 type stateSync struct {
-	d *Downloader // Downloader instance to access and manage current peerset
+	d    *Downloader // Downloader instance to access and manage current peerset
+	root common.Hash // State root currently being synced
 
 	sched  *trie.TrieSync             // State trie sync scheduler defining the tasks
 	keccak hash.Hash                  // Keccak256 hasher to verify deliveries with
@@ -220,6 +264,7 @@ type stateSync struct {
 
 	numUncommitted   int
 	bytesUncommitted int
+	checkpointed     time.Time // Time when the progress was last persisted
 
 	deliver    chan *stateReq // Delivery channel multiplexing peer responses
 	cancel     chan struct{}  // Channel to signal a termination request
@@ -239,6 +284,7 @@ type stateTask struct {
 func newStateSync(d *Downloader, root common.Hash) *stateSync {
 	return &stateSync{
 		d:       d,
+		root:    root,
 		sched:   state.NewStateSync(root, d.stateDB),
 		keccak:  sha3.NewKeccak256(),
 		tasks:   make(map[common.Hash]*stateTask),
@@ -252,6 +298,7 @@ func newStateSync(d *Downloader, root common.Hash) *stateSync {
 // it finishes, and finally notifying any goroutines waiting for the loop to
 // finish.
 func (s *stateSync) run() {
+	s.restore()
 	s.err = s.loop()
 	close(s.done)
 }
@@ -292,6 +339,10 @@ func (s *stateSync) loop() error {
 			// New peer arrived, try to assign it download tasks
 
 		case <-s.cancel:
+			// Persist everything retrieved so far to resume the sync later
+			if err := s.commit(true); err != nil {
+				log.Warn("Failed to checkpoint state sync", "err", err)
+			}
 			return errCancelStateFetch
 
 		case req := <-s.deliver:
@@ -318,6 +369,34 @@ func (s *stateSync) loop() error {
 	return s.commit(true)
 }
 
+// restore loads the progress of a previously interrupted state sync, injecting
+// the trie nodes it already retrieved back into the scheduler. Nodes belonging
+// to a different state root are only kept if they are part of the new trie too.
+func (s *stateSync) restore() {
+	progress := readStateSyncProgress(s.d.stateDB)
+	if progress == nil {
+		return
+	}
+	restored := 0
+	for _, result := range progress.Fetched {
+		if _, _, err := s.sched.Process([]trie.SyncResult{result}); err == nil {
+			s.bytesUncommitted += len(result.Data)
+			restored++
+		}
+	}
+	s.d.syncStatsLock.Lock()
+	if s.d.syncStatsState.processed < progress.Processed {
+		s.d.syncStatsState.initial += progress.Processed - s.d.syncStatsState.processed
+		s.d.syncStatsState.processed = progress.Processed
+	}
+	s.d.syncStatsState.pending = uint64(s.sched.Pending())
+	s.d.syncStatsLock.Unlock()
+
+	log.Info("Resuming interrupted state sync", "root", s.root, "previous", progress.Root, "restored", restored, "discarded", len(progress.Fetched)-restored, "processed", progress.Processed)
+}
+
+// commit flushes the retrieved trie nodes into the database if enough of them
+// accumulated (or if forced to), checkpointing the sync progress periodically.
 func (s *stateSync) commit(force bool) error {
 	if !force && s.bytesUncommitted < vapdb.IdealBatchSize {
 		return nil
@@ -325,9 +404,26 @@ func (s *stateSync) commit(force bool) error {
 	start := time.Now()
 	b := s.d.stateDB.NewBatch()
 	s.sched.Commit(b)
+
+	done := s.sched.Pending() == 0
+	if !done && (force || time.Since(s.checkpointed) > stateSyncCheckpointInterval) {
+		s.d.syncStatsLock.RLock()
+		processed := s.d.syncStatsState.processed + uint64(s.numUncommitted)
+		s.d.syncStatsLock.RUnlock()
+
+		if err := writeStateSyncProgress(b, &stateSyncProgress{Root: s.root, Fetched: s.sched.Fetched(), Processed: processed}); err != nil {
+			return err
+		}
+		s.checkpointed = time.Now()
+	}
 	if err := b.Write(); err != nil {
 		return fmt.Errorf("DB write error: %v", err)
 	}
+	if done {
+		if err := s.d.stateDB.Delete(core.StateSyncProgressKey); err != nil {
+			return fmt.Errorf("DB write error: %v", err)
+		}
+	}
 	s.updateStats(s.numUncommitted, 0, 0, time.Since(start))
 	s.numUncommitted = 0
 	s.bytesUncommitted = 0
@@ -467,6 +563,9 @@ func (s *stateSync) updateStats(written, duplicate, unexpected int, duration tim
 	s.d.syncStatsLock.Lock()
 	defer s.d.syncStatsLock.Unlock()
 
+	if s.d.syncStatsState.started.IsZero() && written > 0 {
+		s.d.syncStatsState.started = time.Now()
+	}
 	s.d.syncStatsState.pending = uint64(s.sched.Pending())
 	s.d.syncStatsState.processed += uint64(written)
 	s.d.syncStatsState.duplicate += uint64(duplicate)
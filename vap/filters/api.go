@@ -27,6 +27,7 @@ import (
 
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core"
 	"github.com/vaporyco/go-vapory/core/types"
 	"github.com/vaporyco/go-vapory/vapdb"
 	"github.com/vaporyco/go-vapory/event"
@@ -35,6 +36,8 @@ import (
 
 var (
 	deadline = 5 * time.Minute // consider a filter inactive if it has not been polled for within deadline
+
+	errResumeOverflow = errors.New("too many logs during resume, retry later")
 )
 
 // filter is a helper struct that holds meta information over the filter type
@@ -58,11 +61,14 @@ type PublicFilterAPI struct {
 	events    *EventSystem
 	filtersMu sync.Mutex
 	filters   map[rpc.ID]*filter
+	limits    Limits
 }
 
-// NewPublicFilterAPI returns a new PublicFilterAPI instance.
-func NewPublicFilterAPI(backend Backend, lightMode bool) *PublicFilterAPI {
+// NewPublicFilterAPI returns a new PublicFilterAPI instance. The limits cap the
+// work performed by each historical log query.
+func NewPublicFilterAPI(backend Backend, lightMode bool, limits Limits) *PublicFilterAPI {
 	api := &PublicFilterAPI{
+		limits:  limits,
 		backend: backend,
 		mux:     backend.EventMux(),
 		chainDb: backend.ChainDb(),
@@ -142,10 +148,12 @@ func (api *PublicFilterAPI) NewPendingTransactions(ctx context.Context) (*rpc.Su
 
 	rpcSub := notifier.CreateSubscription()
 
-	go func() {
-		txHashes := make(chan common.Hash)
-		pendingTxSub := api.events.SubscribePendingTxEvents(txHashes)
+	// Install the event subscription before returning, so no transaction entering
+	// the pool after the call is missed
+	txHashes := make(chan common.Hash)
+	pendingTxSub := api.events.SubscribePendingTxEvents(txHashes)
 
+	go func() {
 		for {
 			select {
 			case h := <-txHashes:
@@ -229,30 +237,103 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
+//
+// If the criteria contain the hash of the last block processed by a resuming
+// subscriber, the logs it missed while disconnected are replayed first: removed
+// logs for the blocks orphaned since, followed by the canonical logs after the
+// fork point. Live notifications are held back until the replay completes.
+//
+// The replay is limited to a single page of the configured query caps, and to at
+// most rpc.MaxPendingNotifications logs. If the missed logs don't fit, the
+// subscription is rejected with the *LimitError of the page, and the subscriber
+// needs to catch up via vap_getLogs before resuming. The live logs held back
+// during the replay count against the same budget, as do the ones arriving
+// until the subscription is activated: exceeding it rejects the subscription,
+// or closes the connection once its ID was handed out.
 func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
 	}
-
-	var (
-		rpcSub      = notifier.CreateSubscription()
-		matchedLogs = make(chan []*types.Log)
-	)
+	matchedLogs := make(chan []*types.Log)
 
 	logsSub, err := api.events.SubscribeLogs(crit, matchedLogs)
 	if err != nil {
 		return nil, err
 	}
+	// Gather the logs missed by a resuming subscriber, holding back the live ones
+	var (
+		missed []*types.Log   // Logs missed by the subscriber, to be delivered first
+		queue  [][]*types.Log // Live logs held back during the replay
+	)
+	if crit.LastBlockHash != nil {
+		var (
+			done     = make(chan struct{})
+			held     = make(chan [][]*types.Log)
+			overflow bool // Whether the held back logs exceeded the buffer
+		)
+		go func() {
+			var (
+				queue [][]*types.Log
+				count int
+			)
+			for {
+				select {
+				case logs := <-matchedLogs:
+					if count += len(logs); count > rpc.MaxPendingNotifications {
+						queue, overflow = nil, true
+					} else if !overflow {
+						queue = append(queue, logs)
+					}
+				case <-done:
+					held <- queue
+					return
+				}
+			}
+		}()
+		missed, err = api.replayLogs(ctx, crit)
+		close(done)
+		queue = <-held
+
+		if err == nil {
+			count := len(missed)
+			for _, logs := range queue {
+				count += len(logs)
+			}
+			if overflow || count > rpc.MaxPendingNotifications {
+				err = errResumeOverflow
+			}
+		}
+		if err != nil {
+			logsSub.Unsubscribe()
+			return nil, err
+		}
+	}
+	// Everything up to activation counts against the notifier's buffer, which
+	// fails the subscription if exceeded instead of dropping logs
+	var rpcSub *rpc.Subscription
+	if crit.LastBlockHash != nil {
+		rpcSub = notifier.CreateBufferedSubscription()
+	} else {
+		rpcSub = notifier.CreateSubscription()
+	}
 
 	go func() {
-
+		// Deliver the missed logs, followed by the held back live ones
+		replayed := make(map[common.Hash]bool) // Blocks whose logs were already replayed
+		for _, l := range missed {
+			notifier.Notify(rpcSub.ID, l)
+			if !l.Removed {
+				replayed[l.BlockHash] = true
+			}
+		}
+		for _, logs := range queue {
+			notifyLogs(notifier, rpcSub.ID, logs, replayed)
+		}
 		for {
 			select {
 			case logs := <-matchedLogs:
-				for _, log := range logs {
-					notifier.Notify(rpcSub.ID, &log)
-				}
+				notifyLogs(notifier, rpcSub.ID, logs, replayed)
 			case <-rpcSub.Err(): // client send an unsubscribe request
 				logsSub.Unsubscribe()
 				return
@@ -266,12 +347,108 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 	return rpcSub, nil
 }
 
+// notifyLogs sends live logs to a subscriber, skipping the ones that were already
+// delivered by the replay of a resumed subscription.
+func notifyLogs(notifier *rpc.Notifier, id rpc.ID, logs []*types.Log, replayed map[common.Hash]bool) {
+	for _, log := range logs {
+		if log.Removed {
+			// The block may be reinserted later on, deliver its logs again then
+			delete(replayed, log.BlockHash)
+		} else if replayed[log.BlockHash] {
+			continue
+		}
+		notifier.Notify(id, log)
+	}
+}
+
+// resumePoint looks up the last block processed by a resuming subscriber and
+// returns the headers of the blocks orphaned since (newest first), along with
+// the number of the first canonical block it has not seen yet.
+func (api *PublicFilterAPI) resumePoint(hash common.Hash) ([]*types.Header, uint64, error) {
+	header := core.GetHeader(api.chainDb, hash, core.GetBlockNumber(api.chainDb, hash))
+	if header == nil {
+		return nil, 0, fmt.Errorf("unknown block %x", hash)
+	}
+	var orphaned []*types.Header
+	for header.Hash() != core.GetCanonicalHash(api.chainDb, header.Number.Uint64()) {
+		orphaned = append(orphaned, header)
+		if header = core.GetHeader(api.chainDb, header.ParentHash, header.Number.Uint64()-1); header == nil {
+			return nil, 0, fmt.Errorf("missing ancestor of block %x", hash)
+		}
+	}
+	return orphaned, header.Number.Uint64() + 1, nil
+}
+
+// replayLogs gathers the logs missed by a resuming subscriber: the removal of the
+// matching logs in the blocks orphaned since its last block, followed by the
+// matching canonical logs after the fork point up to the current head.
+//
+// The canonical logs are retrieved with a single query capped by the configured
+// limits, and by the number of notifications the RPC layer buffers until the
+// subscription is activated. If it is cut short, its *LimitError is returned.
+func (api *PublicFilterAPI) replayLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
+	orphaned, from, err := api.resumePoint(*crit.LastBlockHash)
+	if err != nil {
+		return nil, err
+	}
+	// Retract the logs of the blocks that were reorged out
+	var logs []*types.Log
+	for _, header := range orphaned {
+		receipts, err := api.backend.GetReceipts(ctx, header.Hash())
+		if err != nil {
+			return nil, err
+		}
+		var unfiltered []*types.Log
+		for _, receipt := range receipts {
+			for _, l := range receipt.Logs {
+				removed := *l
+				removed.Removed = true
+				unfiltered = append(unfiltered, &removed)
+			}
+		}
+		logs = append(logs, filterLogs(unfiltered, nil, nil, crit.Addresses, crit.Topics)...)
+	}
+	if len(logs) >= rpc.MaxPendingNotifications {
+		return nil, fmt.Errorf("too many removed logs to replay: %d", len(logs))
+	}
+	// Gather the canonical logs within the requested block range
+	head, _ := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if head == nil {
+		return logs, nil
+	}
+	begin, end := int64(from), head.Number.Int64()
+	if crit.FromBlock != nil && crit.FromBlock.Int64() > begin {
+		begin = crit.FromBlock.Int64()
+	}
+	if crit.ToBlock != nil && crit.ToBlock.Sign() >= 0 && crit.ToBlock.Int64() < end {
+		end = crit.ToBlock.Int64()
+	}
+	if begin > end {
+		return logs, nil
+	}
+	limits := api.limits
+	if budget := uint64(rpc.MaxPendingNotifications - len(logs)); limits.MaxLogs == 0 || limits.MaxLogs > budget {
+		limits.MaxLogs = budget
+	}
+	filter := New(api.backend, begin, end, crit.Addresses, crit.Topics)
+	filter.SetLimits(limits)
+
+	found, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(logs, found...), nil
+}
+
 // FilterCriteria represents a request to create a new filter.
 type FilterCriteria struct {
 	FromBlock *big.Int
 	ToBlock   *big.Int
 	Addresses []common.Address
 	Topics    [][]common.Hash
+	Cursor    *Cursor // Position to resume a capped query from (nil = from the start)
+
+	LastBlockHash *common.Hash // Last block processed by a resuming log subscriber (nil = live only)
 }
 
 // NewFilter creates a new filter and returns the filter id. It can be
@@ -321,6 +498,10 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 
 // GetLogs returns logs matching the given argument that are stored within the state.
 //
+// If the query exceeds the configured block range or result count, the logs found
+// so far are returned in the data of a LimitError, along with a cursor that can be
+// passed in the criteria of a follow-up query to fetch the next page.
+//
 // https://github.com/vaporyco/wiki/wiki/JSON-RPC#vap_getlogs
 func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
 	// Convert the RPC block numbers into internal representations
@@ -332,7 +513,10 @@ func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([
 	}
 	// Create and run the filter to get all the logs
 	filter := New(api.backend, crit.FromBlock.Int64(), crit.ToBlock.Int64(), crit.Addresses, crit.Topics)
-
+	filter.SetLimits(api.limits)
+	if crit.Cursor != nil {
+		filter.Resume(*crit.Cursor)
+	}
 	logs, err := filter.Logs(ctx)
 	if err != nil {
 		return nil, err
@@ -380,6 +564,7 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 	}
 	// Create and run the filter to get all the logs
 	filter := New(api.backend, begin, end, f.crit.Addresses, f.crit.Topics)
+	filter.SetLimits(api.limits)
 
 	logs, err := filter.Logs(ctx)
 	if err != nil {
@@ -447,6 +632,9 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		ToBlock   *rpc.BlockNumber `json:"toBlock"`
 		Addresses interface{}      `json:"address"`
 		Topics    []interface{}    `json:"topics"`
+		Cursor    *Cursor          `json:"cursor"`
+
+		LastBlockHash *common.Hash `json:"lastBlockHash"`
 	}
 
 	var raw input
@@ -462,6 +650,9 @@ func (args *FilterCriteria) UnmarshalJSON(data []byte) error {
 		args.ToBlock = big.NewInt(raw.ToBlock.Int64())
 	}
 
+	args.Cursor = raw.Cursor
+	args.LastBlockHash = raw.LastBlockHash
+
 	args.Addresses = []common.Address{}
 
 	if raw.Addresses != nil {
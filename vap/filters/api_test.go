@@ -182,4 +182,14 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 	if len(test7.Topics[2]) != 0 {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
+
+	// test resume cursor
+	var test8 FilterCriteria
+	vector = `{"fromBlock":"0x1", "cursor": {"blockNumber": "0x10", "logIndex": "0x2"}}`
+	if err := json.Unmarshal([]byte(vector), &test8); err != nil {
+		t.Fatal(err)
+	}
+	if test8.Cursor == nil || *test8.Cursor != (Cursor{BlockNumber: 16, LogIndex: 2}) {
+		t.Fatalf("invalid cursor, want {16 2}, got %v", test8.Cursor)
+	}
 }
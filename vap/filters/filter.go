@@ -18,9 +18,13 @@ package filters
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math/big"
 
 	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
 	"github.com/vaporyco/go-vapory/core"
 	"github.com/vaporyco/go-vapory/core/bloombits"
 	"github.com/vaporyco/go-vapory/core/types"
@@ -44,15 +48,89 @@ type Backend interface {
 	ServiceFilter(ctx context.Context, session *bloombits.MatcherSession)
 }
 
+// errCursorOutOfRange is returned if a log query is resumed from a cursor that
+// precedes the start of the queried block range.
+var errCursorOutOfRange = errors.New("cursor before start of block range")
+
+// Limits caps the amount of work a single log query is allowed to perform.
+type Limits struct {
+	MaxBlocks uint64 // Maximum number of blocks searched by a single query (0 = unlimited)
+	MaxLogs   uint64 // Maximum number of logs returned by a single query (0 = unlimited)
+}
+
+// Cursor is a position within the log stream of the chain, identifying the
+// first log to return when a capped query is resumed.
+type Cursor struct {
+	BlockNumber uint64 // Number of the block to resume the search from
+	LogIndex    uint   // Index of the first log to return within the block
+}
+
+type cursorJSON struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+}
+
+// MarshalJSON implements json.Marshaler, encoding the cursor fields as hex.
+func (c Cursor) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cursorJSON{hexutil.Uint64(c.BlockNumber), hexutil.Uint(c.LogIndex)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (c *Cursor) UnmarshalJSON(input []byte) error {
+	var dec cursorJSON
+	if err := json.Unmarshal(input, &dec); err != nil {
+		return err
+	}
+	c.BlockNumber, c.LogIndex = uint64(dec.BlockNumber), uint(dec.LogIndex)
+	return nil
+}
+
+// LimitError is returned if a log query was cut short by one of the configured
+// limits. The logs found up to the cut are returned along with it, and the query
+// can be continued by resuming it from the cursor.
+type LimitError struct {
+	Reason string // Limit that was hit ("block range" or "result count")
+	Limit  uint64 // Configured value of the limit that was hit
+	Cursor Cursor // Position from which to resume the query
+
+	Logs []*types.Log // Logs found before the limit was hit
+}
+
+// Error implements error.
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("query exceeds max %s of %d, resume from block %d log %d", e.Reason, e.Limit, e.Cursor.BlockNumber, e.Cursor.LogIndex)
+}
+
+// ErrorCode returns the JSON-RPC error code of a capped log query.
+func (e *LimitError) ErrorCode() int { return -32005 }
+
+// ErrorData returns the logs found so far and the position to resume from,
+// so that RPC callers may continue the query with the next page.
+func (e *LimitError) ErrorData() interface{} {
+	logs := e.Logs
+	if logs == nil {
+		logs = []*types.Log{}
+	}
+	return map[string]interface{}{
+		"limit":  hexutil.Uint64(e.Limit),
+		"cursor": e.Cursor,
+		"logs":   logs,
+	}
+}
+
 // Filter can be used to retrieve and filter logs.
 type Filter struct {
 	backend Backend
 
 	db         vapdb.Database
 	begin, end int64
+	block      common.Hash // Block to inspect directly instead of a range (zero if unset)
 	addresses  []common.Address
 	topics     [][]common.Hash
 
+	limits Limits  // Caps on the blocks searched and logs returned
+	cursor *Cursor // Position to resume the search from (nil = start of the range)
+
 	matcher *bloombits.Matcher
 }
 
@@ -91,9 +169,41 @@ func New(backend Backend, begin, end int64, addresses []common.Address, topics [
 	}
 }
 
+// NewBlockFilter creates a new filter which directly inspects the receipts of a
+// single block, identified by hash, to find the matching log entries.
+func NewBlockFilter(backend Backend, block common.Hash, addresses []common.Address, topics [][]common.Hash) *Filter {
+	return &Filter{
+		backend:   backend,
+		block:     block,
+		addresses: addresses,
+		topics:    topics,
+		db:        backend.ChainDb(),
+	}
+}
+
+// SetLimits caps the number of blocks searched and logs returned by the filter.
+// If either limit is hit, Logs returns a *LimitError along with the logs found.
+func (f *Filter) SetLimits(limits Limits) {
+	f.limits = limits
+}
+
+// Resume sets the position from which the filter continues a previously capped
+// search, skipping all the logs preceding the cursor.
+func (f *Filter) Resume(cursor Cursor) {
+	f.cursor = &cursor
+}
+
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
 func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
+	// If we're doing singleton block filtering, execute and return
+	if f.block != (common.Hash{}) {
+		found, err := f.blockLogs(ctx, f.block)
+		if err != nil || len(found) == 0 {
+			return found, err
+		}
+		return f.collect(nil, found[0].BlockNumber, found)
+	}
 	// Figure out the limits of the filter range
 	header, _ := f.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
 	if header == nil {
@@ -108,6 +218,17 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end == -1 {
 		end = head
 	}
+	if f.cursor != nil {
+		if f.cursor.BlockNumber < uint64(f.begin) {
+			return nil, errCursorOutOfRange
+		}
+		f.begin = int64(f.cursor.BlockNumber)
+	}
+	// Cut the range short if it spans more blocks than permitted
+	var capped bool
+	if max := f.limits.MaxBlocks; max > 0 && uint64(f.begin) <= end && end-uint64(f.begin) >= max {
+		end, capped = uint64(f.begin)+max-1, true
+	}
 	// Gather all indexed logs, and finish with non indexed ones
 	var (
 		logs []*types.Log
@@ -124,8 +245,10 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 			return logs, err
 		}
 	}
-	rest, err := f.unindexedLogs(ctx, end)
-	logs = append(logs, rest...)
+	logs, err = f.unindexedLogs(ctx, end, logs)
+	if err == nil && capped {
+		err = &LimitError{Reason: "block range", Limit: f.limits.MaxBlocks, Cursor: Cursor{BlockNumber: end + 1}, Logs: logs}
+	}
 	return logs, err
 }
 
@@ -168,7 +291,9 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 			if err != nil {
 				return logs, err
 			}
-			logs = append(logs, found...)
+			if logs, err = f.collect(logs, number, found); err != nil {
+				return logs, err
+			}
 
 		case <-ctx.Done():
 			return logs, ctx.Err()
@@ -176,12 +301,13 @@ func (f *Filter) indexedLogs(ctx context.Context, end uint64) ([]*types.Log, err
 	}
 }
 
-// indexedLogs returns the logs matching the filter criteria based on raw block
-// iteration and bloom matching.
-func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, error) {
-	var logs []*types.Log
-
+// unindexedLogs returns the logs matching the filter criteria based on raw block
+// iteration and bloom matching, appending them to the ones already found.
+func (f *Filter) unindexedLogs(ctx context.Context, end uint64, logs []*types.Log) ([]*types.Log, error) {
 	for ; f.begin <= int64(end); f.begin++ {
+		if err := ctx.Err(); err != nil {
+			return logs, err
+		}
 		header, err := f.backend.HeaderByNumber(ctx, rpc.BlockNumber(f.begin))
 		if header == nil || err != nil {
 			return logs, err
@@ -191,17 +317,41 @@ func (f *Filter) unindexedLogs(ctx context.Context, end uint64) ([]*types.Log, e
 			if err != nil {
 				return logs, err
 			}
-			logs = append(logs, found...)
+			if logs, err = f.collect(logs, uint64(f.begin), found); err != nil {
+				return logs, err
+			}
+		}
+	}
+	return logs, nil
+}
+
+// collect appends the logs found in the given block to the results, skipping the
+// ones preceding the resume cursor. If the result cap is exceeded, the results are
+// cut at the cap and a *LimitError pointing to the first dropped log is returned.
+func (f *Filter) collect(logs []*types.Log, number uint64, found []*types.Log) ([]*types.Log, error) {
+	for _, log := range found {
+		if f.cursor != nil && number == f.cursor.BlockNumber && log.Index < f.cursor.LogIndex {
+			continue
 		}
+		if max := f.limits.MaxLogs; max > 0 && uint64(len(logs)) >= max {
+			return logs, &LimitError{Reason: "result count", Limit: max, Cursor: Cursor{BlockNumber: number, LogIndex: log.Index}, Logs: logs}
+		}
+		logs = append(logs, log)
 	}
 	return logs, nil
 }
 
 // checkMatches checks if the receipts belonging to the given header contain any log events that
 // match the filter criteria. This function is called when the bloom filter signals a potential match.
-func (f *Filter) checkMatches(ctx context.Context, header *types.Header) (logs []*types.Log, err error) {
+func (f *Filter) checkMatches(ctx context.Context, header *types.Header) ([]*types.Log, error) {
+	return f.blockLogs(ctx, header.Hash())
+}
+
+// blockLogs returns the logs contained in the receipts of the given block that
+// match the filter criteria.
+func (f *Filter) blockLogs(ctx context.Context, hash common.Hash) ([]*types.Log, error) {
 	// Get the logs of the block
-	receipts, err := f.backend.GetReceipts(ctx, header.Hash())
+	receipts, err := f.backend.GetReceipts(ctx, hash)
 	if err != nil {
 		return nil, err
 	}
@@ -209,7 +359,7 @@ func (f *Filter) checkMatches(ctx context.Context, header *types.Header) (logs [
 	for _, receipt := range receipts {
 		unfiltered = append(unfiltered, receipt.Logs...)
 	}
-	logs = filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
+	logs := filterLogs(unfiltered, nil, nil, f.addresses, f.topics)
 	if len(logs) > 0 {
 		return logs, nil
 	}
@@ -22,6 +22,7 @@ import (
 	"math/big"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -135,7 +136,7 @@ func TestBlockSubscription(t *testing.T) {
 		logsFeed    = new(event.Feed)
 		chainFeed   = new(event.Feed)
 		backend     = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
-		api         = NewPublicFilterAPI(backend, false)
+		api         = NewPublicFilterAPI(backend, false, Limits{})
 		genesis     = new(core.Genesis).MustCommit(db)
 		chain, _    = core.GenerateChain(params.TestChainConfig, genesis, vapash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {})
 		chainEvents = []core.ChainEvent{}
@@ -192,7 +193,7 @@ func TestPendingTxFilter(t *testing.T) {
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
 		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
-		api        = NewPublicFilterAPI(backend, false)
+		api        = NewPublicFilterAPI(backend, false, Limits{})
 
 		transactions = []*types.Transaction{
 			types.NewTransaction(0, common.HexToAddress("0xb794f5ea0ba39494ce83a213fffba74279579268"), new(big.Int), 0, new(big.Int), nil),
@@ -255,7 +256,7 @@ func TestLogFilterCreation(t *testing.T) {
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
 		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
-		api        = NewPublicFilterAPI(backend, false)
+		api        = NewPublicFilterAPI(backend, false, Limits{})
 
 		testCases = []struct {
 			crit    FilterCriteria
@@ -304,7 +305,7 @@ func TestInvalidLogFilterCreation(t *testing.T) {
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
 		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
-		api        = NewPublicFilterAPI(backend, false)
+		api        = NewPublicFilterAPI(backend, false, Limits{})
 	)
 
 	// different situations where log filter creation should fail.
@@ -334,7 +335,7 @@ func TestLogFilter(t *testing.T) {
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
 		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
-		api        = NewPublicFilterAPI(backend, false)
+		api        = NewPublicFilterAPI(backend, false, Limits{})
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
 		secondAddr     = common.HexToAddress("0x2222222222222222222222222222222222222222")
@@ -453,7 +454,7 @@ func TestPendingLogsSubscription(t *testing.T) {
 		logsFeed   = new(event.Feed)
 		chainFeed  = new(event.Feed)
 		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
-		api        = NewPublicFilterAPI(backend, false)
+		api        = NewPublicFilterAPI(backend, false, Limits{})
 
 		firstAddr      = common.HexToAddress("0x1111111111111111111111111111111111111111")
 		secondAddr     = common.HexToAddress("0x2222222222222222222222222222222222222222")
@@ -558,3 +559,118 @@ func TestPendingLogsSubscription(t *testing.T) {
 		}
 	}
 }
+
+// TestResumeLogsSubscription tests that a log subscription resumed from a block on
+// a reorged out side chain first receives the removal of the orphaned logs, then
+// the canonical logs after the fork point, before switching to live logs.
+func TestResumeLogsSubscription(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mux        = new(event.TypeMux)
+		db, _      = vapdb.NewMemDatabase()
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		api        = NewPublicFilterAPI(backend, false, Limits{MaxLogs: 3})
+
+		addr    = common.HexToAddress("0x1111111111111111111111111111111111111111")
+		topic   = common.HexToHash("0x1111111111111111111111111111111111111111111111111111111111111111")
+		genesis = core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	)
+	// Generate a canonical chain of 5 blocks and a side chain of 2 blocks forking
+	// off after block 2, with every block containing a single log.
+	generate := func(parent *types.Block, n int, canonical bool) []*types.Block {
+		blocks, receipts := core.GenerateChain(params.TestChainConfig, parent, vapash.NewFaker(), db, n, func(i int, gen *core.BlockGen) {
+			receipt := types.NewReceipt(nil, false, 0)
+			receipt.Logs = []*types.Log{{Address: addr, Topics: []common.Hash{topic}}}
+			gen.AddUncheckedReceipt(receipt)
+			if !canonical {
+				gen.SetExtra([]byte("side"))
+			}
+		})
+		for i, block := range blocks {
+			for _, log := range receipts[i][0].Logs {
+				log.BlockNumber, log.BlockHash = block.NumberU64(), block.Hash()
+			}
+			core.WriteBlock(db, block)
+			if err := core.WriteBlockReceipts(db, block.Hash(), block.NumberU64(), receipts[i]); err != nil {
+				t.Fatal("error writing block receipts:", err)
+			}
+			if canonical {
+				if err := core.WriteCanonicalHash(db, block.Hash(), block.NumberU64()); err != nil {
+					t.Fatalf("failed to insert block number: %v", err)
+				}
+				if err := core.WriteHeadBlockHash(db, block.Hash()); err != nil {
+					t.Fatalf("failed to insert block number: %v", err)
+				}
+			}
+		}
+		return blocks
+	}
+	chain := generate(genesis, 5, true)
+	side := generate(chain[1], 2, false)
+
+	server := rpc.NewServer()
+	if err := server.RegisterName("vap", api); err != nil {
+		t.Fatal(err)
+	}
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	logs := make(chan types.Log, 16)
+	sub, err := client.VapSubscribe(context.Background(), logs, "logs", map[string]interface{}{"lastBlockHash": side[1].Hash()})
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Post live logs: one of an already replayed block and a new one
+	live := &types.Log{Address: addr, Topics: []common.Hash{topic}, BlockNumber: 6, BlockHash: common.Hash{0x06}}
+	logsFeed.Send([]*types.Log{{Address: addr, Topics: []common.Hash{topic}, BlockNumber: 4, BlockHash: chain[3].Hash()}, live})
+
+	type position struct {
+		hash    common.Hash
+		removed bool
+	}
+	want := []position{
+		{side[1].Hash(), true}, {side[0].Hash(), true},
+		{chain[2].Hash(), false}, {chain[3].Hash(), false}, {chain[4].Hash(), false},
+		{live.BlockHash, false},
+	}
+	for i, w := range want {
+		select {
+		case log := <-logs:
+			if have := (position{log.BlockHash, log.Removed}); have != w {
+				t.Errorf("log %d: mismatch: have %x (removed %v), want %x (removed %v)", i, have.hash, have.removed, w.hash, w.removed)
+			}
+		case err := <-sub.Err():
+			t.Fatalf("subscription failed: %v", err)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("log %d: not delivered", i)
+		}
+	}
+	select {
+	case log := <-logs:
+		t.Errorf("unexpected log delivered: %x", log.BlockHash)
+	case <-time.After(100 * time.Millisecond):
+	}
+	// Resuming from an unknown block is rejected
+	if _, err := client.VapSubscribe(context.Background(), logs, "logs", map[string]interface{}{"lastBlockHash": common.Hash{0xff}}); err == nil {
+		t.Errorf("expected error resuming from unknown block")
+	}
+	// Resuming with more missed logs than fit into a single capped page is rejected
+	capped := rpc.NewServer()
+	if err := capped.RegisterName("vap", NewPublicFilterAPI(backend, false, Limits{MaxLogs: 2})); err != nil {
+		t.Fatal(err)
+	}
+	cappedClient := rpc.DialInProc(capped)
+	defer cappedClient.Close()
+
+	_, err = cappedClient.VapSubscribe(context.Background(), logs, "logs", map[string]interface{}{"lastBlockHash": side[1].Hash()})
+	if err == nil || !strings.Contains(err.Error(), "query exceeds max result count of 2, resume from block 5 log 0") {
+		t.Errorf("oversized replay error mismatch: have %v", err)
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"io/ioutil"
 	"math/big"
 	"os"
+	"reflect"
 	"testing"
 
 	"github.com/vaporyco/go-vapory/common"
@@ -241,3 +242,113 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 }
+
+// Tests that log queries are cut short by the configured limits and can be
+// resumed from the cursor of the returned error.
+func TestFilterLimits(t *testing.T) {
+	var (
+		db, _      = vapdb.NewMemDatabase()
+		mux        = new(event.TypeMux)
+		txFeed     = new(event.Feed)
+		rmLogsFeed = new(event.Feed)
+		logsFeed   = new(event.Feed)
+		chainFeed  = new(event.Feed)
+		backend    = &testBackend{mux, db, 0, txFeed, rmLogsFeed, logsFeed, chainFeed}
+		addr       = common.BytesToAddress([]byte("jeff"))
+	)
+	genesis := core.GenesisBlockForTesting(db, addr, big.NewInt(1000000))
+	chain, receipts := core.GenerateChain(params.TestChainConfig, genesis, vapash.NewFaker(), db, 10, func(i int, gen *core.BlockGen) {
+		// Blocks 2 and 5 contain three logs each, block 7 a single one
+		var count int
+		switch i + 1 {
+		case 2, 5:
+			count = 3
+		case 7:
+			count = 1
+		}
+		if count > 0 {
+			receipt := types.NewReceipt(nil, false, 0)
+			for j := 0; j < count; j++ {
+				receipt.Logs = append(receipt.Logs, &types.Log{Address: addr, BlockNumber: uint64(i + 1), Index: uint(j)})
+			}
+			gen.AddUncheckedReceipt(receipt)
+		}
+	})
+	for i, block := range chain {
+		core.WriteBlock(db, block)
+		if err := core.WriteCanonicalHash(db, block.Hash(), block.NumberU64()); err != nil {
+			t.Fatalf("failed to insert block number: %v", err)
+		}
+		if err := core.WriteHeadBlockHash(db, block.Hash()); err != nil {
+			t.Fatalf("failed to insert block number: %v", err)
+		}
+		if err := core.WriteBlockReceipts(db, block.Hash(), block.NumberU64(), receipts[i]); err != nil {
+			t.Fatal("error writing block receipts:", err)
+		}
+	}
+	// position is a shorthand for the location of a log
+	type position struct {
+		number uint64
+		index  uint
+	}
+	// page runs a capped query over the whole chain, resuming from the cursor
+	page := func(limits Limits, cursor *Cursor) ([]position, *LimitError) {
+		filter := New(backend, 0, -1, []common.Address{addr}, nil)
+		filter.SetLimits(limits)
+		if cursor != nil {
+			filter.Resume(*cursor)
+		}
+		logs, err := filter.Logs(context.Background())
+
+		var positions []position
+		for _, log := range logs {
+			positions = append(positions, position{log.BlockNumber, log.Index})
+		}
+		if err == nil {
+			return positions, nil
+		}
+		limitErr, ok := err.(*LimitError)
+		if !ok {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(limitErr.Logs) != len(logs) {
+			t.Errorf("error log count mismatch: have %d, want %d", len(limitErr.Logs), len(logs))
+		}
+		return positions, limitErr
+	}
+	tests := []struct {
+		limits Limits
+		pages  [][]position
+	}{
+		// No limits return everything in one go
+		{Limits{}, [][]position{{{2, 0}, {2, 1}, {2, 2}, {5, 0}, {5, 1}, {5, 2}, {7, 0}}}},
+		// Result count caps may split the logs of a block
+		{Limits{MaxLogs: 4}, [][]position{{{2, 0}, {2, 1}, {2, 2}, {5, 0}}, {{5, 1}, {5, 2}, {7, 0}}}},
+		{Limits{MaxLogs: 2}, [][]position{{{2, 0}, {2, 1}}, {{2, 2}, {5, 0}}, {{5, 1}, {5, 2}}, {{7, 0}}}},
+		// Block range caps cut at block boundaries, even if no logs are found
+		{Limits{MaxBlocks: 4}, [][]position{{{2, 0}, {2, 1}, {2, 2}}, {{5, 0}, {5, 1}, {5, 2}, {7, 0}}, nil}},
+		// Both limits are enforced at the same time
+		{Limits{MaxBlocks: 6, MaxLogs: 2}, [][]position{{{2, 0}, {2, 1}}, {{2, 2}, {5, 0}}, {{5, 1}, {5, 2}}, {{7, 0}}}},
+	}
+	for i, tt := range tests {
+		var cursor *Cursor
+		for j, want := range tt.pages {
+			have, err := page(tt.limits, cursor)
+			if !reflect.DeepEqual(have, want) {
+				t.Errorf("test %d, page %d: logs mismatch: have %v, want %v", i, j, have, want)
+			}
+			if last := j == len(tt.pages)-1; last != (err == nil) {
+				t.Fatalf("test %d, page %d: error mismatch: have %v, last page %v", i, j, err, last)
+			}
+			if err != nil {
+				cursor = &err.Cursor
+			}
+		}
+	}
+	// Resuming before the start of the range is rejected
+	filter := New(backend, 5, -1, nil, nil)
+	filter.Resume(Cursor{BlockNumber: 4})
+	if _, err := filter.Logs(context.Background()); err != errCursorOutOfRange {
+		t.Errorf("cursor error mismatch: have %v, want %v", err, errCursorOutOfRange)
+	}
+}
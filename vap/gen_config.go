@@ -4,6 +4,7 @@ package vap
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/hexutil"
@@ -25,6 +26,13 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		SkipBcVersionCheck      bool `toml:"-"`
 		DatabaseHandles         int  `toml:"-"`
 		DatabaseCache           int
+		FreezerThreshold        uint64 `toml:",omitempty"`
+		NoPruning               bool
+		TrieCache               int
+		TrieTimeout             time.Duration
+		TrieBlockInterval       uint64
+		TriesInMemory           uint64
+		Snapshot                bool
 		Vapbase               common.Address `toml:",omitempty"`
 		MinerThreads            int            `toml:",omitempty"`
 		ExtraData               hexutil.Bytes  `toml:",omitempty"`
@@ -32,6 +40,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 		Vapash                  vapash.Config
 		TxPool                  core.TxPoolConfig
 		GPO                     gasprice.Config
+		FilterMaxBlocks         uint64 `toml:",omitempty"`
+		FilterMaxLogs           uint64 `toml:",omitempty"`
 		EnablePreimageRecording bool
 		DocRoot                 string `toml:"-"`
 	}
@@ -44,6 +54,13 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.SkipBcVersionCheck = c.SkipBcVersionCheck
 	enc.DatabaseHandles = c.DatabaseHandles
 	enc.DatabaseCache = c.DatabaseCache
+	enc.FreezerThreshold = c.FreezerThreshold
+	enc.NoPruning = c.NoPruning
+	enc.TrieCache = c.TrieCache
+	enc.TrieTimeout = c.TrieTimeout
+	enc.TrieBlockInterval = c.TrieBlockInterval
+	enc.TriesInMemory = c.TriesInMemory
+	enc.Snapshot = c.Snapshot
 	enc.Vapbase = c.Vapbase
 	enc.MinerThreads = c.MinerThreads
 	enc.ExtraData = c.ExtraData
@@ -51,6 +68,8 @@ func (c Config) MarshalTOML() (interface{}, error) {
 	enc.Vapash = c.Vapash
 	enc.TxPool = c.TxPool
 	enc.GPO = c.GPO
+	enc.FilterMaxBlocks = c.FilterMaxBlocks
+	enc.FilterMaxLogs = c.FilterMaxLogs
 	enc.EnablePreimageRecording = c.EnablePreimageRecording
 	enc.DocRoot = c.DocRoot
 	return &enc, nil
@@ -66,6 +85,13 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		SkipBcVersionCheck      *bool `toml:"-"`
 		DatabaseHandles         *int  `toml:"-"`
 		DatabaseCache           *int
+		FreezerThreshold        *uint64 `toml:",omitempty"`
+		NoPruning               *bool
+		TrieCache               *int
+		TrieTimeout             *time.Duration
+		TrieBlockInterval       *uint64
+		TriesInMemory           *uint64
+		Snapshot                *bool
 		Vapbase               *common.Address `toml:",omitempty"`
 		MinerThreads            *int            `toml:",omitempty"`
 		ExtraData               *hexutil.Bytes  `toml:",omitempty"`
@@ -73,6 +99,8 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 		Vapash                  *vapash.Config
 		TxPool                  *core.TxPoolConfig
 		GPO                     *gasprice.Config
+		FilterMaxBlocks         *uint64 `toml:",omitempty"`
+		FilterMaxLogs           *uint64 `toml:",omitempty"`
 		EnablePreimageRecording *bool
 		DocRoot                 *string `toml:"-"`
 	}
@@ -104,6 +132,27 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.DatabaseCache != nil {
 		c.DatabaseCache = *dec.DatabaseCache
 	}
+	if dec.FreezerThreshold != nil {
+		c.FreezerThreshold = *dec.FreezerThreshold
+	}
+	if dec.NoPruning != nil {
+		c.NoPruning = *dec.NoPruning
+	}
+	if dec.TrieCache != nil {
+		c.TrieCache = *dec.TrieCache
+	}
+	if dec.TrieTimeout != nil {
+		c.TrieTimeout = *dec.TrieTimeout
+	}
+	if dec.TrieBlockInterval != nil {
+		c.TrieBlockInterval = *dec.TrieBlockInterval
+	}
+	if dec.TriesInMemory != nil {
+		c.TriesInMemory = *dec.TriesInMemory
+	}
+	if dec.Snapshot != nil {
+		c.Snapshot = *dec.Snapshot
+	}
 	if dec.Vapbase != nil {
 		c.Vapbase = *dec.Vapbase
 	}
@@ -125,6 +174,12 @@ func (c *Config) UnmarshalTOML(unmarshal func(interface{}) error) error {
 	if dec.GPO != nil {
 		c.GPO = *dec.GPO
 	}
+	if dec.FilterMaxBlocks != nil {
+		c.FilterMaxBlocks = *dec.FilterMaxBlocks
+	}
+	if dec.FilterMaxLogs != nil {
+		c.FilterMaxLogs = *dec.FilterMaxLogs
+	}
 	if dec.EnablePreimageRecording != nil {
 		c.EnablePreimageRecording = *dec.EnablePreimageRecording
 	}
@@ -0,0 +1,117 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/vm"
+)
+
+// fourByteTracer is a native implementation of the JavaScript 4byteTracer, which
+// counts the 4 byte method identifiers and call data sizes of all calls made by
+// a transaction.
+type fourByteTracer struct {
+	interruptible
+
+	ids   map[string]int // Number of calls seen per identifier and data size
+	order []string       // Identifiers in the order they were first seen
+	input []byte         // Call data of the outer transaction
+}
+
+// newFourByteTracer creates a native 4byte tracer.
+func newFourByteTracer() *fourByteTracer {
+	return &fourByteTracer{ids: make(map[string]int)}
+}
+
+// store saves the given identifier and data size.
+func (t *fourByteTracer) store(id []byte, size *big.Int) {
+	key := fmt.Sprintf("%s-%s", hexutil.Encode(id), size)
+	if _, ok := t.ids[key]; !ok {
+		t.order = append(t.order, key)
+	}
+	t.ids[key]++
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *fourByteTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.input = input
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *fourByteTracer) CaptureState(env *vm.VVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.halted() {
+		return nil
+	}
+	// Skip any opcodes that are not internal calls, finding the stack position
+	// of the input offset after the value for those that are
+	var pos int
+	switch op {
+	case vm.CALL, vm.CALLCODE:
+		pos = 3 // gas, addr, val, memin, meminsz, memout, memoutsz
+
+	case vm.DELEGATECALL, vm.STATICCALL:
+		pos = 2 // gas, addr, memin, meminsz, memout, memoutsz
+
+	default:
+		return nil
+	}
+	// Skip any pre-compile invocations, those are just fancy opcodes
+	if isPrecompiled(common.BigToAddress(peekStack(stack, 1))) {
+		return nil
+	}
+	// Gather internal call details
+	if size := peekStack(stack, pos+1); size.Cmp(big.NewInt(4)) >= 0 {
+		id := memorySlice(memory, peekStack(stack, pos), big.NewInt(4))
+		t.store(id, new(big.Int).Sub(size, big.NewInt(4)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *fourByteTracer) CaptureFault(env *vm.VVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *fourByteTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the gathered identifier counts, or any error that
+// interrupted the tracing.
+func (t *fourByteTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	// Save the outer call data also
+	if len(t.input) > 4 {
+		t.store(t.input[:4], big.NewInt(int64(len(t.input)-4)))
+	}
+	ids := new(orderedJSON)
+	for _, key := range t.order {
+		ids.add(key, t.ids[key])
+	}
+	return ids.MarshalJSON()
+}
@@ -0,0 +1,264 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/vm"
+)
+
+// callFrame is a single call in the call tree, with its fields ordered as the
+// JavaScript call tracer serializes them.
+type callFrame struct {
+	Type    string       `json:"type,omitempty"`
+	From    string       `json:"from,omitempty"`
+	To      string       `json:"to,omitempty"`
+	Value   string       `json:"value,omitempty"`
+	Gas     string       `json:"gas,omitempty"`
+	GasUsed string       `json:"gasUsed,omitempty"`
+	Input   string       `json:"input,omitempty"`
+	Output  string       `json:"output,omitempty"`
+	Error   string       `json:"error,omitempty"`
+	Time    string       `json:"time,omitempty"`
+	Calls   []*callFrame `json:"calls,omitempty"`
+
+	gasIn    uint64   // Gas available before the call opcode
+	gasCost  uint64   // Gas charged by the call opcode itself
+	gas      uint64   // Gas made available to the callee
+	gasKnown bool     // Whether the callee's gas allowance was observed
+	outOff   *big.Int // Memory offset of the call's return data
+	outLen   *big.Int // Memory size reserved for the call's return data
+}
+
+// callTracer is a native implementation of the JavaScript callTracer, which
+// assembles the tree of internal calls made by a transaction.
+type callTracer struct {
+	interruptible
+
+	callstack []*callFrame // Current recursive call stack of the VVM execution
+	descended bool         // Whether an inner call was just entered
+
+	typ     string
+	from    common.Address
+	to      common.Address
+	input   []byte
+	gas     uint64
+	value   *big.Int
+	output  []byte
+	gasUsed uint64
+	time    time.Duration
+	failure error
+}
+
+// newCallTracer creates a native call tracer.
+func newCallTracer() *callTracer {
+	return &callTracer{callstack: []*callFrame{{}}}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *callTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.typ = "CALL"
+	if create {
+		t.typ = "CREATE"
+	}
+	t.from, t.to, t.input, t.gas, t.value = from, to, input, gas, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *callTracer) CaptureState(env *vm.VVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.halted() {
+		return nil
+	}
+	// Capture any errors immediately
+	if err != nil {
+		t.fault(err)
+		return nil
+	}
+	switch op {
+	case vm.CREATE:
+		// If a new contract is being created, add to the call stack
+		t.callstack = append(t.callstack, &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(contract.Address().Bytes()),
+			Input:   hexutil.Encode(memorySlice(memory, peekStack(stack, 1), peekStack(stack, 2))),
+			Value:   hexBig(peekStack(stack, 0)),
+			gasIn:   gas,
+			gasCost: cost,
+		})
+		t.descended = true
+		return nil
+
+	case vm.SELFDESTRUCT:
+		// If a contract is being self destructed, gather that as a subcall too
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, &callFrame{Type: op.String()})
+		return nil
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		// Skip any pre-compile invocations, those are just fancy opcodes
+		to := common.BigToAddress(peekStack(stack, 1))
+		if isPrecompiled(to) {
+			return nil
+		}
+		off := 1
+		if op == vm.DELEGATECALL || op == vm.STATICCALL {
+			off = 0
+		}
+		call := &callFrame{
+			Type:    op.String(),
+			From:    hexutil.Encode(contract.Address().Bytes()),
+			To:      hexutil.Encode(to.Bytes()),
+			Input:   hexutil.Encode(memorySlice(memory, peekStack(stack, 2+off), peekStack(stack, 3+off))),
+			gasIn:   gas,
+			gasCost: cost,
+			outOff:  new(big.Int).Set(peekStack(stack, 4+off)),
+			outLen:  new(big.Int).Set(peekStack(stack, 5+off)),
+		}
+		if off == 1 {
+			call.Value = hexBig(peekStack(stack, 2))
+		}
+		t.callstack = append(t.callstack, call)
+		t.descended = true
+		return nil
+	}
+	// If we've just descended into an inner call, retrieve its true allowance. It
+	// needs to be extracted from within the call as there may be funky gas dynamics
+	// with regard to requested and actually given gas (2300 stipend, 63/64 rule).
+	if t.descended {
+		if depth >= len(t.callstack) {
+			call := t.callstack[len(t.callstack)-1]
+			call.gas, call.gasKnown = gas, true
+		}
+		t.descended = false
+	}
+	// If an existing call is returning, pop off the call stack
+	if op == vm.REVERT {
+		t.callstack[len(t.callstack)-1].Error = "execution reverted"
+		return nil
+	}
+	if depth == len(t.callstack)-1 {
+		// Pop off the last call and get the execution results
+		call := t.callstack[len(t.callstack)-1]
+		t.callstack = t.callstack[:len(t.callstack)-1]
+
+		if call.Type == vm.CREATE.String() {
+			// If the call was a CREATE, retrieve the contract address and output code
+			call.GasUsed = hexInt(int64(call.gasIn) - int64(call.gasCost) - int64(gas))
+
+			if ret := peekStack(stack, 0); ret.Sign() != 0 {
+				addr := common.BigToAddress(ret)
+				call.To = hexutil.Encode(addr.Bytes())
+				call.Output = hexutil.Encode(env.StateDB.GetCode(addr))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		} else if call.gasKnown {
+			// If the call was a contract call, retrieve the gas usage and output
+			call.GasUsed = hexInt(int64(call.gasIn) - int64(call.gasCost) + int64(call.gas) - int64(gas))
+
+			if ret := peekStack(stack, 0); ret.Sign() != 0 {
+				call.Output = hexutil.Encode(memorySlice(memory, call.outOff, call.outLen))
+			} else if call.Error == "" {
+				call.Error = "internal failure"
+			}
+		}
+		if call.gasKnown {
+			call.Gas = hexInt(int64(call.gas))
+		}
+		// Inject the call into the previous one
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *callTracer) CaptureFault(env *vm.VVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if !t.halted() {
+		t.fault(err)
+	}
+	return nil
+}
+
+// fault handles the failure of the innermost call.
+func (t *callTracer) fault(err error) {
+	// If the topmost call already reverted, don't handle the additional fault again
+	if t.callstack[len(t.callstack)-1].Error != "" {
+		return
+	}
+	// Pop off the just failed call
+	call := t.callstack[len(t.callstack)-1]
+	t.callstack = t.callstack[:len(t.callstack)-1]
+
+	call.Error = err.Error()
+
+	// Consume all available gas and clean any leftovers
+	if call.gasKnown {
+		call.Gas = hexInt(int64(call.gas))
+		call.GasUsed = call.Gas
+	}
+	// Flatten the failed call into its parent
+	if len(t.callstack) > 0 {
+		parent := t.callstack[len(t.callstack)-1]
+		parent.Calls = append(parent.Calls, call)
+		return
+	}
+	// Last call failed too, leave it in the stack
+	t.callstack = append(t.callstack, call)
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *callTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	t.output, t.gasUsed, t.time, t.failure = output, gasUsed, d, err
+	return nil
+}
+
+// GetResult returns the call tree rooted in the outer transaction, or any
+// error that interrupted the tracing.
+func (t *callTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	root := t.callstack[0]
+	result := &callFrame{
+		Type:    t.typ,
+		From:    hexutil.Encode(t.from.Bytes()),
+		To:      hexutil.Encode(t.to.Bytes()),
+		Value:   hexBig(t.value),
+		Gas:     hexInt(int64(t.gas)),
+		GasUsed: hexInt(int64(t.gasUsed)),
+		Input:   hexutil.Encode(t.input),
+		Output:  hexutil.Encode(t.output),
+		Error:   root.Error,
+		Time:    t.time.String(),
+		Calls:   root.Calls,
+	}
+	if result.Error == "" && t.failure != nil {
+		result.Error = t.failure.Error()
+	}
+	if result.Error != "" {
+		result.Output = ""
+	}
+	return marshalJSON(result)
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/core/vm"
+)
+
+// interruptible implements the asynchronous termination of the native tracers,
+// mirroring the semantics of the JavaScript one: once stopped, all subsequent
+// steps are ignored and the reason is returned instead of the result.
+type interruptible struct {
+	interrupt uint32 // Atomic flag to signal execution interruption
+	reason    error  // Textual reason for the interruption
+	err       error  // Error, if one has occurred
+}
+
+// Stop terminates execution of the tracer at the first opportune moment.
+func (t *interruptible) Stop(err error) {
+	t.reason = err
+	atomic.StoreUint32(&t.interrupt, 1)
+}
+
+// halted reports whether tracing should be skipped, either because an error
+// occurred previously or because the tracer was just interrupted.
+func (t *interruptible) halted() bool {
+	if t.err == nil && atomic.LoadUint32(&t.interrupt) > 0 {
+		t.err = t.reason
+	}
+	return t.err != nil
+}
+
+// hexBig formats a big integer the way the JavaScript tracers do, i.e. as its
+// 0x prefixed hexadecimal representation without any zero padding.
+func hexBig(n *big.Int) string {
+	return "0x" + n.Text(16)
+}
+
+// hexInt formats a signed integer the way the JavaScript tracers do.
+func hexInt(n int64) string {
+	return hexBig(big.NewInt(n))
+}
+
+// peekStack returns the n-th element from the top of the stack, or zero if the
+// stack is not deep enough.
+func peekStack(stack *vm.Stack, n int) *big.Int {
+	data := stack.Data()
+	if len(data) <= n {
+		return new(big.Int)
+	}
+	return data[len(data)-n-1]
+}
+
+// memorySlice copies out a chunk of memory, returning nil if the requested
+// range is not fully allocated.
+func memorySlice(memory *vm.Memory, offset, size *big.Int) []byte {
+	end := new(big.Int).Add(offset, size)
+	if end.BitLen() > 63 || int64(memory.Len()) < end.Int64() {
+		return nil
+	}
+	return memory.Get(offset.Int64(), size.Int64())
+}
+
+// isPrecompiled reports whether the address is a pre-compiled contract.
+func isPrecompiled(addr common.Address) bool {
+	_, ok := vm.PrecompiledContractsByzantium[addr]
+	return ok
+}
+
+// marshalJSON encodes a value without escaping HTML characters, matching the
+// output of the JavaScript engine's encoder.
+func marshalJSON(v interface{}) (json.RawMessage, error) {
+	buf := new(bytes.Buffer)
+
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// orderedJSON is a JSON object which retains the insertion order of its keys, as
+// objects assembled by the JavaScript tracers do.
+type orderedJSON struct {
+	keys   []string
+	values []interface{}
+}
+
+// add appends a new field to the object.
+func (obj *orderedJSON) add(key string, value interface{}) {
+	obj.keys = append(obj.keys, key)
+	obj.values = append(obj.values, value)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (obj *orderedJSON) MarshalJSON() ([]byte, error) {
+	buf := new(bytes.Buffer)
+	buf.WriteByte('{')
+	for i, key := range obj.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		blob, err := marshalJSON(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(blob)
+		buf.WriteByte(':')
+
+		if blob, err = marshalJSON(obj.values[i]); err != nil {
+			return nil, err
+		}
+		buf.Write(blob)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
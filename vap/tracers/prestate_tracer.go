@@ -0,0 +1,188 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/vm"
+	"github.com/vaporyco/go-vapory/crypto"
+)
+
+// errNoPrestate is returned if the traced message did not execute any code, so
+// the state it accessed could not be gathered.
+var errNoPrestate = errors.New("no code executed, prestate unavailable")
+
+// prestateAccount is the state of an account prior to the traced transaction.
+type prestateAccount struct {
+	balance *big.Int
+	nonce   uint64
+	code    []byte
+	storage map[common.Hash]common.Hash
+	slots   []common.Hash // Storage slots in the order they were accessed
+}
+
+// MarshalJSON implements json.Marshaler, retaining the slot access order the
+// JavaScript tracer serializes storage in.
+func (acc *prestateAccount) MarshalJSON() ([]byte, error) {
+	storage := new(orderedJSON)
+	for _, slot := range acc.slots {
+		storage.add(hexutil.Encode(slot.Bytes()), hexutil.Encode(acc.storage[slot].Bytes()))
+	}
+	account := new(orderedJSON)
+	account.add("balance", hexBig(acc.balance))
+	account.add("nonce", acc.nonce)
+	account.add("code", hexutil.Encode(acc.code))
+	account.add("storage", storage)
+
+	return account.MarshalJSON()
+}
+
+// prestateTracer is a native implementation of the JavaScript prestateTracer,
+// which gathers the state of all accounts accessed by a transaction prior to
+// its execution.
+type prestateTracer struct {
+	interruptible
+
+	db       vm.StateDB
+	accounts map[common.Address]*prestateAccount
+	order    []common.Address // Accounts in the order they were accessed
+
+	create bool
+	from   common.Address
+	to     common.Address
+	value  *big.Int
+}
+
+// newPrestateTracer creates a native prestate tracer.
+func newPrestateTracer() *prestateTracer {
+	return &prestateTracer{accounts: make(map[common.Address]*prestateAccount)}
+}
+
+// lookupAccount injects the specified account into the prestate.
+func (t *prestateTracer) lookupAccount(addr common.Address) {
+	if _, ok := t.accounts[addr]; ok {
+		return
+	}
+	t.accounts[addr] = &prestateAccount{
+		balance: new(big.Int).Set(t.db.GetBalance(addr)),
+		nonce:   t.db.GetNonce(addr),
+		code:    common.CopyBytes(t.db.GetCode(addr)),
+		storage: make(map[common.Hash]common.Hash),
+	}
+	t.order = append(t.order, addr)
+}
+
+// lookupStorage injects the specified storage entry of the given account into
+// the prestate. Empty slots are not recorded.
+func (t *prestateTracer) lookupStorage(addr common.Address, key common.Hash) {
+	t.lookupAccount(addr)
+
+	acc := t.accounts[addr]
+	if _, ok := acc.storage[key]; ok {
+		return
+	}
+	if val := t.db.GetState(addr, key); val != (common.Hash{}) {
+		acc.storage[key] = val
+		acc.slots = append(acc.slots, key)
+	}
+}
+
+// CaptureStart implements the Tracer interface to initialize the tracing operation.
+func (t *prestateTracer) CaptureStart(from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) error {
+	t.create, t.from, t.to, t.value = create, from, to, value
+	return nil
+}
+
+// CaptureState implements the Tracer interface to trace a single step of VM execution.
+func (t *prestateTracer) CaptureState(env *vm.VVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	if t.halted() {
+		return nil
+	}
+	// Add the current account if we just started tracing. Its balance will be
+	// off by the value sent along with the message, which is fixed up at the end.
+	if t.db == nil {
+		t.db = env.StateDB
+		t.lookupAccount(contract.Address())
+	}
+	// Whenever new state is accessed, add it to the prestate
+	switch op {
+	case vm.EXTCODECOPY, vm.EXTCODESIZE, vm.BALANCE:
+		t.lookupAccount(common.BigToAddress(peekStack(stack, 0)))
+
+	case vm.CREATE:
+		from := contract.Address()
+		t.lookupAccount(crypto.CreateAddress(from, t.db.GetNonce(from)))
+
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL:
+		t.lookupAccount(common.BigToAddress(peekStack(stack, 1)))
+
+	case vm.SSTORE, vm.SLOAD:
+		t.lookupStorage(contract.Address(), common.BigToHash(peekStack(stack, 0)))
+	}
+	return nil
+}
+
+// CaptureFault implements the Tracer interface to trace an execution fault
+// while running an opcode.
+func (t *prestateTracer) CaptureFault(env *vm.VVM, pc uint64, op vm.OpCode, gas, cost uint64, memory *vm.Memory, stack *vm.Stack, contract *vm.Contract, depth int, err error) error {
+	return nil
+}
+
+// CaptureEnd is called after the call finishes to finalize the tracing.
+func (t *prestateTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) error {
+	return nil
+}
+
+// GetResult returns the assembled allocations of all accessed accounts, or any
+// error that interrupted the tracing.
+func (t *prestateTracer) GetResult() (json.RawMessage, error) {
+	if t.err != nil {
+		return nil, t.err
+	}
+	if t.db == nil {
+		return nil, errNoPrestate
+	}
+	// At this point, we need to deduct the value from the outer transaction
+	// and move it back to the origin
+	t.lookupAccount(t.from)
+	t.lookupAccount(t.to)
+
+	fromBal := new(big.Int).Add(t.accounts[t.from].balance, t.value)
+	toBal := new(big.Int).Sub(t.accounts[t.to].balance, t.value)
+
+	t.accounts[t.to].balance = toBal
+	t.accounts[t.from].balance = fromBal
+
+	// Decrement the caller's nonce, and remove empty create targets. Any existing
+	// state would have caused the transaction to be rejected as invalid.
+	t.accounts[t.from].nonce--
+
+	prestate := new(orderedJSON)
+	for _, addr := range t.order {
+		if t.create && addr == t.to {
+			continue
+		}
+		prestate.add(hexutil.Encode(addr.Bytes()), t.accounts[addr])
+	}
+	return prestate.MarshalJSON()
+}
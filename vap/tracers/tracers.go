@@ -14,16 +14,32 @@
 // You should have received a copy of the GNU Lesser General Public License
 // along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
 
-// Package tracers is a collection of JavaScript transaction tracers.
+// Package tracers is a collection of JavaScript and native transaction tracers.
 package tracers
 
 import (
+	"encoding/json"
 	"strings"
 	"unicode"
 
+	"github.com/vaporyco/go-vapory/core/vm"
 	"github.com/vaporyco/go-vapory/vap/tracers/internal/tracers"
 )
 
+// ResultTracer is a VVM tracer which aggregates its findings into a JSON result
+// and which can be asynchronously interrupted. Both the JavaScript and the
+// native tracers implement it.
+type ResultTracer interface {
+	vm.Tracer
+
+	// GetResult returns the JSON result of the tracing, or any error that was
+	// encountered during it.
+	GetResult() (json.RawMessage, error)
+
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
 // all contains all the built in JavaScript tracers by name.
 var all = make(map[string]string)
 
@@ -44,6 +60,24 @@ func init() {
 	}
 }
 
+// native contains the built in Go tracers, keyed by the name of the JavaScript
+// tracer whose output they reproduce.
+var native = map[string]func() ResultTracer{
+	"callTracer":     func() ResultTracer { return newCallTracer() },
+	"prestateTracer": func() ResultTracer { return newPrestateTracer() },
+	"4byteTracer":    func() ResultTracer { return newFourByteTracer() },
+}
+
+// NewTracer creates a tracer from either a tracer name or a JavaScript code
+// snippet. Built in tracers that have a native Go implementation are served by
+// that, everything else is run by the JavaScript engine.
+func NewTracer(code string) (ResultTracer, error) {
+	if constructor, ok := native[code]; ok {
+		return constructor(), nil
+	}
+	return New(code)
+}
+
 // tracer retrieves a specific JavaScript tracer by name.
 func tracer(name string) (string, bool) {
 	if tracer, ok := all[name]; ok {
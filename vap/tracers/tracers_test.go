@@ -17,11 +17,13 @@
 package tracers
 
 import (
+	"bytes"
 	"encoding/json"
 	"io/ioutil"
 	"math/big"
 	"path/filepath"
 	"reflect"
+	"regexp"
 	"strings"
 	"testing"
 
@@ -116,78 +118,126 @@ type callTracerTest struct {
 	Result  *callTrace    `json:"result"`
 }
 
-// Iterates over all the input-output datasets in the tracer test harness and
-// runs the JavaScript tracers against them.
-func TestCallTracer(t *testing.T) {
+// runTracer executes the transaction of a tracer test against its prestate with
+// the given tracer attached, returning the raw trace result.
+func runTracer(t *testing.T, test *callTracerTest, tracer ResultTracer) json.RawMessage {
+	// Configure a blockchain with the given prestate
+	tx := new(types.Transaction)
+	if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
+		t.Fatalf("failed to parse testcase input: %v", err)
+	}
+	signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
+	origin, _ := signer.Sender(tx)
+
+	context := vm.Context{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		Origin:      origin,
+		Coinbase:    test.Context.Miner,
+		BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
+		Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+		Difficulty:  (*big.Int)(test.Context.Difficulty),
+		GasLimit:    uint64(test.Context.GasLimit),
+		GasPrice:    tx.GasPrice(),
+	}
+	db, _ := vapdb.NewMemDatabase()
+	statedb := tests.MakePreState(db, test.Genesis.Alloc)
+
+	// Create the VVM environment with the tracer attached and run it
+	vvm := vm.NewVVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
+
+	msg, err := tx.AsMessage(signer)
+	if err != nil {
+		t.Fatalf("failed to prepare transaction for tracing: %v", err)
+	}
+	st := core.NewStateTransition(vvm, msg, new(core.GasPool).AddGas(tx.Gas()))
+	if _, _, _, err = st.TransitionDb(); err != nil {
+		t.Fatalf("failed to execute transaction: %v", err)
+	}
+	// Retrieve the trace result
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatalf("failed to retrieve trace result: %v", err)
+	}
+	return res
+}
+
+// loadTracerTests reads all the call tracer test cases from the testdata folder.
+func loadTracerTests(t *testing.T) map[string]*callTracerTest {
 	files, err := ioutil.ReadDir("testdata")
 	if err != nil {
 		t.Fatalf("failed to retrieve tracer test suite: %v", err)
 	}
+	tests := make(map[string]*callTracerTest)
 	for _, file := range files {
 		if !strings.HasPrefix(file.Name(), "call_tracer_") {
 			continue
 		}
-		file := file // capture range variable
-		t.Run(camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json")), func(t *testing.T) {
-			t.Parallel()
+		// Call tracer test found, read if from disk
+		blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
+		if err != nil {
+			t.Fatalf("failed to read testcase: %v", err)
+		}
+		test := new(callTracerTest)
+		if err := json.Unmarshal(blob, test); err != nil {
+			t.Fatalf("failed to parse testcase: %v", err)
+		}
+		tests[camel(strings.TrimSuffix(strings.TrimPrefix(file.Name(), "call_tracer_"), ".json"))] = test
+	}
+	return tests
+}
 
-			// Call tracer test found, read if from disk
-			blob, err := ioutil.ReadFile(filepath.Join("testdata", file.Name()))
-			if err != nil {
-				t.Fatalf("failed to read testcase: %v", err)
-			}
-			test := new(callTracerTest)
-			if err := json.Unmarshal(blob, test); err != nil {
-				t.Fatalf("failed to parse testcase: %v", err)
-			}
-			// Configure a blockchain with the given prestate
-			tx := new(types.Transaction)
-			if err := rlp.DecodeBytes(common.FromHex(test.Input), tx); err != nil {
-				t.Fatalf("failed to parse testcase input: %v", err)
-			}
-			signer := types.MakeSigner(test.Genesis.Config, new(big.Int).SetUint64(uint64(test.Context.Number)))
-			origin, _ := signer.Sender(tx)
-
-			context := vm.Context{
-				CanTransfer: core.CanTransfer,
-				Transfer:    core.Transfer,
-				Origin:      origin,
-				Coinbase:    test.Context.Miner,
-				BlockNumber: new(big.Int).SetUint64(uint64(test.Context.Number)),
-				Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
-				Difficulty:  (*big.Int)(test.Context.Difficulty),
-				GasLimit:    uint64(test.Context.GasLimit),
-				GasPrice:    tx.GasPrice(),
-			}
-			db, _ := vapdb.NewMemDatabase()
-			statedb := tests.MakePreState(db, test.Genesis.Alloc)
+// Iterates over all the input-output datasets in the tracer test harness and
+// runs the JavaScript and native call tracers against them.
+func TestCallTracer(t *testing.T) {
+	for name, test := range loadTracerTests(t) {
+		test := test // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-			// Create the tracer, the VVM environment and run it
 			tracer, err := New("callTracer")
 			if err != nil {
 				t.Fatalf("failed to create call tracer: %v", err)
 			}
-			vvm := vm.NewVVM(context, statedb, test.Genesis.Config, vm.Config{Debug: true, Tracer: tracer})
-
-			msg, err := tx.AsMessage(signer)
-			if err != nil {
-				t.Fatalf("failed to prepare transaction for tracing: %v", err)
+			for _, tracer := range []ResultTracer{tracer, newCallTracer()} {
+				// Run the tracer and compare against the etalon
+				res := runTracer(t, test, tracer)
+
+				ret := new(callTrace)
+				if err := json.Unmarshal(res, ret); err != nil {
+					t.Fatalf("failed to unmarshal trace result: %v", err)
+				}
+				if !reflect.DeepEqual(ret, test.Result) {
+					t.Fatalf("%T trace mismatch: have %+v, want %+v", tracer, ret, test.Result)
+				}
 			}
-			st := core.NewStateTransition(vvm, msg, new(core.GasPool).AddGas(tx.Gas()))
-			if _, _, _, err = st.TransitionDb(); err != nil {
-				t.Fatalf("failed to execute transaction: %v", err)
-			}
-			// Retrieve the trace result and compare against the etalon
-			res, err := tracer.GetResult()
-			if err != nil {
-				t.Fatalf("failed to retrieve trace result: %v", err)
-			}
-			ret := new(callTrace)
-			if err := json.Unmarshal(res, ret); err != nil {
-				t.Fatalf("failed to unmarshal trace result: %v", err)
-			}
-			if !reflect.DeepEqual(ret, test.Result) {
-				t.Fatalf("trace mismatch: have %+v, want %+v", ret, test.Result)
+		})
+	}
+}
+
+// timeField matches the execution time reported by the call tracers, which
+// naturally differs between runs.
+var timeField = regexp.MustCompile(`"time":"[^"]*",?`)
+
+// Tests that the native tracers produce byte for byte the same output as the
+// JavaScript tracers they replace.
+func TestNativeTracers(t *testing.T) {
+	for name, test := range loadTracerTests(t) {
+		test := test // capture range variable
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			for tracer, constructor := range native {
+				js, err := New(tracer)
+				if err != nil {
+					t.Fatalf("failed to create JavaScript %s: %v", tracer, err)
+				}
+				want := timeField.ReplaceAll(runTracer(t, test, js), nil)
+				have := timeField.ReplaceAll(runTracer(t, test, constructor()), nil)
+
+				if !bytes.Equal(have, want) {
+					t.Errorf("%s output mismatch:\nhave %s\nwant %s", tracer, have, want)
+				}
 			}
 		})
 	}
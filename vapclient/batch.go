@@ -0,0 +1,210 @@
+// Copyright 2018 The go-ethereum Authors
+// This file is part of the go-vapory library.
+//
+// The go-vapory library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-vapory library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-vapory library. If not, see <http://www.gnu.org/licenses/>.
+
+package vapclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/vaporyco/go-vapory"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/common/hexutil"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/rpc"
+)
+
+// batchCall sends all the requests to the server in a single round trip,
+// returning the first error encountered by any of them.
+func (ec *Client) batchCall(ctx context.Context, reqs []rpc.BatchElem) error {
+	if len(reqs) == 0 {
+		return nil
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return req.Error
+		}
+	}
+	return nil
+}
+
+// HeadersByNumber returns the canonical block headers with the given numbers,
+// retrieved in a single batch request. A nil number denotes the latest known
+// header. Headers unknown to the node are returned as nil.
+func (ec *Client) HeadersByNumber(ctx context.Context, numbers []*big.Int) ([]*types.Header, error) {
+	heads := make([]*types.Header, len(numbers))
+	reqs := make([]rpc.BatchElem, len(numbers))
+	for i, number := range numbers {
+		reqs[i] = rpc.BatchElem{
+			Method: "vap_getBlockByNumber",
+			Args:   []interface{}{toBlockNumArg(number), false},
+			Result: &heads[i],
+		}
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	return heads, nil
+}
+
+// BlocksByNumber returns the full canonical blocks with the given numbers. A nil
+// number denotes the latest known block. Blocks unknown to the node are returned
+// as nil.
+//
+// Note that loading full blocks requires two batch requests, the second one
+// retrieving the uncle headers of all blocks at once.
+func (ec *Client) BlocksByNumber(ctx context.Context, numbers []*big.Int) ([]*types.Block, error) {
+	raws := make([]json.RawMessage, len(numbers))
+	reqs := make([]rpc.BatchElem, len(numbers))
+	for i, number := range numbers {
+		reqs[i] = rpc.BatchElem{
+			Method: "vap_getBlockByNumber",
+			Args:   []interface{}{toBlockNumArg(number), true},
+			Result: &raws[i],
+		}
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	// Decode all the known blocks and load their uncles in one go
+	var (
+		heads  = make([]*types.Header, len(numbers))
+		bodies = make([]*rpcBlock, 0, len(numbers))
+		index  = make([]int, 0, len(numbers))
+	)
+	for i, raw := range raws {
+		if len(raw) == 0 || string(raw) == "null" {
+			continue
+		}
+		head, body, err := decodeBlock(raw)
+		if err != nil {
+			return nil, err
+		}
+		heads[i] = head
+		bodies = append(bodies, body)
+		index = append(index, i)
+	}
+	uncles := make([][]*types.Header, len(bodies))
+	if err := ec.getUncles(ctx, bodies, uncles); err != nil {
+		return nil, err
+	}
+	blocks := make([]*types.Block, len(numbers))
+	for i, body := range bodies {
+		blocks[index[i]] = assembleBlock(heads[index[i]], body, uncles[i])
+	}
+	return blocks, nil
+}
+
+// TransactionReceipts returns the receipts of the given transactions, retrieved
+// in a single batch request. Receipts unknown to the node are returned as nil.
+func (ec *Client) TransactionReceipts(ctx context.Context, txHashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(txHashes))
+	reqs := make([]rpc.BatchElem, len(txHashes))
+	for i, hash := range txHashes {
+		reqs[i] = rpc.BatchElem{
+			Method: "vap_getTransactionReceipt",
+			Args:   []interface{}{hash},
+			Result: &receipts[i],
+		}
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	return receipts, nil
+}
+
+// BalancesAt returns the wei balances of the given accounts, retrieved in a single
+// batch request. The block number can be nil, in which case the balances are taken
+// from the latest known block.
+func (ec *Client) BalancesAt(ctx context.Context, accounts []common.Address, blockNumber *big.Int) ([]*big.Int, error) {
+	results := make([]hexutil.Big, len(accounts))
+	reqs := make([]rpc.BatchElem, len(accounts))
+	for i, account := range accounts {
+		reqs[i] = rpc.BatchElem{
+			Method: "vap_getBalance",
+			Args:   []interface{}{account, toBlockNumArg(blockNumber)},
+			Result: &results[i],
+		}
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(accounts))
+	for i := range results {
+		balances[i] = (*big.Int)(&results[i])
+	}
+	return balances, nil
+}
+
+// rpcReceipt is a transaction receipt along with the hash of its inclusion block.
+type rpcReceipt struct {
+	receipt *types.Receipt
+	receiptExtraInfo
+}
+
+type receiptExtraInfo struct {
+	BlockHash common.Hash `json:"blockHash"`
+}
+
+func (r *rpcReceipt) UnmarshalJSON(msg []byte) error {
+	if err := json.Unmarshal(msg, &r.receipt); err != nil {
+		return err
+	}
+	return json.Unmarshal(msg, &r.receiptExtraInfo)
+}
+
+// BlockReceipts returns the receipts of all the transactions included in the block
+// with the given hash. The transaction hashes are retrieved first, after which all
+// the receipts are fetched in a single batch request.
+func (ec *Client) BlockReceipts(ctx context.Context, hash common.Hash) (types.Receipts, error) {
+	var block *struct {
+		Transactions []common.Hash `json:"transactions"`
+	}
+	if err := ec.c.CallContext(ctx, &block, "vap_getBlockByHash", hash, false); err != nil {
+		return nil, err
+	} else if block == nil {
+		return nil, vapory.NotFound
+	}
+	results := make([]*rpcReceipt, len(block.Transactions))
+	reqs := make([]rpc.BatchElem, len(block.Transactions))
+	for i, txHash := range block.Transactions {
+		reqs[i] = rpc.BatchElem{
+			Method: "vap_getTransactionReceipt",
+			Args:   []interface{}{txHash},
+			Result: &results[i],
+		}
+	}
+	if err := ec.batchCall(ctx, reqs); err != nil {
+		return nil, err
+	}
+	// Ensure the receipts weren't taken from a different block after a reorg
+	receipts := make(types.Receipts, len(results))
+	for i, result := range results {
+		if result == nil || result.receipt == nil {
+			return nil, fmt.Errorf("missing receipt for transaction %x", block.Transactions[i])
+		}
+		if result.BlockHash != hash {
+			return nil, fmt.Errorf("receipt for transaction %x included in block %x, want %x", block.Transactions[i], result.BlockHash, hash)
+		}
+		receipts[i] = result.receipt
+	}
+	return receipts, nil
+}
@@ -23,11 +23,14 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"sort"
+	"time"
 
 	"github.com/vaporyco/go-vapory"
 	"github.com/vaporyco/go-vapory/common"
 	"github.com/vaporyco/go-vapory/common/hexutil"
 	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/event"
 	"github.com/vaporyco/go-vapory/rlp"
 	"github.com/vaporyco/go-vapory/rpc"
 )
@@ -84,59 +87,92 @@ func (ec *Client) getBlock(ctx context.Context, method string, args ...interface
 	} else if len(raw) == 0 {
 		return nil, vapory.NotFound
 	}
-	// Decode header and transactions.
+	head, body, err := decodeBlock(raw)
+	if err != nil {
+		return nil, err
+	}
+	// Load uncles because they are not included in the block response.
+	uncles := make([][]*types.Header, 1)
+	if err := ec.getUncles(ctx, []*rpcBlock{body}, uncles); err != nil {
+		return nil, err
+	}
+	return assembleBlock(head, body, uncles[0]), nil
+}
+
+// decodeBlock decodes the header and body of a block response, verifying the
+// transaction and uncle lists against the header.
+func decodeBlock(raw json.RawMessage) (*types.Header, *rpcBlock, error) {
 	var head *types.Header
 	var body rpcBlock
 	if err := json.Unmarshal(raw, &head); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := json.Unmarshal(raw, &body); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Quick-verify transaction and uncle lists. This mostly helps with debugging the server.
 	if head.UncleHash == types.EmptyUncleHash && len(body.UncleHashes) > 0 {
-		return nil, fmt.Errorf("server returned non-empty uncle list but block header indicates no uncles")
+		return nil, nil, fmt.Errorf("server returned non-empty uncle list but block header indicates no uncles")
 	}
 	if head.UncleHash != types.EmptyUncleHash && len(body.UncleHashes) == 0 {
-		return nil, fmt.Errorf("server returned empty uncle list but block header indicates uncles")
+		return nil, nil, fmt.Errorf("server returned empty uncle list but block header indicates uncles")
 	}
 	if head.TxHash == types.EmptyRootHash && len(body.Transactions) > 0 {
-		return nil, fmt.Errorf("server returned non-empty transaction list but block header indicates no transactions")
+		return nil, nil, fmt.Errorf("server returned non-empty transaction list but block header indicates no transactions")
 	}
 	if head.TxHash != types.EmptyRootHash && len(body.Transactions) == 0 {
-		return nil, fmt.Errorf("server returned empty transaction list but block header indicates transactions")
+		return nil, nil, fmt.Errorf("server returned empty transaction list but block header indicates transactions")
 	}
-	// Load uncles because they are not included in the block response.
-	var uncles []*types.Header
-	if len(body.UncleHashes) > 0 {
-		uncles = make([]*types.Header, len(body.UncleHashes))
-		reqs := make([]rpc.BatchElem, len(body.UncleHashes))
-		for i := range reqs {
-			reqs[i] = rpc.BatchElem{
+	return head, &body, nil
+}
+
+// getUncles loads the uncle headers of all the given blocks in a single batch
+// request, storing them in the matching slot of uncles.
+func (ec *Client) getUncles(ctx context.Context, bodies []*rpcBlock, uncles [][]*types.Header) error {
+	var reqs []rpc.BatchElem
+	for i, body := range bodies {
+		if len(body.UncleHashes) == 0 {
+			continue
+		}
+		uncles[i] = make([]*types.Header, len(body.UncleHashes))
+		for j := range body.UncleHashes {
+			reqs = append(reqs, rpc.BatchElem{
 				Method: "vap_getUncleByBlockHashAndIndex",
-				Args:   []interface{}{body.Hash, hexutil.EncodeUint64(uint64(i))},
-				Result: &uncles[i],
-			}
+				Args:   []interface{}{body.Hash, hexutil.EncodeUint64(uint64(j))},
+				Result: &uncles[i][j],
+			})
 		}
-		if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
-			return nil, err
+	}
+	if len(reqs) == 0 {
+		return nil
+	}
+	if err := ec.c.BatchCallContext(ctx, reqs); err != nil {
+		return err
+	}
+	for _, req := range reqs {
+		if req.Error != nil {
+			return req.Error
 		}
-		for i := range reqs {
-			if reqs[i].Error != nil {
-				return nil, reqs[i].Error
-			}
-			if uncles[i] == nil {
-				return nil, fmt.Errorf("got null header for uncle %d of block %x", i, body.Hash[:])
+	}
+	for i, body := range bodies {
+		for j, uncle := range uncles[i] {
+			if uncle == nil {
+				return fmt.Errorf("got null header for uncle %d of block %x", j, body.Hash[:])
 			}
 		}
 	}
-	// Fill the sender cache of transactions in the block.
+	return nil
+}
+
+// assembleBlock creates a block from its decoded parts, filling the sender cache
+// of the contained transactions.
+func assembleBlock(head *types.Header, body *rpcBlock, uncles []*types.Header) *types.Block {
 	txs := make([]*types.Transaction, len(body.Transactions))
 	for i, tx := range body.Transactions {
 		setSenderFromServer(tx.tx, tx.From, body.Hash)
 		txs[i] = tx.tx
 	}
-	return types.NewBlockWithHeader(head).WithBody(txs, uncles), nil
+	return types.NewBlockWithHeader(head).WithBody(txs, uncles)
 }
 
 // HeaderByHash returns the block header with the given hash.
@@ -266,6 +302,8 @@ type rpcProgress struct {
 	HighestBlock  hexutil.Uint64
 	PulledStates  hexutil.Uint64
 	KnownStates   hexutil.Uint64
+	Percentage    float64
+	ETA           hexutil.Uint64
 }
 
 // SyncProgress retrieves the current progress of the sync algorithm. If there's
@@ -290,6 +328,8 @@ func (ec *Client) SyncProgress(ctx context.Context) (*vapory.SyncProgress, error
 		HighestBlock:  uint64(progress.HighestBlock),
 		PulledStates:  uint64(progress.PulledStates),
 		KnownStates:   uint64(progress.KnownStates),
+		Percentage:    progress.Percentage,
+		ETA:           time.Duration(progress.ETA) * time.Second,
 	}, nil
 }
 
@@ -299,6 +339,59 @@ func (ec *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header)
 	return ec.c.VapSubscribe(ctx, ch, "newHeads", map[string]struct{}{})
 }
 
+// SubscribeSyncProgress subscribes to notifications about changes in the sync
+// status of the node. A nil progress is delivered when synchronisation stops.
+func (ec *Client) SubscribeSyncProgress(ctx context.Context, ch chan<- *vapory.SyncProgress) (vapory.Subscription, error) {
+	raw := make(chan json.RawMessage)
+	sub, err := ec.c.VapSubscribe(ctx, raw, "syncing")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case msg := <-raw:
+				progress, err := decodeSyncNotification(msg)
+				if err != nil {
+					return err
+				}
+				select {
+				case ch <- progress:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// decodeSyncNotification decodes a notification of the syncing subscription,
+// which is either false or the status of a running synchronisation.
+func decodeSyncNotification(msg json.RawMessage) (*vapory.SyncProgress, error) {
+	var syncing bool
+	if err := json.Unmarshal(msg, &syncing); err == nil {
+		return nil, nil // Not syncing (always false)
+	}
+	var result struct {
+		Syncing bool                `json:"syncing"`
+		Status  vapory.SyncProgress `json:"status"`
+	}
+	if err := json.Unmarshal(msg, &result); err != nil {
+		return nil, err
+	}
+	if !result.Syncing {
+		return nil, nil
+	}
+	return &result.Status, nil
+}
+
 // State Access
 
 // NetworkID returns the network ID (also known as the chain ID) for this chain.
@@ -411,7 +504,51 @@ func (ec *Client) PendingTransactionCount(ctx context.Context) (uint, error) {
 	return uint(num), err
 }
 
-// TODO: SubscribePendingTransactions (needs server side)
+// PoolContentFrom returns the pending and queued transactions of the given account
+// held by the transaction pool, sorted by nonce.
+func (ec *Client) PoolContentFrom(ctx context.Context, account common.Address) (pending types.Transactions, queued types.Transactions, err error) {
+	var content map[string]map[string]*types.Transaction
+	if err := ec.c.CallContext(ctx, &content, "txpool_contentFrom", account); err != nil {
+		return nil, nil, err
+	}
+	for _, tx := range content["pending"] {
+		pending = append(pending, tx)
+	}
+	for _, tx := range content["queued"] {
+		queued = append(queued, tx)
+	}
+	sort.Sort(types.TxByNonce(pending))
+	sort.Sort(types.TxByNonce(queued))
+	return pending, queued, nil
+}
+
+type rpcNonceGaps struct {
+	Nonce hexutil.Uint64 `json:"nonce"`
+	Gaps  []struct {
+		From hexutil.Uint64 `json:"from"`
+		To   hexutil.Uint64 `json:"to"`
+	} `json:"gaps"`
+}
+
+// PoolNonceGaps returns the next nonce the transaction pool expects from the given
+// account, along with the ranges of nonces missing from its queued transactions.
+func (ec *Client) PoolNonceGaps(ctx context.Context, account common.Address) (uint64, []vapory.NonceGap, error) {
+	var result rpcNonceGaps
+	if err := ec.c.CallContext(ctx, &result, "txpool_nonceGaps", account); err != nil {
+		return 0, nil, err
+	}
+	var gaps []vapory.NonceGap
+	for _, gap := range result.Gaps {
+		gaps = append(gaps, vapory.NonceGap{From: uint64(gap.From), To: uint64(gap.To)})
+	}
+	return uint64(result.Nonce), gaps, nil
+}
+
+// SubscribePendingTransactions subscribes to notifications about the hashes of
+// transactions entering the pending pool of the node.
+func (ec *Client) SubscribePendingTransactions(ctx context.Context, ch chan<- common.Hash) (vapory.Subscription, error) {
+	return ec.c.VapSubscribe(ctx, ch, "newPendingTransactions")
+}
 
 // Contract Calling
 
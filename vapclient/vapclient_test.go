@@ -16,7 +16,24 @@
 
 package vapclient
 
-import "github.com/vaporyco/go-vapory"
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/vaporyco/go-vapory"
+	"github.com/vaporyco/go-vapory/common"
+	"github.com/vaporyco/go-vapory/consensus/vapash"
+	"github.com/vaporyco/go-vapory/core"
+	"github.com/vaporyco/go-vapory/core/types"
+	"github.com/vaporyco/go-vapory/crypto"
+	"github.com/vaporyco/go-vapory/node"
+	"github.com/vaporyco/go-vapory/params"
+	"github.com/vaporyco/go-vapory/vap"
+)
 
 // Verify that Client implements the vapory interfaces.
 var (
@@ -32,3 +49,198 @@ var (
 	// _ = vapory.PendingStateEventer(&Client{})
 	_ = vapory.PendingContractCaller(&Client{})
 )
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = big.NewInt(2e18)
+)
+
+// newTestBackend creates a networkless full node with a chain of two blocks,
+// each containing two value transfers, and returns a client attached to it.
+func newTestBackend(t *testing.T) (*node.Node, *Client, []*types.Block) {
+	stack, err := node.New(&node.Config{Name: "vapclient-tester", NoUSB: true})
+	if err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+	config := &vap.Config{
+		Genesis: &core.Genesis{
+			Config:   params.TestChainConfig,
+			GasLimit: 4712388,
+			Alloc:    core.GenesisAlloc{testAddr: {Balance: testBalance}},
+		},
+		Vapash: vapash.Config{PowMode: vapash.ModeFake},
+	}
+	if err := stack.Register(func(ctx *node.ServiceContext) (node.Service, error) { return vap.New(ctx, config) }); err != nil {
+		t.Fatalf("failed to register Vapory protocol: %v", err)
+	}
+	if err := stack.Start(); err != nil {
+		t.Fatalf("failed to start test stack: %v", err)
+	}
+	var vapServ *vap.Vapory
+	stack.Service(&vapServ)
+
+	chain := vapServ.BlockChain()
+	signer := types.HomesteadSigner{}
+	blocks, _ := core.GenerateChain(params.TestChainConfig, chain.Genesis(), vapash.NewFaker(), vapServ.ChainDb(), 2, func(i int, b *core.BlockGen) {
+		for j := 0; j < 2; j++ {
+			tx := types.NewTransaction(b.TxNonce(testAddr), common.Address{byte(i + 1)}, big.NewInt(1000), 21000, big.NewInt(1), nil)
+			tx, _ = types.SignTx(tx, signer, testKey)
+			b.AddTx(tx)
+		}
+	})
+	if _, err := chain.InsertChain(blocks); err != nil {
+		stack.Stop()
+		t.Fatalf("failed to insert test chain: %v", err)
+	}
+	// Wait for the transaction pool to reset to the new head, otherwise the next
+	// nonce would be considered a future transaction and queued
+	for deadline := time.Now().Add(5 * time.Second); vapServ.TxPool().State().GetNonce(testAddr) != 4; {
+		if time.Now().After(deadline) {
+			stack.Stop()
+			t.Fatalf("transaction pool failed to reach the chain head")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	rpcClient, err := stack.Attach()
+	if err != nil {
+		stack.Stop()
+		t.Fatalf("failed to attach to node: %v", err)
+	}
+	return stack, NewClient(rpcClient), blocks
+}
+
+// Tests that headers, blocks, receipts and balances can be retrieved in batches.
+func TestBatchRequests(t *testing.T) {
+	stack, client, blocks := newTestBackend(t)
+	defer stack.Stop()
+
+	ctx := context.Background()
+	numbers := []*big.Int{big.NewInt(2), big.NewInt(1), big.NewInt(3)}
+
+	heads, err := client.HeadersByNumber(ctx, numbers)
+	if err != nil {
+		t.Fatalf("failed to retrieve headers: %v", err)
+	}
+	if len(heads) != 3 || heads[0].Hash() != blocks[1].Hash() || heads[1].Hash() != blocks[0].Hash() || heads[2] != nil {
+		t.Errorf("header mismatch: have %v", heads)
+	}
+	full, err := client.BlocksByNumber(ctx, numbers)
+	if err != nil {
+		t.Fatalf("failed to retrieve blocks: %v", err)
+	}
+	if len(full) != 3 || full[2] != nil {
+		t.Fatalf("block count mismatch: have %v", full)
+	}
+	for i, block := range full[:2] {
+		want := blocks[1-i]
+		if block.Hash() != want.Hash() || block.Transactions().Len() != want.Transactions().Len() {
+			t.Errorf("block %d: mismatch: have %x with %d txs, want %x with %d txs", i, block.Hash(), block.Transactions().Len(), want.Hash(), want.Transactions().Len())
+		}
+	}
+	var hashes []common.Hash
+	for _, tx := range blocks[0].Transactions() {
+		hashes = append(hashes, tx.Hash())
+	}
+	receipts, err := client.TransactionReceipts(ctx, hashes)
+	if err != nil {
+		t.Fatalf("failed to retrieve receipts: %v", err)
+	}
+	for i, receipt := range receipts {
+		if receipt == nil || receipt.TxHash != hashes[i] {
+			t.Errorf("receipt %d: mismatch: have %v, want tx %x", i, receipt, hashes[i])
+		}
+	}
+	balances, err := client.BalancesAt(ctx, []common.Address{{0x01}, {0x02}, testAddr}, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("failed to retrieve balances: %v", err)
+	}
+	want := []*big.Int{big.NewInt(2000), new(big.Int), new(big.Int).Sub(testBalance, big.NewInt(2*(1000+21000)))}
+	for i := range want {
+		if balances[i].Cmp(want[i]) != 0 {
+			t.Errorf("balance %d: mismatch: have %v, want %v", i, balances[i], want[i])
+		}
+	}
+}
+
+// Tests that all the receipts of a block can be retrieved at once.
+func TestBlockReceipts(t *testing.T) {
+	stack, client, blocks := newTestBackend(t)
+	defer stack.Stop()
+
+	receipts, err := client.BlockReceipts(context.Background(), blocks[1].Hash())
+	if err != nil {
+		t.Fatalf("failed to retrieve block receipts: %v", err)
+	}
+	txs := blocks[1].Transactions()
+	if len(receipts) != len(txs) {
+		t.Fatalf("receipt count mismatch: have %d, want %d", len(receipts), len(txs))
+	}
+	for i, receipt := range receipts {
+		if receipt.TxHash != txs[i].Hash() {
+			t.Errorf("receipt %d: tx hash mismatch: have %x, want %x", i, receipt.TxHash, txs[i].Hash())
+		}
+		if receipt.CumulativeGasUsed != uint64(21000*(i+1)) {
+			t.Errorf("receipt %d: cumulative gas mismatch: have %d, want %d", i, receipt.CumulativeGasUsed, 21000*(i+1))
+		}
+	}
+	if _, err := client.BlockReceipts(context.Background(), common.Hash{0x01}); err != vapory.NotFound {
+		t.Errorf("unknown block: error mismatch: have %v, want %v", err, vapory.NotFound)
+	}
+}
+
+// Tests that the hashes of transactions entering the pool are delivered to
+// pending transaction subscribers.
+func TestSubscribePendingTransactions(t *testing.T) {
+	stack, client, _ := newTestBackend(t)
+	defer stack.Stop()
+
+	ctx := context.Background()
+	hashes := make(chan common.Hash, 1)
+	sub, err := client.SubscribePendingTransactions(ctx, hashes)
+	if err != nil {
+		t.Fatalf("failed to subscribe: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	tx := types.NewTransaction(4, common.Address{0x03}, big.NewInt(1000), 21000, big.NewInt(params.Shannon), nil)
+	tx, _ = types.SignTx(tx, types.HomesteadSigner{}, testKey)
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		t.Fatalf("failed to send transaction: %v", err)
+	}
+	select {
+	case hash := <-hashes:
+		if hash != tx.Hash() {
+			t.Errorf("hash mismatch: have %x, want %x", hash, tx.Hash())
+		}
+	case err := <-sub.Err():
+		t.Fatalf("subscription failed: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatalf("pending transaction not delivered")
+	}
+}
+
+// Tests that notifications of the syncing subscription are decoded correctly.
+func TestDecodeSyncNotification(t *testing.T) {
+	progress := vapory.SyncProgress{StartingBlock: 1, CurrentBlock: 2, HighestBlock: 3, Percentage: 50, ETA: time.Minute}
+	blob, _ := json.Marshal(map[string]interface{}{"syncing": true, "status": progress})
+
+	tests := []struct {
+		msg  string
+		want *vapory.SyncProgress
+	}{
+		{"false", nil},
+		{`{"syncing":false}`, nil},
+		{string(blob), &progress},
+	}
+	for i, tt := range tests {
+		have, err := decodeSyncNotification(json.RawMessage(tt.msg))
+		if err != nil {
+			t.Errorf("test %d: failed to decode: %v", i, err)
+			continue
+		}
+		if !reflect.DeepEqual(have, tt.want) {
+			t.Errorf("test %d: progress mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
@@ -29,6 +29,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/filter"
 	"github.com/syndtr/goleveldb/leveldb/iterator"
 	"github.com/syndtr/goleveldb/leveldb/opt"
+	"github.com/syndtr/goleveldb/leveldb/util"
 
 	gometrics "github.com/rcrowley/go-metrics"
 )
@@ -149,6 +150,19 @@ func (db *LDBDatabase) NewIterator() iterator.Iterator {
 	return db.db.NewIterator(nil, nil)
 }
 
+// NewIteratorWithStart creates a binary-alphabetical iterator over a subset of
+// database content starting at a particular initial key (or after, if it does
+// not exist).
+func (db *LDBDatabase) NewIteratorWithStart(start []byte) Iterator {
+	return db.db.NewIterator(&util.Range{Start: start}, nil)
+}
+
+// NewIteratorWithPrefix creates a binary-alphabetical iterator over a subset
+// of database content with a particular key prefix.
+func (db *LDBDatabase) NewIteratorWithPrefix(prefix []byte) Iterator {
+	return db.db.NewIterator(util.BytesPrefix(prefix), nil)
+}
+
 func (db *LDBDatabase) Close() {
 	// Stop the metrics collection to avoid internal database races
 	db.quitLock.Lock()
@@ -291,6 +305,12 @@ func (b *ldbBatch) Put(key, value []byte) error {
 	return nil
 }
 
+func (b *ldbBatch) Delete(key []byte) error {
+	b.b.Delete(key)
+	b.size += len(key)
+	return nil
+}
+
 func (b *ldbBatch) Write() error {
 	return b.db.Write(b.b, nil)
 }
@@ -299,6 +319,41 @@ func (b *ldbBatch) ValueSize() int {
 	return b.size
 }
 
+func (b *ldbBatch) Reset() {
+	b.b.Reset()
+	b.size = 0
+}
+
+func (b *ldbBatch) Replay(w Writer) error {
+	r := &replayer{writer: w}
+	if err := b.b.Replay(r); err != nil {
+		return err
+	}
+	return r.failure
+}
+
+// replayer is a small wrapper to forward leveldb batch replays into a Writer.
+type replayer struct {
+	writer  Writer
+	failure error
+}
+
+func (r *replayer) Put(key, value []byte) {
+	// If the replay already failed, stop executing ops
+	if r.failure != nil {
+		return
+	}
+	r.failure = r.writer.Put(key, value)
+}
+
+func (r *replayer) Delete(key []byte) {
+	// If the replay already failed, stop executing ops
+	if r.failure != nil {
+		return
+	}
+	r.failure = r.writer.Delete(key)
+}
+
 type table struct {
 	db     Database
 	prefix string
@@ -333,6 +388,22 @@ func (dt *table) Close() {
 	// Do nothing; don't close the underlying DB.
 }
 
+// NewIteratorWithStart creates a binary-alphabetical iterator over a subset of
+// the table content starting at a particular initial key (or after, if it does
+// not exist). The returned keys have the table prefix stripped.
+func (dt *table) NewIteratorWithStart(start []byte) Iterator {
+	it := dt.db.NewIteratorWithStart(append([]byte(dt.prefix), start...))
+	return &tableIterator{it: it, prefix: dt.prefix}
+}
+
+// NewIteratorWithPrefix creates a binary-alphabetical iterator over a subset
+// of the table content with a particular key prefix. The returned keys have the
+// table prefix stripped.
+func (dt *table) NewIteratorWithPrefix(prefix []byte) Iterator {
+	it := dt.db.NewIteratorWithPrefix(append([]byte(dt.prefix), prefix...))
+	return &tableIterator{it: it, prefix: dt.prefix}
+}
+
 type tableBatch struct {
 	batch  Batch
 	prefix string
@@ -351,6 +422,10 @@ func (tb *tableBatch) Put(key, value []byte) error {
 	return tb.batch.Put(append([]byte(tb.prefix), key...), value)
 }
 
+func (tb *tableBatch) Delete(key []byte) error {
+	return tb.batch.Delete(append([]byte(tb.prefix), key...))
+}
+
 func (tb *tableBatch) Write() error {
 	return tb.batch.Write()
 }
@@ -358,3 +433,64 @@ func (tb *tableBatch) Write() error {
 func (tb *tableBatch) ValueSize() int {
 	return tb.batch.ValueSize()
 }
+
+func (tb *tableBatch) Reset() {
+	tb.batch.Reset()
+}
+
+func (tb *tableBatch) Replay(w Writer) error {
+	return tb.batch.Replay(&tableReplayer{writer: w, prefix: tb.prefix})
+}
+
+// tableReplayer is a wrapper around a batch replayer which strips the table
+// prefix from the replayed keys.
+type tableReplayer struct {
+	writer Writer
+	prefix string
+}
+
+func (r *tableReplayer) Put(key, value []byte) error {
+	return r.writer.Put(key[len(r.prefix):], value)
+}
+
+func (r *tableReplayer) Delete(key []byte) error {
+	return r.writer.Delete(key[len(r.prefix):])
+}
+
+// tableIterator is a wrapper around a database iterator that stops once leaving
+// the table and strips the table prefix from the returned keys.
+type tableIterator struct {
+	it     Iterator
+	prefix string
+}
+
+func (it *tableIterator) Next() bool {
+	if !it.it.Next() {
+		return false
+	}
+	if !strings.HasPrefix(string(it.it.Key()), it.prefix) {
+		it.it.Release()
+		return false
+	}
+	return true
+}
+
+func (it *tableIterator) Error() error {
+	return it.it.Error()
+}
+
+func (it *tableIterator) Key() []byte {
+	key := it.it.Key()
+	if key == nil || !strings.HasPrefix(string(key), it.prefix) {
+		return nil
+	}
+	return key[len(it.prefix):]
+}
+
+func (it *tableIterator) Value() []byte {
+	return it.it.Value()
+}
+
+func (it *tableIterator) Release() {
+	it.it.Release()
+}
@@ -190,3 +190,177 @@ func testParallelPutGet(db vapdb.Database, t *testing.T) {
 	}
 	pending.Wait()
 }
+
+func TestLDB_Iterator(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testIterator(db, t)
+}
+
+func TestMemoryDB_Iterator(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	testIterator(db, t)
+}
+
+func TestTable_Iterator(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	db.Put([]byte("a"), []byte("outside"))
+	db.Put([]byte("z"), []byte("outside"))
+	testIterator(vapdb.NewTable(db, "t-"), t)
+}
+
+func testIterator(db vapdb.Database, t *testing.T) {
+	keys := []string{"1", "2", "20", "21", "3", "4"}
+	for _, k := range keys {
+		if err := db.Put([]byte(k), []byte("v"+k)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	tests := []struct {
+		it   vapdb.Iterator
+		want []string
+	}{
+		{it: db.NewIteratorWithPrefix(nil), want: keys},
+		{it: db.NewIteratorWithPrefix([]byte("2")), want: []string{"2", "20", "21"}},
+		{it: db.NewIteratorWithPrefix([]byte("5")), want: nil},
+		{it: db.NewIteratorWithStart(nil), want: keys},
+		{it: db.NewIteratorWithStart([]byte("20")), want: []string{"20", "21", "3", "4"}},
+		{it: db.NewIteratorWithStart([]byte("22")), want: []string{"3", "4"}},
+		{it: db.NewIteratorWithStart([]byte("5")), want: nil},
+	}
+	for i, tt := range tests {
+		var have []string
+		for tt.it.Next() {
+			if !bytes.Equal(tt.it.Value(), []byte("v"+string(tt.it.Key()))) {
+				t.Errorf("test %d: value mismatch for key %q: have %q", i, tt.it.Key(), tt.it.Value())
+			}
+			have = append(have, string(tt.it.Key()))
+		}
+		if err := tt.it.Error(); err != nil {
+			t.Errorf("test %d: iteration failed: %v", i, err)
+		}
+		tt.it.Release()
+
+		if fmt.Sprint(have) != fmt.Sprint(tt.want) {
+			t.Errorf("test %d: key mismatch: have %v, want %v", i, have, tt.want)
+		}
+	}
+}
+
+func TestLDB_BatchReplay(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testBatchReplay(db, t)
+}
+
+func TestMemoryDB_BatchReplay(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	testBatchReplay(db, t)
+}
+
+func TestTable_BatchReplay(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	testBatchReplay(vapdb.NewTable(db, "t-"), t)
+}
+
+func testBatchReplay(db vapdb.Database, t *testing.T) {
+	batch := db.NewBatch()
+	for _, v := range test_values {
+		if err := batch.Put([]byte(v), []byte("v"+v)); err != nil {
+			t.Fatalf("batch put failed: %v", err)
+		}
+	}
+	// Replay the batch into a separate store and check the contents
+	target, _ := vapdb.NewMemDatabase()
+	if err := batch.Replay(target); err != nil {
+		t.Fatalf("batch replay failed: %v", err)
+	}
+	if target.Len() != len(test_values) {
+		t.Fatalf("replayed item count mismatch: have %d, want %d", target.Len(), len(test_values))
+	}
+	for _, v := range test_values {
+		data, err := target.Get([]byte(v))
+		if err != nil {
+			t.Fatalf("replayed value %q missing: %v", v, err)
+		}
+		if !bytes.Equal(data, []byte("v"+v)) {
+			t.Fatalf("replayed value mismatch: have %q, want %q", data, "v"+v)
+		}
+	}
+	// Reset the batch and ensure nothing is written or replayed anymore
+	batch.Reset()
+	if size := batch.ValueSize(); size != 0 {
+		t.Fatalf("reset batch size mismatch: have %d, want 0", size)
+	}
+	if err := batch.Write(); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	for _, v := range test_values {
+		if has, _ := db.Has([]byte(v)); has {
+			t.Fatalf("reset batch wrote value %q", v)
+		}
+	}
+	target, _ = vapdb.NewMemDatabase()
+	if err := batch.Replay(target); err != nil {
+		t.Fatalf("batch replay failed: %v", err)
+	}
+	if target.Len() != 0 {
+		t.Fatalf("reset batch replayed %d items", target.Len())
+	}
+}
+
+func TestLDB_BatchDelete(t *testing.T) {
+	db, remove := newTestLDB()
+	defer remove()
+	testBatchDelete(db, t)
+}
+
+func TestMemoryDB_BatchDelete(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	testBatchDelete(db, t)
+}
+
+func TestTable_BatchDelete(t *testing.T) {
+	db, _ := vapdb.NewMemDatabase()
+	testBatchDelete(vapdb.NewTable(db, "t-"), t)
+}
+
+func testBatchDelete(db vapdb.Database, t *testing.T) {
+	for _, v := range test_values {
+		if err := db.Put([]byte(v), []byte("v"+v)); err != nil {
+			t.Fatalf("put failed: %v", err)
+		}
+	}
+	// Queue up deletions and ensure nothing is removed until the batch is written
+	batch := db.NewBatch()
+	for _, v := range test_values {
+		if err := batch.Delete([]byte(v)); err != nil {
+			t.Fatalf("batch delete failed: %v", err)
+		}
+	}
+	for _, v := range test_values {
+		if has, _ := db.Has([]byte(v)); !has {
+			t.Fatalf("unwritten batch deleted value %q", v)
+		}
+	}
+	// Replay the deletions into a populated store and ensure they are applied
+	target, _ := vapdb.NewMemDatabase()
+	for _, v := range test_values {
+		target.Put([]byte(v), []byte("v"+v))
+	}
+	if err := batch.Replay(target); err != nil {
+		t.Fatalf("batch replay failed: %v", err)
+	}
+	if target.Len() != 0 {
+		t.Fatalf("replayed deletions left %d items", target.Len())
+	}
+	// Write the batch and ensure all the values are gone
+	if err := batch.Write(); err != nil {
+		t.Fatalf("batch write failed: %v", err)
+	}
+	for _, v := range test_values {
+		if has, _ := db.Has([]byte(v)); has {
+			t.Fatalf("batch failed to delete value %q", v)
+		}
+	}
+}